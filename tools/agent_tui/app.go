@@ -1,18 +1,17 @@
 package agent_tui
 
 import (
-	"errors"
-	"fmt"
 	"log"
-	"os"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/openshift/agent-installer-utils/pkg/version"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	tuiconfig "github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/logging"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/ui"
 	"github.com/rivo/tview"
-	"github.com/sirupsen/logrus"
 )
 
 // AppContext contains all the configuration and state needed to run the agent TUI application
@@ -27,6 +26,10 @@ type AppContext struct {
 	Config checks.Config
 	// CheckFuncs allows injecting custom check implementations for testing
 	CheckFuncs []checks.CheckFunctions
+	// Settings are the effective options, displayed in the settings page
+	Settings []tuiconfig.Setting
+	// Warnings are the non fatal issues found while loading the options
+	Warnings []string
 }
 
 func App(ctx AppContext) {
@@ -36,24 +39,43 @@ func App(ctx AppContext) {
 	config := ctx.Config
 	checkFuncs := ctx.CheckFuncs
 
-	if err := prepareConfig(&config); err != nil {
+	if err := checks.PrepareConfig(&config); err != nil {
 		log.Fatal(err)
 	}
 
-	logger := logrus.New()
-	// initialize log
-	f, err := os.OpenFile(config.LogPath, os.O_RDWR|os.O_CREATE, 0644)
-	if errors.Is(err, os.ErrNotExist) {
-		// handle the case where the file doesn't exist
-		fmt.Printf("Error creating log file %s\n", config.LogPath)
+	logger, logFile, err := logging.NewLogger(logging.Options{
+		Path:       config.LogPath,
+		Level:      config.LogLevel,
+		Format:     config.LogFormat,
+		MaxSizeMB:  config.LogMaxSizeMB,
+		MaxBackups: config.LogMaxBackups,
+	})
+	if err != nil {
+		log.Fatalf("Error initializing the log: %v", err)
 	}
-	logger.Out = f
+	defer logFile.Close()
 
 	logger.Infof("Release Image URL: %s", config.ReleaseImageURL)
 	logger.Infof("Agent TUI git version: %s", version.Commit)
 	logger.Infof("Agent TUI build version: %s", version.Raw)
 	logger.Infof("Rendezvous IP: %s", rendezvousIP)
 	logger.Infof("Interactive UI Mode: %v", interactiveUIMode)
+	for _, setting := range ctx.Settings {
+		if setting.Source != tuiconfig.SourceDefault {
+			logger.Infof("Setting %s: %s (from %s)", setting.Name, setting.Value, setting.Source)
+		}
+	}
+	for _, warning := range ctx.Warnings {
+		logger.Warn(warning)
+	}
+
+	// In the interactive flow, a rendezvous IP already saved does not need
+	// to be confirmed by the user
+	if interactiveUIMode && rendezvousIP != "" && config.AutoContinue &&
+		rendezvousIP == rendezvous.Read(config.RendezvousHostEnvPath) {
+		logger.Infof("Rendezvous IP is set and auto-continue is enabled, exiting")
+		return
+	}
 
 	var appUI *ui.UI
 	if app == nil {
@@ -75,8 +97,11 @@ func App(ctx AppContext) {
 		app = tview.NewApplication()
 	}
 	appUI = ui.NewUI(app, config, logger, rendezvousIP)
+	appUI.SetSettings(ctx.Settings, ctx.Warnings)
 	controller := ui.NewController(appUI)
 	engine := checks.NewEngine(controller.GetChan(), config, logger, checkFuncs...)
+	appUI.SetRunChecks(engine.RunConnectivity)
+	appUI.SetTriggerChecks(engine.Trigger)
 
 	controller.Init(engine.Size(), rendezvousIP, interactiveUIMode)
 	engine.Init()
@@ -84,21 +109,3 @@ func App(ctx AppContext) {
 		log.Fatal(err)
 	}
 }
-
-func prepareConfig(config *checks.Config) error {
-	// Set hostname
-	hostname, err := checks.ParseHostnameFromURL(config.ReleaseImageURL)
-	if err != nil {
-		return err
-	}
-	config.ReleaseImageHostname = hostname
-
-	// Set scheme
-	schemeHostnamePort, err := checks.ParseSchemeHostnamePortFromURL(config.ReleaseImageURL, "https://")
-	if err != nil {
-		return fmt.Errorf("error creating <scheme>://<hostname>:<port> from releaseImageURL: %s", config.ReleaseImageURL)
-	}
-	config.ReleaseImageSchemeHostnamePort = schemeHostnamePort
-
-	return nil
-}
@@ -0,0 +1,179 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	stdnet "net"
+	"strings"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+// How long the ARP replies are waited for
+const arpProbeTimeout = time.Second
+
+// arpCache keeps the results of the ARP probes of the periodic check,
+// sent when it starts and when the IPv4 addresses change
+var arpCache probeCache
+
+// arpResult is the value of arpCache
+type arpResult struct {
+	duplicates  []DuplicateIP
+	probeErrors []string
+}
+
+// duplicateIPProbes are the operations used to detect the duplicate
+// addresses, replaced by the tests
+type duplicateIPProbes struct {
+	arp func(iface string, ip stdnet.IP, timeout time.Duration) ([]string, error)
+	dad func() ([]net.AddressDAD, error)
+}
+
+var dupProbes = duplicateIPProbes{
+	arp: net.ARPProbe,
+	dad: net.IPv6DAD,
+}
+
+// DuplicateIP is an address of the host used by other hosts too
+type DuplicateIP struct {
+	Iface string
+	IP    string
+	// MACs are the addresses of the other hosts answering the ARP
+	// probes. The IPv6 duplicate address detection doesn't tell them.
+	MACs []string
+}
+
+func (d DuplicateIP) String() string {
+	if len(d.MACs) == 0 {
+		return fmt.Sprintf("%s on %s failed the IPv6 duplicate address detection", d.IP, d.Iface)
+	}
+	return fmt.Sprintf("%s on %s is also used by %s", d.IP, d.Iface, strings.Join(d.MACs, ", "))
+}
+
+// checkDuplicateIP fails when any address of the host is used by
+// another host. The ARP probes are not sent at every run, see
+// arpCache, while the IPv6 duplicate address detection state is read
+// every time.
+func checkDuplicateIP(checkType string, c Config, ns net.NetState) ([]byte, error) {
+	arp := arpCache.get(arpProbesKey(ns), func() interface{} {
+		duplicates, probeErrors := arpDuplicates(ns)
+		return arpResult{duplicates, probeErrors}
+	}).(arpResult)
+	duplicates := append([]DuplicateIP{}, arp.duplicates...)
+	probeErrors := append([]string{}, arp.probeErrors...)
+	duplicates, probeErrors = appendDADDuplicates(duplicates, probeErrors)
+	lines := []string{}
+	for _, d := range duplicates {
+		lines = append(lines, d.String())
+	}
+	lines = append(lines, probeErrors...)
+	output := []byte(strings.Join(lines, "\n"))
+	if len(duplicates) > 0 {
+		return output, errors.New("duplicate IP addresses")
+	}
+	return output, nil
+}
+
+// FindDuplicateIPs sends ARP probes for the IPv4 addresses of the
+// interfaces up, and looks for the IPv6 addresses whose duplicate
+// address detection failed. The probes that couldn't be sent are
+// reported apart, so that they don't hide the duplicates found.
+func FindDuplicateIPs(ns net.NetState) ([]DuplicateIP, []string) {
+	duplicates, probeErrors := arpDuplicates(ns)
+	return appendDADDuplicates(duplicates, probeErrors)
+}
+
+// arpProbesKey identifies the IPv4 addresses probed by arpDuplicates
+func arpProbesKey(ns net.NetState) string {
+	key := []string{}
+	for _, iface := range ns.Ifaces {
+		if iface.State != "up" || iface.Type == "loopback" {
+			continue
+		}
+		for _, address := range iface.IPv4.Addresses {
+			key = append(key, iface.Name+"/"+address.IP.String())
+		}
+	}
+	return strings.Join(key, " ")
+}
+
+func arpDuplicates(ns net.NetState) ([]DuplicateIP, []string) {
+	duplicates := []DuplicateIP{}
+	probeErrors := []string{}
+	for _, iface := range ns.Ifaces {
+		if iface.State != "up" || iface.Type == "loopback" {
+			continue
+		}
+		for _, address := range iface.IPv4.Addresses {
+			macs, err := dupProbes.arp(iface.Name, address.IP, arpProbeTimeout)
+			if err != nil {
+				probeErrors = append(probeErrors, fmt.Sprintf("ARP probe for %s on %s failed: %v", address.IP, iface.Name, err))
+				continue
+			}
+			if len(macs) > 0 {
+				duplicates = append(duplicates, DuplicateIP{Iface: iface.Name, IP: address.IP.String(), MACs: macs})
+			}
+		}
+	}
+	return duplicates, probeErrors
+}
+
+func appendDADDuplicates(duplicates []DuplicateIP, probeErrors []string) ([]DuplicateIP, []string) {
+	states, err := dupProbes.dad()
+	if err != nil {
+		return duplicates, append(probeErrors, fmt.Sprintf("IPv6 duplicate address detection state not available: %v", err))
+	}
+	for _, state := range states {
+		if state.Failed {
+			duplicates = append(duplicates, DuplicateIP{Iface: state.Iface, IP: state.IP.String()})
+		}
+	}
+	return duplicates, probeErrors
+}
+
+// ProbeRendezvousIP looks for other hosts using the rendezvous IP
+// before it's saved. The address of this host, when local, must not be
+// used by any other host, while the address of another host must be
+// used by that host only. Only the on-link IPv4 addresses and the local
+// IPv6 ones can be probed, the others are reported as not duplicated.
+func ProbeRendezvousIP(ns net.NetState, ipAddress string, local bool) (*DuplicateIP, error) {
+	ip := stdnet.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("%s is not a valid IP address", ipAddress)
+	}
+
+	if ip.To4() == nil {
+		if !local {
+			return nil, nil
+		}
+		states, err := dupProbes.dad()
+		if err != nil {
+			return nil, err
+		}
+		for _, state := range states {
+			if state.Failed && state.IP.Equal(ip) {
+				return &DuplicateIP{Iface: state.Iface, IP: ipAddress}, nil
+			}
+		}
+		return nil, nil
+	}
+
+	iface := ns.OnLinkIface(ip)
+	if iface == "" {
+		return nil, nil
+	}
+	macs, err := dupProbes.arp(iface, ip, arpProbeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	// the rendezvous node itself answers for a remote address
+	allowed := 1
+	if local {
+		allowed = 0
+	}
+	if len(macs) > allowed {
+		return &DuplicateIP{Iface: iface, IP: ipAddress, MACs: macs}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,132 @@
+package checks
+
+import (
+	"errors"
+	stdnet "net"
+	"testing"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDuplicateIPs(t *testing.T) {
+	defer func(p duplicateIPProbes) { dupProbes = p }(dupProbes)
+
+	address := func(cidr string) stdnet.IPNet {
+		ip, network, err := stdnet.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		return stdnet.IPNet{IP: ip, Mask: network.Mask}
+	}
+	ns := net.NetState{
+		Ifaces: []net.Iface{
+			{Name: "lo", Type: "loopback", State: "up", IPv4: net.IPConfig{Addresses: []stdnet.IPNet{address("127.0.0.1/8")}}},
+			{Name: "eth0", Type: "ethernet", State: "up", IPv4: net.IPConfig{Addresses: []stdnet.IPNet{address("192.168.111.20/24")}}},
+			{Name: "eth1", Type: "ethernet", State: "up", IPv4: net.IPConfig{Addresses: []stdnet.IPNet{address("10.0.0.20/24")}}},
+			{Name: "eth2", Type: "ethernet", State: "down", IPv4: net.IPConfig{Addresses: []stdnet.IPNet{address("172.16.0.20/24")}}},
+		},
+	}
+
+	probed := []string{}
+	answers := map[string][]string{
+		"192.168.111.20": {"52:54:00:12:34:56"},
+		"192.168.111.80": {"52:54:00:00:00:80"},
+		"192.168.111.99": {"52:54:00:00:00:80", "52:54:00:00:00:99"},
+	}
+	dupProbes = duplicateIPProbes{
+		arp: func(iface string, ip stdnet.IP, timeout time.Duration) ([]string, error) {
+			probed = append(probed, iface+"/"+ip.String())
+			if iface == "eth1" {
+				return nil, errors.New("operation not permitted")
+			}
+			return answers[ip.String()], nil
+		},
+		dad: func() ([]net.AddressDAD, error) {
+			return []net.AddressDAD{
+				{Iface: "eth0", IP: stdnet.ParseIP("fd00::20"), Failed: true},
+				{Iface: "eth0", IP: stdnet.ParseIP("fd00::21"), Tentative: true},
+			}, nil
+		},
+	}
+
+	duplicates, probeErrors := FindDuplicateIPs(ns)
+	assert.Equal(t, []string{"eth0/192.168.111.20", "eth1/10.0.0.20"}, probed)
+	assert.Equal(t, []DuplicateIP{
+		{Iface: "eth0", IP: "192.168.111.20", MACs: []string{"52:54:00:12:34:56"}},
+		{Iface: "eth0", IP: "fd00::20"},
+	}, duplicates)
+	assert.Equal(t, "192.168.111.20 on eth0 is also used by 52:54:00:12:34:56", duplicates[0].String())
+	assert.Equal(t, "fd00::20 on eth0 failed the IPv6 duplicate address detection", duplicates[1].String())
+	assert.Equal(t, []string{"ARP probe for 10.0.0.20 on eth1 failed: operation not permitted"}, probeErrors)
+
+	// the rendezvous node answers for its own address
+	duplicate, err := ProbeRendezvousIP(ns, "192.168.111.80", false)
+	assert.NoError(t, err)
+	assert.Nil(t, duplicate)
+	duplicate, err = ProbeRendezvousIP(ns, "192.168.111.99", false)
+	assert.NoError(t, err)
+	assert.Equal(t, &DuplicateIP{Iface: "eth0", IP: "192.168.111.99", MACs: []string{"52:54:00:00:00:80", "52:54:00:00:00:99"}}, duplicate)
+	duplicate, err = ProbeRendezvousIP(ns, "192.168.111.20", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"52:54:00:12:34:56"}, duplicate.MACs)
+	duplicate, err = ProbeRendezvousIP(ns, "fd00::20", true)
+	assert.NoError(t, err)
+	assert.Equal(t, &DuplicateIP{Iface: "eth0", IP: "fd00::20"}, duplicate)
+
+	// the addresses not on-link can't be probed
+	duplicate, err = ProbeRendezvousIP(ns, "192.0.2.1", false)
+	assert.NoError(t, err)
+	assert.Nil(t, duplicate)
+	_, err = ProbeRendezvousIP(ns, "not-an-ip", false)
+	assert.Error(t, err)
+}
+
+func TestCheckDuplicateIPProbesOnAddressChanges(t *testing.T) {
+	defer func(p duplicateIPProbes) { dupProbes = p }(dupProbes)
+	resetProbeCaches()
+	defer resetProbeCaches()
+
+	netState := func(cidr string) net.NetState {
+		ip, network, err := stdnet.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		return net.NetState{Ifaces: []net.Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", IPv4: net.IPConfig{Enabled: true, Addresses: []stdnet.IPNet{{IP: ip, Mask: network.Mask}}}},
+		}}
+	}
+
+	probed := []string{}
+	dadFailed := false
+	dupProbes = duplicateIPProbes{
+		arp: func(iface string, ip stdnet.IP, timeout time.Duration) ([]string, error) {
+			probed = append(probed, ip.String())
+			return []string{"52:54:00:12:34:56"}, nil
+		},
+		dad: func() ([]net.AddressDAD, error) {
+			return []net.AddressDAD{{Iface: "eth0", IP: stdnet.ParseIP("fd00::20"), Failed: dadFailed}}, nil
+		},
+	}
+
+	ns := netState("192.168.111.20/24")
+	output, err := checkDuplicateIP(CheckTypeDuplicateIP, Config{}, ns)
+	assert.Error(t, err)
+	assert.Equal(t, "192.168.111.20 on eth0 is also used by 52:54:00:12:34:56", string(output))
+	assert.Equal(t, []string{"192.168.111.20"}, probed)
+
+	// the probes are not sent again, while the DAD state is still read
+	dadFailed = true
+	output, _ = checkDuplicateIP(CheckTypeDuplicateIP, Config{}, ns)
+	assert.Equal(t, "192.168.111.20 on eth0 is also used by 52:54:00:12:34:56\n"+
+		"fd00::20 on eth0 failed the IPv6 duplicate address detection", string(output))
+	assert.Equal(t, []string{"192.168.111.20"}, probed)
+
+	ns = netState("192.168.111.21/24")
+	checkDuplicateIP(CheckTypeDuplicateIP, Config{}, ns)
+	assert.Equal(t, []string{"192.168.111.20", "192.168.111.21"}, probed)
+
+	// Trigger sends them again
+	engine := NewEngine(nil, Config{RegistryEnvPath: "/nonexistent"}, logrus.New(), CheckFunctions{})
+	engine.Trigger()
+	checkDuplicateIP(CheckTypeDuplicateIP, Config{}, ns)
+	assert.Equal(t, []string{"192.168.111.20", "192.168.111.21", "192.168.111.21"}, probed)
+}
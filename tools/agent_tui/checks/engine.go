@@ -2,10 +2,14 @@ package checks
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
 	"net/http"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -16,11 +20,65 @@ const (
 	CheckTypeReleaseImageHostDNS  = "ReleaseImageHostDNS"
 	CheckTypeReleaseImageHostPing = "ReleaseImageHostPing"
 	CheckTypeReleaseImageHttp     = "ReleaseImageHttp"
+	CheckTypeNetStateLint         = "NetStateLint"
+	CheckTypeGateway              = "Gateway"
+	CheckTypeDuplicateIP          = "DuplicateIP"
+	CheckTypePathMTU              = "PathMTU"
+
+	// checkTypeEndpointPrefix is followed by the URL of the endpoint
+	checkTypeEndpointPrefix = "Endpoint:"
+)
+
+// The defaults of the Config fields not set
+const (
+	DefaultLogSummaryInterval  = 5 * time.Minute
+	DefaultCheckFrequency      = 5 * time.Second
+	DefaultRegistryEnvPath     = "/etc/assisted/registry.env"
+	DefaultPromptTimeout       = 20 * time.Second
+	DefaultConnectivityTimeout = 1 * time.Second
+
+	// ConnectivityChecksTimeout bounds the connectivity checks run
+	// before and after a network change
+	ConnectivityChecksTimeout = 30 * time.Second
 )
 
+// connectivityCheckTypes are the quick checks run by RunConnectivity,
+// the additional endpoints are included too
+var connectivityCheckTypes = map[string]bool{
+	CheckTypeReleaseImageHostDNS:  true,
+	CheckTypeReleaseImageHostPing: true,
+	CheckTypeReleaseImageHttp:     true,
+	CheckTypeGateway:              true,
+}
+
 type Config struct {
 	ReleaseImageURL string
 	LogPath         string
+	LogLevel        string
+	LogFormat       string
+	LogMaxSizeMB    int
+	LogMaxBackups   int
+	// LogSummaryInterval is how often a summary of each check
+	// is logged, in addition to its status changes
+	LogSummaryInterval time.Duration
+	// JournalUnits are the systemd units whose journal is
+	// displayed in the log viewer
+	JournalUnits []string
+	// RegistryEnvPath is the file whose presence indicates that a local
+	// registry is used, so the release image checks are skipped
+	RegistryEnvPath string
+	// CheckFrequency is how often each check is run
+	CheckFrequency time.Duration
+	// RendezvousHostEnvPath is the file where the rendezvous IP is saved
+	RendezvousHostEnvPath string
+	// PromptTimeout is how long the UI prompts wait before continuing
+	PromptTimeout time.Duration
+	// ConnectivityTimeout is the timeout of the rendezvous IP connectivity check
+	ConnectivityTimeout time.Duration
+	// AutoContinue skips the UI prompts when no user input is required
+	AutoContinue bool
+	// CheckEndpoints are additional URLs checked with an http GET
+	CheckEndpoints []string
 
 	ReleaseImageHostname           string
 	ReleaseImageSchemeHostnamePort string
@@ -32,40 +90,98 @@ type Config struct {
 // Different checks could have the same type
 
 type CheckResult struct {
-	Type    string
-	Success bool
-	Details string // In case of failure
+	Type      string
+	Success   bool
+	Details   string // In case of failure
+	Timestamp time.Time
+	Duration  time.Duration
+	History   HistorySummary // Most recent results, including this one
 }
 
 type Check struct {
 	Type string
 	Freq time.Duration //Note: a ticker could be useful
 	Run  func(c chan CheckResult, Freq time.Duration)
+	Once func() CheckResult // runs the check a single time
+	Wake chan struct{}      // interrupts the wait for the next run
+	// Probe runs the check a single time without recording the result
+	// in the history, only set for the connectivity checks
+	Probe func() CheckResult
 }
 
 type Engine struct {
-	checks  []*Check
-	channel chan CheckResult
-	logger  *logrus.Logger
+	checks              []*Check
+	histories           map[string]*History
+	channel             chan CheckResult
+	logger              *logrus.Logger
+	connectivityTimeout time.Duration
+	netState            *netStateSnapshot
 }
 
 type CheckFunction func(checkType string, config Config) ([]byte, error)
 
-func createCheckResult(f CheckFunction, checkType string, config Config, l *logrus.Logger) CheckResult {
+func runCheckFunction(f CheckFunction, checkType string, config Config) CheckResult {
+	start := time.Now()
 	output, err := f(checkType, config)
-	result := CheckResult{
-		Type:    checkType,
-		Success: err == nil,
-		Details: string(output),
+	return CheckResult{
+		Type:      checkType,
+		Success:   err == nil,
+		Details:   string(output),
+		Timestamp: start,
+		Duration:  time.Since(start),
+	}
+}
+
+func createCheckResult(f CheckFunction, checkType string, config Config, l *logrus.Logger, h *History) CheckResult {
+	result := runCheckFunction(f, checkType, config)
+	changed := h.Add(HistoryEntry{
+		Timestamp: result.Timestamp,
+		Success:   result.Success,
+		Duration:  result.Duration,
+	})
+	result.History = h.Summary()
+
+	// Only the status changes are logged at the info level, to avoid
+	// filling the log with the same messages every few seconds
+	logf := l.Debugf
+	switch {
+	case changed && result.Success:
+		logf = l.Infof
+	case changed && !result.Success:
+		logf = l.Warnf
 	}
 	if result.Success {
-		l.Infof("%s check successful: %s", checkType, result.Details)
+		logf("%s check successful: %s", checkType, result.Details)
 	} else {
-		l.Warnf("%s check failed with error: %s", checkType, result.Details)
+		logf("%s check failed with error: %s", checkType, result.Details)
 	}
 	return result
 }
 
+// logSummary logs the results of a check since the previous summary
+func logSummary(l *logrus.Logger, checkType string, h HistorySummary, since time.Time) {
+	runs, passed := 0, 0
+	for _, e := range h.Entries {
+		if e.Timestamp.Before(since) {
+			continue
+		}
+		runs++
+		if e.Success {
+			passed++
+		}
+	}
+	if runs == 0 {
+		return
+	}
+
+	status := "failing"
+	if h.Entries[len(h.Entries)-1].Success {
+		status = "successful"
+	}
+	l.Infof("%s check summary: %d/%d runs passed since %s, %s since %s",
+		checkType, passed, runs, since.Format(time.RFC3339), status, h.LastChange.Format(time.RFC3339))
+}
+
 type CheckFunctions map[string]CheckFunction
 
 var defaultCheckFunctions = CheckFunctions{
@@ -79,50 +195,130 @@ var defaultCheckFunctions = CheckFunctions{
 		return exec.Command("ping", "-c", "4", c.ReleaseImageHostname).CombinedOutput()
 	},
 	CheckTypeReleaseImageHttp: func(checkType string, c Config) ([]byte, error) {
-		resp, err := http.Get(c.ReleaseImageSchemeHostnamePort)
-		if err != nil {
-			return []byte(err.Error()), err
-		} else {
-			// server replied with http response
-			// as long as there is a response, the check
-			// is a success.
-			return []byte(resp.Status), err
-		}
+		return httpGet(c.ReleaseImageSchemeHostnamePort)
 	},
 }
 
+func httpGet(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return []byte(err.Error()), err
+	} else {
+		// server replied with http response
+		// as long as there is a response, the check
+		// is a success.
+		resp.Body.Close()
+		return []byte(resp.Status), err
+	}
+}
+
+// EndpointCheckType returns the type of the check for an additional endpoint
+func EndpointCheckType(url string) string {
+	return checkTypeEndpointPrefix + url
+}
+
+// EndpointFromCheckType returns the URL checked by an additional endpoint
+// check, or false if checkType is not an endpoint check
+func EndpointFromCheckType(checkType string) (string, bool) {
+	return strings.CutPrefix(checkType, checkTypeEndpointPrefix)
+}
+
 func NewEngine(c chan CheckResult, config Config, logger *logrus.Logger, checkFuncs ...CheckFunctions) *Engine {
 	checks := []*Check{}
+	histories := map[string]*History{}
+
+	summaryInterval := config.LogSummaryInterval
+	if summaryInterval == 0 {
+		summaryInterval = DefaultLogSummaryInterval
+	}
+
+	freq := config.CheckFrequency
+	if freq == 0 {
+		freq = DefaultCheckFrequency
+	}
+	registryEnvPath := config.RegistryEnvPath
+	if registryEnvPath == "" {
+		registryEnvPath = DefaultRegistryEnvPath
+	}
+	// the checks run at the same frequency, so the snapshot is shared
+	// by the runs of the same cycle
+	netState := &netStateSnapshot{maxAge: freq}
 
 	// When a local registry is present, there is no need to check the release
 	// image connectivity.
-	if _, err := os.Stat("/etc/assisted/registry.env"); errors.Is(err, fs.ErrNotExist) {
-		cf := defaultCheckFunctions
+	cf := CheckFunctions{}
+	if _, err := os.Stat(registryEnvPath); errors.Is(err, fs.ErrNotExist) {
+		source := defaultCheckFunctions
 		if len(checkFuncs) > 0 {
-			cf = checkFuncs[0]
+			source = checkFuncs[0]
+		}
+		for ct, f := range source {
+			cf[ct] = f
 		}
+	} else {
+		logger.Infof("A local registry is configured by %s, skipping the release image checks", registryEnvPath)
+	}
+	// the network state and endpoint checks don't depend on the registry
+	if len(checkFuncs) == 0 {
+		for ct, f := range netStateCheckFunctions {
+			cf[ct] = netState.checkFunction(f)
+		}
+	}
+	for _, endpoint := range config.CheckEndpoints {
+		url := endpoint
+		cf[EndpointCheckType(url)] = func(checkType string, c Config) ([]byte, error) {
+			return httpGet(url)
+		}
+	}
 
-		// create checks
-		for cType, cFunc := range cf {
-			ct := cType
-			cf := cFunc
-			checks = append(checks, &Check{
-				Type: ct,
-				Freq: 5 * time.Second,
-				Run: func(c chan CheckResult, freq time.Duration) {
-					for {
-						c <- createCheckResult(cf, ct, config, logger)
-						time.Sleep(freq)
-					}
-				},
-			})
+	// create checks
+	for cType, cFunc := range cf {
+		ct := cType
+		cf := cFunc
+		h := NewHistory(defaultHistorySize)
+		histories[ct] = h
+		wake := make(chan struct{}, 1)
+		once := func() CheckResult {
+			return createCheckResult(cf, ct, config, logger, h)
 		}
+		var probe func() CheckResult
+		if _, endpoint := EndpointFromCheckType(ct); connectivityCheckTypes[ct] || endpoint {
+			probe = func() CheckResult {
+				result := runCheckFunction(cf, ct, config)
+				logger.Debugf("%s connectivity probe: success %v: %s", ct, result.Success, result.Details)
+				return result
+			}
+		}
+		checks = append(checks, &Check{
+			Type: ct,
+			Freq: freq,
+			Once: once,
+			Run: func(c chan CheckResult, freq time.Duration) {
+				lastSummary := time.Now()
+				for {
+					c <- once()
+					if summaryInterval > 0 && time.Since(lastSummary) >= summaryInterval {
+						logSummary(logger, ct, h.Summary(), lastSummary)
+						lastSummary = time.Now()
+					}
+					select {
+					case <-time.After(freq):
+					case <-wake:
+					}
+				}
+			},
+			Wake:  wake,
+			Probe: probe,
+		})
 	}
 
 	return &Engine{
-		checks:  checks,
-		channel: c,
-		logger:  logger,
+		checks:              checks,
+		histories:           histories,
+		channel:             c,
+		logger:              logger,
+		connectivityTimeout: ConnectivityChecksTimeout,
+		netState:            netState,
 	}
 }
 
@@ -132,6 +328,124 @@ func (e *Engine) Init() {
 	}
 }
 
+// Trigger makes the periodic checks run immediately, instead of
+// waiting for their next run, for example after a network change. The
+// checks sending probes on the network send them again too.
+func (e *Engine) Trigger() {
+	e.netState.reset()
+	resetProbeCaches()
+	for _, chk := range e.checks {
+		select {
+		case chk.Wake <- struct{}{}:
+		default:
+			// a run is already pending
+		}
+	}
+}
+
+// RunOnce runs all the checks a single time, in parallel, and returns
+// their results sorted by type
+func (e *Engine) RunOnce() []CheckResult {
+	e.netState.reset()
+	results := make([]CheckResult, len(e.checks))
+	var wg sync.WaitGroup
+	for i, chk := range e.checks {
+		wg.Add(1)
+		go func(i int, chk *Check) {
+			defer wg.Done()
+			results[i] = chk.Once()
+		}(i, chk)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Type < results[j].Type
+	})
+	return results
+}
+
+// RunConnectivity runs the connectivity checks a single time, in
+// parallel, to compare the connectivity before and after a network
+// change. Unlike RunOnce, the slow checks such as the release image pull
+// are not run and the results are not recorded in the history. The
+// checks still running after ConnectivityChecksTimeout are reported as
+// failed. The results are sorted by type.
+func (e *Engine) RunConnectivity() []CheckResult {
+	// the network may have just changed
+	e.netState.reset()
+	probes := []*Check{}
+	for _, chk := range e.checks {
+		if chk.Probe != nil {
+			probes = append(probes, chk)
+		}
+	}
+
+	type probeResult struct {
+		i      int
+		result CheckResult
+	}
+	// buffered, so that the probes timed out don't block
+	done := make(chan probeResult, len(probes))
+	start := time.Now()
+	for i, chk := range probes {
+		go func(i int, chk *Check) {
+			done <- probeResult{i, chk.Probe()}
+		}(i, chk)
+	}
+
+	results := make([]CheckResult, len(probes))
+	for i, chk := range probes {
+		results[i] = CheckResult{
+			Type:      chk.Type,
+			Details:   fmt.Sprintf("timed out after %s", e.connectivityTimeout),
+			Timestamp: start,
+			Duration:  e.connectivityTimeout,
+		}
+	}
+	deadline := time.After(e.connectivityTimeout)
+wait:
+	for pending := len(probes); pending > 0; pending-- {
+		select {
+		case r := <-done:
+			results[r.i] = r.result
+		case <-deadline:
+			e.logger.Infof("%d connectivity checks timed out after %s", pending, e.connectivityTimeout)
+			break wait
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Type < results[j].Type
+	})
+	return results
+}
+
 func (e *Engine) Size() int {
 	return len(e.checks)
 }
+
+// History returns the most recent results for the specified check type
+func (e *Engine) History(checkType string) (HistorySummary, bool) {
+	h, found := e.histories[checkType]
+	if !found {
+		return HistorySummary{}, false
+	}
+	return h.Summary(), true
+}
+
+// Regressions returns the checks that were successful in the before
+// results and are failing in the after ones, for example to detect a
+// network change that made the connectivity worse
+func Regressions(before, after []CheckResult) []string {
+	succeeded := map[string]bool{}
+	for _, r := range before {
+		succeeded[r.Type] = r.Success
+	}
+	regressions := []string{}
+	for _, r := range after {
+		if succeeded[r.Type] && !r.Success {
+			regressions = append(regressions, r.Type)
+		}
+	}
+	return regressions
+}
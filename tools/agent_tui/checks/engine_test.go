@@ -0,0 +1,231 @@
+package checks
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCheckResultLogsOnlyStatusChanges(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	fail := false
+	f := func(checkType string, c Config) ([]byte, error) {
+		if fail {
+			return []byte("no route to host"), errors.New("failed")
+		}
+		return []byte("ok"), nil
+	}
+
+	h := NewHistory(10)
+	for _, failing := range []bool{false, false, true, true, true, false} {
+		fail = failing
+		createCheckResult(f, CheckTypeReleaseImageHostPing, Config{}, logger, h)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, []string{
+		`level=info msg="ReleaseImageHostPing check successful: ok"`,
+		`level=warning msg="ReleaseImageHostPing check failed with error: no route to host"`,
+		`level=info msg="ReleaseImageHostPing check successful: ok"`,
+	}, lines)
+}
+
+func TestLogSummary(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	h := NewHistory(10)
+	for i, success := range []bool{false, true, true, false, true} {
+		h.Add(HistoryEntry{Timestamp: start.Add(time.Duration(i) * time.Minute), Success: success})
+	}
+
+	logSummary(logger, CheckTypeReleaseImagePull, h.Summary(), start.Add(time.Minute))
+	assert.Equal(t,
+		`level=info msg="ReleaseImagePull check summary: 3/4 runs passed since 2023-01-01T10:01:00Z, successful since 2023-01-01T10:04:00Z"`,
+		strings.TrimSpace(buf.String()))
+}
+
+func TestRegressions(t *testing.T) {
+	before := []CheckResult{
+		{Type: CheckTypeReleaseImagePull, Success: true},
+		{Type: CheckTypeReleaseImageHostDNS, Success: false},
+		{Type: CheckTypeReleaseImageHostPing, Success: true},
+	}
+	after := []CheckResult{
+		{Type: CheckTypeReleaseImagePull, Success: false},
+		{Type: CheckTypeReleaseImageHostDNS, Success: false},
+		{Type: CheckTypeReleaseImageHostPing, Success: true},
+	}
+	assert.Equal(t, []string{CheckTypeReleaseImagePull}, Regressions(before, after))
+	assert.Empty(t, Regressions(after, before))
+	assert.Empty(t, Regressions(nil, after))
+}
+
+func TestTrigger(t *testing.T) {
+	c := make(chan CheckResult, 10)
+	config := Config{CheckFrequency: time.Hour, RegistryEnvPath: "/nonexistent"}
+	logger := logrus.New()
+	logger.SetOutput(&bytes.Buffer{})
+	engine := NewEngine(c, config, logger, CheckFunctions{
+		CheckTypeReleaseImagePull: func(checkType string, c Config) ([]byte, error) {
+			return []byte("ok"), nil
+		},
+	})
+	engine.Init()
+
+	next := func() bool {
+		select {
+		case <-c:
+			return true
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+	assert.True(t, next(), "the checks run when started")
+	assert.False(t, next(), "the next run is an hour later")
+
+	engine.Trigger()
+	assert.True(t, next(), "the checks run immediately when triggered")
+	assert.False(t, next())
+}
+
+func TestRunConnectivity(t *testing.T) {
+	config := Config{CheckFrequency: time.Hour, RegistryEnvPath: "/nonexistent"}
+	logger := logrus.New()
+	logger.SetOutput(&bytes.Buffer{})
+	pulled := false
+	slow := make(chan struct{})
+	defer close(slow)
+	engine := NewEngine(nil, config, logger, CheckFunctions{
+		CheckTypeReleaseImagePull: func(checkType string, c Config) ([]byte, error) {
+			pulled = true
+			return []byte("ok"), nil
+		},
+		CheckTypeReleaseImageHostDNS: func(checkType string, c Config) ([]byte, error) {
+			return []byte("ok"), nil
+		},
+		CheckTypeReleaseImageHttp: func(checkType string, c Config) ([]byte, error) {
+			<-slow
+			return []byte("200 OK"), nil
+		},
+	})
+	engine.connectivityTimeout = 100 * time.Millisecond
+
+	results := engine.RunConnectivity()
+	assert.False(t, pulled, "the release image is not pulled")
+	assert.Len(t, results, 2)
+	assert.Equal(t, CheckTypeReleaseImageHostDNS, results[0].Type)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, CheckTypeReleaseImageHttp, results[1].Type)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "timed out after 100ms", results[1].Details)
+
+	// the results are not recorded
+	history, found := engine.History(CheckTypeReleaseImageHostDNS)
+	assert.True(t, found)
+	assert.Empty(t, history.Entries)
+}
+
+// countingProvider counts the retrievals of the network state
+type countingProvider struct {
+	retrievals *int
+}
+
+func (countingProvider) Name() string {
+	return "counting"
+}
+
+func (p countingProvider) RetrieveRaw() ([]byte, error) {
+	*p.retrievals++
+	return []byte(`{"interfaces": [{"name": "eth0", "type": "ethernet", "state": "up"}]}`), nil
+}
+
+func TestNetStateRetrievedOncePerCycle(t *testing.T) {
+	defer net.SetProvider(net.CurrentProvider())
+	retrievals := 0
+	net.SetProvider(countingProvider{&retrievals})
+
+	defer func(f CheckFunctions, n map[string]NetStateCheckFunction) {
+		defaultCheckFunctions, netStateCheckFunctions = f, n
+	}(defaultCheckFunctions, netStateCheckFunctions)
+	defaultCheckFunctions = CheckFunctions{}
+	ifaces := func(checkType string, c Config, ns net.NetState) ([]byte, error) {
+		return []byte(ns.Ifaces[0].Name), nil
+	}
+	netStateCheckFunctions = map[string]NetStateCheckFunction{
+		CheckTypeNetStateLint: ifaces,
+		CheckTypeGateway:      ifaces,
+		CheckTypeDuplicateIP:  ifaces,
+		CheckTypePathMTU:      ifaces,
+	}
+
+	config := Config{CheckFrequency: time.Hour, RegistryEnvPath: "/nonexistent"}
+	logger := logrus.New()
+	logger.SetOutput(&bytes.Buffer{})
+	engine := NewEngine(nil, config, logger)
+
+	results := engine.RunOnce()
+	assert.Len(t, results, 4)
+	for _, r := range results {
+		assert.True(t, r.Success)
+		assert.Equal(t, "eth0", r.Details)
+	}
+	assert.Equal(t, 1, retrievals)
+
+	// each run retrieves the current state
+	engine.RunOnce()
+	assert.Equal(t, 2, retrievals)
+	engine.RunConnectivity()
+	assert.Equal(t, 3, retrievals)
+}
+
+func TestLocalRegistrySkipsOnlyReleaseImageChecks(t *testing.T) {
+	defer func(f CheckFunctions, n map[string]NetStateCheckFunction) {
+		defaultCheckFunctions, netStateCheckFunctions = f, n
+	}(defaultCheckFunctions, netStateCheckFunctions)
+	success := func(checkType string, c Config) ([]byte, error) {
+		return nil, nil
+	}
+	defaultCheckFunctions = CheckFunctions{CheckTypeReleaseImagePull: success}
+	netStateCheckFunctions = map[string]NetStateCheckFunction{
+		CheckTypeGateway: func(checkType string, c Config, ns net.NetState) ([]byte, error) {
+			return nil, nil
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	registryEnv := filepath.Join(t.TempDir(), "registry.env")
+	assert.NoError(t, os.WriteFile(registryEnv, nil, 0644))
+	config := Config{CheckFrequency: time.Hour, RegistryEnvPath: registryEnv, CheckEndpoints: []string{server.URL}}
+	logger := logrus.New()
+	logger.SetOutput(&bytes.Buffer{})
+	engine := NewEngine(nil, config, logger)
+
+	types := []string{}
+	for _, r := range engine.RunOnce() {
+		types = append(types, r.Type)
+		if r.Type == EndpointCheckType(server.URL) {
+			assert.True(t, r.Success, r.Details)
+		}
+	}
+	assert.ElementsMatch(t, []string{CheckTypeGateway, EndpointCheckType(server.URL)}, types)
+}
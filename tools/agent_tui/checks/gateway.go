@@ -0,0 +1,307 @@
+package checks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	stdnet "net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+// Diagnosis is the lowest layer of the network path that is not working
+type Diagnosis string
+
+const (
+	DiagnosisOK           Diagnosis = "local network OK, gateway and upstream reachable"
+	DiagnosisNoRoute      Diagnosis = "no default route"
+	DiagnosisLocalDown    Diagnosis = "local network down"
+	DiagnosisGatewayDown  Diagnosis = "gateway down"
+	DiagnosisDNSDown      Diagnosis = "local network OK, DNS servers down"
+	DiagnosisUpstreamDown Diagnosis = "local network OK, upstream down"
+)
+
+// The diagnoses, from the lowest layer
+var diagnosisLayers = []Diagnosis{
+	DiagnosisNoRoute,
+	DiagnosisLocalDown,
+	DiagnosisGatewayDown,
+	DiagnosisDNSDown,
+	DiagnosisUpstreamDown,
+	DiagnosisOK,
+}
+
+const probeTimeout = 2 * time.Second
+
+// gatewayProbes are the operations used to diagnose the path to the
+// default gateway, replaced by the tests
+type gatewayProbes struct {
+	carrier  func(iface string) (bool, error)
+	ping     func(iface string, ip string) error
+	neighbor func(ip stdnet.IP) (*net.Neighbor, error)
+	queryDNS func(server string) error
+}
+
+var probes = gatewayProbes{
+	carrier:  net.LinkCarrier,
+	ping:     pingFrom,
+	neighbor: net.LookupNeighbor,
+	queryDNS: queryDNS,
+}
+
+// GatewayReport is the result of the gateway check, with a line for
+// each probe
+type GatewayReport struct {
+	Diagnosis Diagnosis
+	Lines     []string
+}
+
+func (r GatewayReport) String() string {
+	return strings.Join(append(r.Lines, "Diagnosis: "+string(r.Diagnosis)), "\n")
+}
+
+func (r *GatewayReport) addf(format string, args ...interface{}) {
+	r.Lines = append(r.Lines, fmt.Sprintf(format, args...))
+}
+
+// worsen keeps the lowest layer between the current diagnosis and d
+func (r *GatewayReport) worsen(d Diagnosis) {
+	for _, layer := range diagnosisLayers {
+		if layer == r.Diagnosis {
+			return
+		}
+		if layer == d {
+			r.Diagnosis = d
+			return
+		}
+	}
+}
+
+// checkGateway diagnoses the path to the registry one layer at a time,
+// so that a wrong gateway is not reported as a generic DNS failure
+func checkGateway(checkType string, c Config, ns net.NetState) ([]byte, error) {
+	report := CheckGateway(ns)
+	if report.Diagnosis != DiagnosisOK {
+		return []byte(report.String()), errors.New(string(report.Diagnosis))
+	}
+	return []byte(report.String()), nil
+}
+
+// CheckGateway checks, for each family with a default route, that its
+// interface is up with carrier and an address, then that the gateway
+// answers to ARP/NDP and ICMP. Finally it queries the DNS servers,
+// telling apart the ones on-link from the ones reached via a gateway.
+func CheckGateway(ns net.NetState) GatewayReport {
+	report := GatewayReport{Diagnosis: DiagnosisOK}
+	gateways := map[net.Family]bool{}
+	for _, family := range net.Families {
+		route := ns.PrimaryDefaultRoute(family)
+		if route == nil {
+			continue
+		}
+		if !checkLocal(ns, *route, &report) {
+			report.worsen(DiagnosisLocalDown)
+			gateways[family] = false
+			continue
+		}
+		gateways[family] = checkGatewayNeighbor(*route, &report)
+		if !gateways[family] {
+			report.worsen(DiagnosisGatewayDown)
+		}
+	}
+	if len(gateways) == 0 {
+		report.addf("No default route")
+		report.worsen(DiagnosisNoRoute)
+		return report
+	}
+
+	checkDNSServers(ns, gateways, &report)
+	return report
+}
+
+// checkLocal checks the interface of the default route
+func checkLocal(ns net.NetState, route net.Route, report *GatewayReport) bool {
+	var iface *net.Iface
+	for i := range ns.Ifaces {
+		if ns.Ifaces[i].Name == route.NextHopIface {
+			iface = &ns.Ifaces[i]
+		}
+	}
+	switch {
+	case iface == nil:
+		report.addf("%s: default route interface %s not found", route.Family(), route.NextHopIface)
+		return false
+	case iface.State != "up":
+		report.addf("%s: interface %s is %s", route.Family(), iface.Name, iface.State)
+		return false
+	}
+	if carrier, err := probes.carrier(iface.Name); err == nil && !carrier {
+		report.addf("%s: interface %s has no carrier", route.Family(), iface.Name)
+		return false
+	}
+	config := iface.IPv4
+	if route.Family() == net.FamilyIPv6 {
+		config = iface.IPv6
+	}
+	if len(config.Addresses) == 0 {
+		report.addf("%s: interface %s has no address", route.Family(), iface.Name)
+		return false
+	}
+	report.addf("%s: interface %s is up with address %s", route.Family(), iface.Name, config.Addresses[0].String())
+	return true
+}
+
+// checkGatewayNeighbor pings the gateway, which resolves its link layer
+// address too. A gateway resolved but not answering to ICMP is still
+// considered up, since many of them drop the echo requests. An on-link
+// default route, without a next hop address, has no gateway to probe.
+func checkGatewayNeighbor(route net.Route, report *GatewayReport) bool {
+	if route.NextHopAddr == "" {
+		report.addf("%s: default route on-link on %s, no gateway", route.Family(), route.NextHopIface)
+		return true
+	}
+	gateway := stdnet.ParseIP(route.NextHopAddr)
+	if gateway == nil {
+		report.addf("%s: invalid gateway address %q", route.Family(), route.NextHopAddr)
+		return false
+	}
+
+	pingErr := probes.ping(route.NextHopIface, route.NextHopAddr)
+	resolution := "ARP"
+	if route.Family() == net.FamilyIPv6 {
+		resolution = "NDP"
+	}
+	neighbor, err := probes.neighbor(gateway)
+	resolved := err == nil && neighbor != nil && neighbor.Resolved()
+	switch {
+	case resolved:
+		report.addf("%s: gateway %s resolved by %s to %s (%s)", route.Family(), route.NextHopAddr, resolution, neighbor.MAC, neighbor.State)
+	case err != nil:
+		report.addf("%s: gateway %s neighbor lookup failed: %v", route.Family(), route.NextHopAddr, err)
+	default:
+		report.addf("%s: gateway %s not resolved by %s", route.Family(), route.NextHopAddr, resolution)
+	}
+	if pingErr != nil {
+		report.addf("%s: gateway %s doesn't answer to ping: %v", route.Family(), route.NextHopAddr, pingErr)
+	} else {
+		report.addf("%s: gateway %s answers to ping", route.Family(), route.NextHopAddr)
+	}
+	return resolved || pingErr == nil
+}
+
+// checkDNSServers queries each DNS server. The servers reached via a
+// gateway that is down are not queried, since the gateway is the
+// problem to fix first.
+func checkDNSServers(ns net.NetState, gateways map[net.Family]bool, report *GatewayReport) {
+	if len(ns.DNS.Running.Servers) == 0 {
+		report.addf("No DNS servers configured")
+		report.worsen(DiagnosisDNSDown)
+		return
+	}
+
+	answered, onLinkDown, upstreamDown := false, false, false
+	for _, server := range ns.DNS.Running.Servers {
+		host, _, _ := strings.Cut(server, "%")
+		ip := stdnet.ParseIP(host)
+		if ip == nil {
+			continue
+		}
+		family := net.FamilyIPv4
+		if ip.To4() == nil {
+			family = net.FamilyIPv6
+		}
+
+		onLink := strings.Contains(server, "%") || ns.OnLinkIface(ip) != ""
+		path := "on-link"
+		if !onLink {
+			route := ns.PrimaryDefaultRoute(family)
+			if route == nil || !gateways[family] {
+				report.addf("DNS server %s not queried, no working %s gateway", server, family)
+				continue
+			}
+			path = "via gateway " + route.NextHopAddr
+			if route.NextHopAddr == "" {
+				path = "via the on-link default route"
+			}
+		}
+
+		if err := probes.queryDNS(server); err != nil {
+			report.addf("DNS server %s (%s) doesn't answer: %v", server, path, err)
+			if onLink {
+				onLinkDown = true
+			} else {
+				upstreamDown = true
+			}
+			continue
+		}
+		report.addf("DNS server %s (%s) answers", server, path)
+		answered = true
+	}
+
+	// a single server answering is enough to resolve the names
+	switch {
+	case answered:
+	case upstreamDown:
+		report.worsen(DiagnosisUpstreamDown)
+	case onLinkDown:
+		report.worsen(DiagnosisDNSDown)
+	}
+}
+
+// pingFrom sends a single ICMP echo request from the interface
+func pingFrom(iface string, ip string) error {
+	output, err := exec.Command("ping", "-c", "1", "-W", fmt.Sprint(int(probeTimeout.Seconds())), "-I", iface, ip).CombinedOutput()
+	if err != nil {
+		if lines := strings.Split(strings.TrimSpace(string(output)), "\n"); len(lines) > 0 && lines[len(lines)-1] != "" {
+			return fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+		}
+		return err
+	}
+	return nil
+}
+
+// queryDNS sends a query for the NS records of the root zone, and
+// succeeds on any answer, even an error, since it proves the server is
+// reachable
+func queryDNS(server string) error {
+	return queryDNSAt(stdnet.JoinHostPort(server, "53"))
+}
+
+func queryDNSAt(address string) error {
+	conn, err := stdnet.DialTimeout("udp", address, probeTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(probeTimeout)); err != nil {
+		return err
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query := make([]byte, 12, 17)
+	binary.BigEndian.PutUint16(query[0:2], id)
+	// recursion desired, a single question
+	binary.BigEndian.PutUint16(query[2:4], 0x0100)
+	binary.BigEndian.PutUint16(query[4:6], 1)
+	// the root name, type NS, class IN
+	query = append(query, 0, 0, 2, 0, 1)
+	if _, err := conn.Write(query); err != nil {
+		return err
+	}
+
+	response := make([]byte, 512)
+	for {
+		n, err := conn.Read(response)
+		if err != nil {
+			return err
+		}
+		// the responses to other queries are ignored
+		if n >= 12 && binary.BigEndian.Uint16(response[0:2]) == id && response[2]&0x80 != 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package checks
+
+import (
+	"encoding/binary"
+	"errors"
+	stdnet "net"
+	"testing"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckGateway(t *testing.T) {
+	defer func(p gatewayProbes) { probes = p }(probes)
+
+	address := func(cidr string) stdnet.IPNet {
+		ip, network, err := stdnet.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		return stdnet.IPNet{IP: ip, Mask: network.Mask}
+	}
+	ns := net.NetState{
+		DNS: net.DNSResolver{Running: net.DNSConfig{Servers: []string{"192.168.111.53", "10.0.0.53"}}},
+		Routes: net.RoutesRC{Running: []net.Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1", Metric: 100, TableID: 254},
+		}},
+		Ifaces: []net.Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", IPv4: net.IPConfig{Addresses: []stdnet.IPNet{address("192.168.111.20/24")}}},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		carrier   bool
+		neighbor  string
+		ping      bool
+		answering map[string]bool
+		diagnosis Diagnosis
+		lines     []string
+	}{
+		{
+			name:      "ok",
+			carrier:   true,
+			neighbor:  "reachable",
+			ping:      true,
+			answering: map[string]bool{"192.168.111.53": true, "10.0.0.53": true},
+			diagnosis: DiagnosisOK,
+			lines: []string{
+				"IPv4: interface eth0 is up with address 192.168.111.20/24",
+				"IPv4: gateway 192.168.111.1 resolved by ARP to 52:54:00:12:34:56 (reachable)",
+				"IPv4: gateway 192.168.111.1 answers to ping",
+				"DNS server 192.168.111.53 (on-link) answers",
+				"DNS server 10.0.0.53 (via gateway 192.168.111.1) answers",
+			},
+		},
+		{
+			name:      "no carrier",
+			carrier:   false,
+			diagnosis: DiagnosisLocalDown,
+			lines: []string{
+				"IPv4: interface eth0 has no carrier",
+				"DNS server 192.168.111.53 (on-link) doesn't answer: timeout",
+				"DNS server 10.0.0.53 not queried, no working IPv4 gateway",
+			},
+		},
+		{
+			name:      "gateway down",
+			carrier:   true,
+			neighbor:  "failed",
+			answering: map[string]bool{"192.168.111.53": true},
+			diagnosis: DiagnosisGatewayDown,
+			lines: []string{
+				"IPv4: interface eth0 is up with address 192.168.111.20/24",
+				"IPv4: gateway 192.168.111.1 not resolved by ARP",
+				"IPv4: gateway 192.168.111.1 doesn't answer to ping: timeout",
+				"DNS server 192.168.111.53 (on-link) answers",
+				"DNS server 10.0.0.53 not queried, no working IPv4 gateway",
+			},
+		},
+		{
+			name:      "gateway dropping ICMP, upstream down",
+			carrier:   true,
+			neighbor:  "stale",
+			diagnosis: DiagnosisUpstreamDown,
+			lines: []string{
+				"IPv4: interface eth0 is up with address 192.168.111.20/24",
+				"IPv4: gateway 192.168.111.1 resolved by ARP to 52:54:00:12:34:56 (stale)",
+				"IPv4: gateway 192.168.111.1 doesn't answer to ping: timeout",
+				"DNS server 192.168.111.53 (on-link) doesn't answer: timeout",
+				"DNS server 10.0.0.53 (via gateway 192.168.111.1) doesn't answer: timeout",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			probes = gatewayProbes{
+				carrier: func(iface string) (bool, error) { return tc.carrier, nil },
+				ping: func(iface string, ip string) error {
+					if tc.ping {
+						return nil
+					}
+					return errors.New("timeout")
+				},
+				neighbor: func(ip stdnet.IP) (*net.Neighbor, error) {
+					if tc.neighbor == "" {
+						return nil, nil
+					}
+					return &net.Neighbor{IP: ip, MAC: "52:54:00:12:34:56", State: tc.neighbor}, nil
+				},
+				queryDNS: func(server string) error {
+					if tc.answering[server] {
+						return nil
+					}
+					return errors.New("timeout")
+				},
+			}
+			report := CheckGateway(ns)
+			assert.Equal(t, tc.diagnosis, report.Diagnosis)
+			assert.Equal(t, tc.lines, report.Lines)
+		})
+	}
+
+	report := CheckGateway(net.NetState{})
+	assert.Equal(t, DiagnosisNoRoute, report.Diagnosis)
+
+	// the on-link default routes have no gateway to probe
+	probes = gatewayProbes{
+		carrier: func(iface string) (bool, error) { return true, nil },
+		ping: func(iface string, ip string) error {
+			t.Errorf("no gateway to ping, pinged %q", ip)
+			return nil
+		},
+		neighbor: func(ip stdnet.IP) (*net.Neighbor, error) {
+			t.Errorf("no gateway to resolve, resolved %s", ip)
+			return nil, nil
+		},
+		queryDNS: func(server string) error { return nil },
+	}
+	ns.Routes.Running = []net.Route{{Destination: "0.0.0.0/0", NextHopIface: "eth0", Metric: 100, TableID: 254}}
+	report = CheckGateway(ns)
+	assert.Equal(t, DiagnosisOK, report.Diagnosis)
+	assert.Equal(t, []string{
+		"IPv4: interface eth0 is up with address 192.168.111.20/24",
+		"IPv4: default route on-link on eth0, no gateway",
+		"DNS server 192.168.111.53 (on-link) answers",
+		"DNS server 10.0.0.53 (via the on-link default route) answers",
+	}, report.Lines)
+}
+
+func TestQueryDNS(t *testing.T) {
+	conn, err := stdnet.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on the loopback: %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 512)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil || n < 12 {
+			return
+		}
+		// an unrelated response first, then the matching one with SERVFAIL
+		other := append([]byte{}, buf[:n]...)
+		binary.BigEndian.PutUint16(other[0:2], binary.BigEndian.Uint16(buf[0:2])+1)
+		other[2] |= 0x80
+		_, _ = conn.WriteTo(other, addr)
+		buf[2] |= 0x80
+		buf[3] |= 0x02
+		_, _ = conn.WriteTo(buf[:n], addr)
+	}()
+
+	assert.NoError(t, queryDNSAt(conn.LocalAddr().String()))
+}
@@ -0,0 +1,105 @@
+package checks
+
+import (
+	"sync"
+	"time"
+)
+
+// Number of results kept for each check. With the default check
+// frequency of 5 seconds, this covers the last 5 minutes.
+const defaultHistorySize = 60
+
+// HistoryEntry records the outcome of a single check execution
+type HistoryEntry struct {
+	Timestamp time.Time
+	Success   bool
+	Duration  time.Duration
+}
+
+// HistorySummary is a point in time copy of a check history,
+// safe to be sent over to the UI
+type HistorySummary struct {
+	// Entries are sorted from the oldest to the newest
+	Entries []HistoryEntry
+	// LastChange is the time when the check status last changed
+	LastChange time.Time
+}
+
+// PassRatio returns the fraction of successful entries, between 0 and 1
+func (s HistorySummary) PassRatio() float64 {
+	if len(s.Entries) == 0 {
+		return 0
+	}
+	passed := 0
+	for _, e := range s.Entries {
+		if e.Success {
+			passed++
+		}
+	}
+	return float64(passed) / float64(len(s.Entries))
+}
+
+// History is a fixed size ring buffer containing the most
+// recent results of a check
+type History struct {
+	mu         sync.Mutex
+	entries    []HistoryEntry
+	next       int
+	full       bool
+	lastChange time.Time
+}
+
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	return &History{
+		entries: make([]HistoryEntry, size),
+	}
+}
+
+// Add stores a new entry, overwriting the oldest one when the buffer
+// is full. Returns true if the entry changed the check status, or if it
+// is the first one.
+func (h *History) Add(e HistoryEntry) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	changed := false
+	if last, ok := h.last(); !ok || last.Success != e.Success {
+		h.lastChange = e.Timestamp
+		changed = true
+	}
+
+	h.entries[h.next] = e
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+	return changed
+}
+
+func (h *History) last() (HistoryEntry, bool) {
+	if h.next == 0 && !h.full {
+		return HistoryEntry{}, false
+	}
+	idx := (h.next - 1 + len(h.entries)) % len(h.entries)
+	return h.entries[idx], true
+}
+
+// Summary returns a copy of the current history content
+func (h *History) Summary() HistorySummary {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var entries []HistoryEntry
+	if h.full {
+		entries = append(entries, h.entries[h.next:]...)
+	}
+	entries = append(entries, h.entries[:h.next]...)
+
+	return HistorySummary{
+		Entries:    entries,
+		LastChange: h.lastChange,
+	}
+}
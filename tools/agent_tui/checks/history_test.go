@@ -0,0 +1,81 @@
+package checks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time {
+		return start.Add(time.Duration(sec) * time.Second)
+	}
+
+	cases := []struct {
+		name               string
+		size               int
+		results            []bool
+		expectedSuccesses  []bool
+		expectedPassRatio  float64
+		expectedLastChange time.Time
+	}{
+		{
+			name:               "empty",
+			size:               3,
+			expectedPassRatio:  0,
+			expectedLastChange: time.Time{},
+		},
+		{
+			name:               "partially filled",
+			size:               3,
+			results:            []bool{true, false},
+			expectedSuccesses:  []bool{true, false},
+			expectedPassRatio:  0.5,
+			expectedLastChange: at(1),
+		},
+		{
+			name:               "wrap around keeps the most recent entries",
+			size:               3,
+			results:            []bool{false, false, true, true, false},
+			expectedSuccesses:  []bool{true, true, false},
+			expectedPassRatio:  2.0 / 3.0,
+			expectedLastChange: at(4),
+		},
+		{
+			name:               "status never changed",
+			size:               2,
+			results:            []bool{true, true, true},
+			expectedSuccesses:  []bool{true, true},
+			expectedPassRatio:  1,
+			expectedLastChange: at(0),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHistory(tc.size)
+			for i, success := range tc.results {
+				h.Add(HistoryEntry{
+					Timestamp: at(i),
+					Success:   success,
+					Duration:  time.Duration(i) * time.Millisecond,
+				})
+			}
+
+			summary := h.Summary()
+			successes := []bool{}
+			for _, e := range summary.Entries {
+				successes = append(successes, e.Success)
+			}
+			if tc.expectedSuccesses == nil {
+				assert.Empty(t, summary.Entries)
+			} else {
+				assert.Equal(t, tc.expectedSuccesses, successes)
+			}
+			assert.InDelta(t, tc.expectedPassRatio, summary.PassRatio(), 0.001)
+			assert.Equal(t, tc.expectedLastChange, summary.LastChange)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package checks
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+// NetStateCheckFunction is a check based on the network state, which
+// is retrieved once for all of them
+type NetStateCheckFunction func(checkType string, config Config, ns net.NetState) ([]byte, error)
+
+var netStateCheckFunctions = map[string]NetStateCheckFunction{
+	CheckTypeNetStateLint: lintNetState,
+	CheckTypeGateway:      checkGateway,
+	CheckTypeDuplicateIP:  checkDuplicateIP,
+	CheckTypePathMTU:      checkPathMTU,
+}
+
+// netStateSnapshot is the network state shared by the checks based on
+// it, so that it's retrieved once per check cycle instead of once per
+// check
+type netStateSnapshot struct {
+	mu        sync.Mutex
+	maxAge    time.Duration
+	retrieved time.Time
+	ns        net.NetState
+	err       error
+}
+
+// get returns the network state, retrieved again when older than
+// maxAge or after reset
+func (s *netStateSnapshot) get() (net.NetState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retrieved.IsZero() || time.Since(s.retrieved) >= s.maxAge {
+		s.ns, s.err = net.RetrieveNetState()
+		s.retrieved = time.Now()
+	}
+	return s.ns, s.err
+}
+
+func (s *netStateSnapshot) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retrieved = time.Time{}
+}
+
+// checkFunction adapts f to the state of the snapshot
+func (s *netStateSnapshot) checkFunction(f NetStateCheckFunction) CheckFunction {
+	return func(checkType string, c Config) ([]byte, error) {
+		ns, err := s.get()
+		if err != nil {
+			return []byte(err.Error()), err
+		}
+		return f(checkType, c, ns)
+	}
+}
+
+// lintNetState reports the problems found in the current network
+// state. The check fails only for the errors, the warnings are
+// reported in the output of a successful check.
+func lintNetState(checkType string, c Config, ns net.NetState) ([]byte, error) {
+	findings := net.Lint(ns)
+	lines := []string{}
+	for _, f := range findings {
+		lines = append(lines, f.String())
+	}
+	output := []byte(strings.Join(lines, "\n"))
+	if net.HasErrors(findings) {
+		return output, errors.New("network state errors")
+	}
+	return output, nil
+}
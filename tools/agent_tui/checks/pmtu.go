@@ -0,0 +1,270 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	stdnet "net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
+)
+
+const (
+	// the size of the IP and ICMP headers, added to the ping payload
+	icmpv4Overhead = 20 + 8
+	icmpv6Overhead = 40 + 8
+
+	// the minimum MTU every link must support
+	minIPv4MTU = 576
+	minIPv6MTU = 1280
+)
+
+// The MTU reported by the ICMP errors, or by the kernel when it already
+// knows the path MTU, in the ping output
+var pingMTURegexp = regexp.MustCompile(`(?i)mtu ?= ?(\d+)`)
+
+// pmtuProbes are the operations used to discover the path MTU, replaced
+// by the tests
+type pmtuProbes struct {
+	lookupIP func(host string) ([]stdnet.IP, error)
+	sourceIP func(dst stdnet.IP) (stdnet.IP, error)
+	// pingDF sends an echo request of size bytes, headers included,
+	// that must not be fragmented. It returns the MTU reported by the
+	// "fragmentation needed" or "packet too big" errors, if any.
+	pingDF func(dst stdnet.IP, size int) (int, error)
+}
+
+var mtuProbes = pmtuProbes{
+	lookupIP: stdnet.LookupIP,
+	sourceIP: sourceIP,
+	pingDF:   pingDF,
+}
+
+// PathMTU is the result of the path MTU discovery toward a host
+type PathMTU struct {
+	Target   string
+	IP       string
+	Iface    string
+	IfaceMTU int
+	// PathMTU is 0 if the host doesn't answer to ping
+	PathMTU int
+	// Reported is set when a router reported the path MTU, so that the
+	// TCP connections adapt to it. Otherwise the larger packets are
+	// silently dropped, and the connections hang.
+	Reported bool
+}
+
+// Blackhole returns true if the packets as large as the interface MTU
+// are dropped without notice
+func (p PathMTU) Blackhole() bool {
+	return p.PathMTU != 0 && p.PathMTU < p.IfaceMTU && !p.Reported
+}
+
+func (p PathMTU) String() string {
+	target := fmt.Sprintf("%s (%s)", p.Target, p.IP)
+	if p.Target == p.IP {
+		target = p.Target
+	}
+	switch {
+	case p.PathMTU == 0:
+		return fmt.Sprintf("%s doesn't answer to ping, path MTU not measured", target)
+	case p.PathMTU >= p.IfaceMTU:
+		return fmt.Sprintf("%s: path MTU matches the MTU %d of %s", target, p.IfaceMTU, p.Iface)
+	case p.Reported:
+		return fmt.Sprintf("%s: path MTU %d reported by a router, lower than the MTU %d of %s", target, p.PathMTU, p.IfaceMTU, p.Iface)
+	default:
+		return fmt.Sprintf("%s: packets larger than %d bytes are dropped without notice, while the MTU of %s is %d. Lower the MTU of %s, or fix the MTU along the path",
+			target, p.PathMTU, p.Iface, p.IfaceMTU, p.Iface)
+	}
+}
+
+// mtuCache keeps the result of the periodic check, discovered again
+// only when the targets, the routes or the MTUs change
+var mtuCache probeCache
+
+// pathMTUResult is the value of mtuCache
+type pathMTUResult struct {
+	output []byte
+	err    error
+	// failed is set when a target couldn't be probed, for example when
+	// its name isn't resolved yet
+	failed bool
+}
+
+// checkPathMTU discovers the path MTU toward the release image registry
+// and the rendezvous node. It fails only when the larger packets are
+// dropped without notice, the MTU reported by a router is a warning.
+// The discovery isn't repeated at every run, see mtuCache, unless a
+// target couldn't be probed.
+func checkPathMTU(checkType string, c Config, ns net.NetState) ([]byte, error) {
+	rendezvousHostEnvPath := c.RendezvousHostEnvPath
+	if rendezvousHostEnvPath == "" {
+		rendezvousHostEnvPath = rendezvous.HostEnvPath
+	}
+	targets := []string{}
+	for _, target := range []string{c.ReleaseImageHostname, rendezvous.Read(rendezvousHostEnvPath)} {
+		if target != "" {
+			targets = append(targets, target)
+		}
+	}
+
+	result := mtuCache.get(pathMTUKey(ns, targets), func() interface{} {
+		return discoverPathMTUs(ns, targets)
+	}).(pathMTUResult)
+	if result.failed {
+		mtuCache.reset()
+	}
+	return result.output, result.err
+}
+
+// pathMTUKey identifies the targets, routes and MTUs the path MTU
+// discovery depends on
+func pathMTUKey(ns net.NetState, targets []string) string {
+	key := append([]string{}, targets...)
+	for _, route := range ns.Routes.Running {
+		key = append(key, fmt.Sprintf("%s/%s/%s/%d", route.Destination, route.NextHopIface, route.NextHopAddr, route.Metric))
+	}
+	for _, iface := range ns.Ifaces {
+		key = append(key, fmt.Sprintf("%s/%s/%d", iface.Name, iface.State, iface.MTU))
+	}
+	return strings.Join(key, " ")
+}
+
+func discoverPathMTUs(ns net.NetState, targets []string) pathMTUResult {
+	lines := []string{}
+	blackhole, failed := false, false
+	for _, target := range targets {
+		result, err := DiscoverPathMTU(ns, target)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("%s: %v", target, err))
+			failed = true
+			continue
+		}
+		// nothing to report when the path MTU matches
+		if result == nil || result.PathMTU >= result.IfaceMTU {
+			continue
+		}
+		lines = append(lines, result.String())
+		blackhole = blackhole || result.Blackhole()
+	}
+	output := []byte(strings.Join(lines, "\n"))
+	if blackhole {
+		return pathMTUResult{output: output, err: errors.New("path MTU mismatch"), failed: failed}
+	}
+	return pathMTUResult{output: output, failed: failed}
+}
+
+// DiscoverPathMTU sends echo requests with the don't fragment bit set
+// toward the target, first as large as the MTU of the interface used to
+// reach it, then searching for the largest one answered. It returns nil
+// if the target is an address of this host.
+func DiscoverPathMTU(ns net.NetState, target string) (*PathMTU, error) {
+	ip := stdnet.ParseIP(target)
+	if ip == nil {
+		ips, err := mtuProbes.lookupIP(target)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no address found for %s", target)
+		}
+		ip = ips[0]
+	}
+	src, err := mtuProbes.sourceIP(ip)
+	if err != nil {
+		return nil, err
+	}
+	if ip.IsLoopback() || src.Equal(ip) {
+		return nil, nil
+	}
+
+	result := &PathMTU{Target: target, IP: ip.String()}
+	for _, iface := range ns.Ifaces {
+		for _, config := range []net.IPConfig{iface.IPv4, iface.IPv6} {
+			for _, address := range config.Addresses {
+				if address.IP.Equal(src) {
+					result.Iface = iface.Name
+					result.IfaceMTU = iface.MTU
+				}
+			}
+		}
+	}
+	if result.Iface == "" {
+		return nil, fmt.Errorf("no interface has the source address %s", src)
+	}
+
+	minMTU := minIPv4MTU
+	if ip.To4() == nil {
+		minMTU = minIPv6MTU
+	}
+	if result.IfaceMTU <= minMTU {
+		result.PathMTU = result.IfaceMTU
+		return result, nil
+	}
+
+	reported, err := mtuProbes.pingDF(ip, result.IfaceMTU)
+	if err == nil {
+		result.PathMTU = result.IfaceMTU
+		return result, nil
+	}
+	// the size reported is checked first, since it's usually right
+	if reported >= minMTU && reported < result.IfaceMTU {
+		if _, err := mtuProbes.pingDF(ip, reported); err == nil {
+			result.PathMTU = reported
+			result.Reported = true
+			return result, nil
+		}
+	}
+	if _, err := mtuProbes.pingDF(ip, minMTU); err != nil {
+		return result, nil
+	}
+
+	// largest known to pass and smallest known to be dropped
+	passed, dropped := minMTU, result.IfaceMTU
+	for dropped-passed > 1 {
+		size := (passed + dropped) / 2
+		reported, err := mtuProbes.pingDF(ip, size)
+		switch {
+		case err == nil:
+			passed = size
+		case reported >= minMTU && reported < size:
+			result.Reported = true
+			dropped = min(dropped, reported+1)
+		default:
+			dropped = size
+		}
+	}
+	result.PathMTU = passed
+	return result, nil
+}
+
+// sourceIP returns the address used by this host to reach dst. No
+// packet is sent, connecting an UDP socket only selects the route.
+func sourceIP(dst stdnet.IP) (stdnet.IP, error) {
+	conn, err := stdnet.DialUDP("udp", nil, &stdnet.UDPAddr{IP: dst, Port: 9})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*stdnet.UDPAddr).IP, nil
+}
+
+func pingDF(dst stdnet.IP, size int) (int, error) {
+	overhead := icmpv4Overhead
+	if dst.To4() == nil {
+		overhead = icmpv6Overhead
+	}
+	output, err := exec.Command("ping", "-c", "1", "-W", "1", "-M", "do", "-s", strconv.Itoa(size-overhead), dst.String()).CombinedOutput()
+	if err == nil {
+		return 0, nil
+	}
+	if m := pingMTURegexp.FindSubmatch(output); m != nil {
+		mtu, _ := strconv.Atoi(string(m[1]))
+		return mtu, err
+	}
+	return 0, err
+}
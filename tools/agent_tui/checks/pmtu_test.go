@@ -0,0 +1,196 @@
+package checks
+
+import (
+	"errors"
+	stdnet "net"
+	"testing"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverPathMTU(t *testing.T) {
+	defer func(p pmtuProbes) { mtuProbes = p }(mtuProbes)
+
+	address := func(cidr string) stdnet.IPNet {
+		ip, network, err := stdnet.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		return stdnet.IPNet{IP: ip, Mask: network.Mask}
+	}
+	ns := net.NetState{
+		Ifaces: []net.Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MTU: 9000, IPv4: net.IPConfig{Addresses: []stdnet.IPNet{address("192.168.111.20/24")}},
+				IPv6: net.IPConfig{Addresses: []stdnet.IPNet{address("fd00::20/64")}}},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		target    string
+		pathMTU   int
+		reports   bool
+		answers   bool
+		expected  *PathMTU
+		blackhole bool
+		line      string
+	}{
+		{
+			name:     "jumbo frames all the way",
+			target:   "quay.io",
+			pathMTU:  9000,
+			answers:  true,
+			expected: &PathMTU{Target: "quay.io", IP: "192.0.2.10", Iface: "eth0", IfaceMTU: 9000, PathMTU: 9000},
+			line:     "quay.io (192.0.2.10): path MTU matches the MTU 9000 of eth0",
+		},
+		{
+			name:      "blackhole",
+			target:    "quay.io",
+			pathMTU:   1500,
+			answers:   true,
+			expected:  &PathMTU{Target: "quay.io", IP: "192.0.2.10", Iface: "eth0", IfaceMTU: 9000, PathMTU: 1500},
+			blackhole: true,
+			line:      "quay.io (192.0.2.10): packets larger than 1500 bytes are dropped without notice, while the MTU of eth0 is 9000. Lower the MTU of eth0, or fix the MTU along the path",
+		},
+		{
+			name:     "reported by a router",
+			target:   "fd00::80",
+			pathMTU:  1400,
+			reports:  true,
+			answers:  true,
+			expected: &PathMTU{Target: "fd00::80", IP: "fd00::80", Iface: "eth0", IfaceMTU: 9000, PathMTU: 1400, Reported: true},
+			line:     "fd00::80: path MTU 1400 reported by a router, lower than the MTU 9000 of eth0",
+		},
+		{
+			name:     "no answer",
+			target:   "192.168.111.80",
+			pathMTU:  9000,
+			expected: &PathMTU{Target: "192.168.111.80", IP: "192.168.111.80", Iface: "eth0", IfaceMTU: 9000},
+			line:     "192.168.111.80 doesn't answer to ping, path MTU not measured",
+		},
+		{
+			name:   "this host",
+			target: "192.168.111.20",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			probes := 0
+			mtuProbes = pmtuProbes{
+				lookupIP: func(host string) ([]stdnet.IP, error) {
+					return []stdnet.IP{stdnet.ParseIP("192.0.2.10")}, nil
+				},
+				sourceIP: func(dst stdnet.IP) (stdnet.IP, error) {
+					if dst.String() == "192.168.111.20" {
+						return dst, nil
+					}
+					if dst.To4() == nil {
+						return stdnet.ParseIP("fd00::20"), nil
+					}
+					return stdnet.ParseIP("192.168.111.20"), nil
+				},
+				pingDF: func(dst stdnet.IP, size int) (int, error) {
+					probes++
+					switch {
+					case !tc.answers:
+						return 0, errors.New("timeout")
+					case size <= tc.pathMTU:
+						return 0, nil
+					case tc.reports:
+						return tc.pathMTU, errors.New("packet too big")
+					default:
+						return 0, errors.New("timeout")
+					}
+				},
+			}
+
+			result, err := DiscoverPathMTU(ns, tc.target)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+			if result != nil {
+				assert.Equal(t, tc.blackhole, result.Blackhole())
+				assert.Equal(t, tc.line, result.String())
+			}
+			// the binary search needs a few probes only
+			assert.LessOrEqual(t, probes, 16)
+		})
+	}
+}
+
+func TestCheckPathMTUDiscoversOnChanges(t *testing.T) {
+	defer func(p pmtuProbes) { mtuProbes = p }(mtuProbes)
+	resetProbeCaches()
+	defer resetProbeCaches()
+
+	netState := func(mtu int) net.NetState {
+		return net.NetState{Ifaces: []net.Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MTU: mtu, IPv4: net.IPConfig{Enabled: true, Addresses: []stdnet.IPNet{
+				{IP: stdnet.ParseIP("192.168.111.20"), Mask: stdnet.CIDRMask(24, 32)},
+			}}},
+		}}
+	}
+	config := Config{ReleaseImageHostname: "registry.example.com", RendezvousHostEnvPath: "/nonexistent"}
+
+	resolved := false
+	pings := 0
+	mtuProbes = pmtuProbes{
+		lookupIP: func(host string) ([]stdnet.IP, error) {
+			if !resolved {
+				return nil, errors.New("no such host")
+			}
+			return []stdnet.IP{stdnet.ParseIP("192.0.2.10")}, nil
+		},
+		sourceIP: func(dst stdnet.IP) (stdnet.IP, error) {
+			return stdnet.ParseIP("192.168.111.20"), nil
+		},
+		pingDF: func(dst stdnet.IP, size int) (int, error) {
+			pings++
+			if size <= 1500 {
+				return 0, nil
+			}
+			return 0, errors.New("timeout")
+		},
+	}
+
+	// the targets not probed are retried at the next run
+	ns := netState(9000)
+	output, err := checkPathMTU(CheckTypePathMTU, config, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, "registry.example.com: no such host", string(output))
+	resolved = true
+	output, err = checkPathMTU(CheckTypePathMTU, config, ns)
+	assert.EqualError(t, err, "path MTU mismatch")
+	assert.Contains(t, string(output), "packets larger than 1500 bytes are dropped without notice")
+	discovery := pings
+	assert.Greater(t, discovery, 0)
+
+	// the result is reused until the MTU changes
+	_, err = checkPathMTU(CheckTypePathMTU, config, ns)
+	assert.EqualError(t, err, "path MTU mismatch")
+	assert.Equal(t, discovery, pings)
+	ns = netState(1500)
+	_, err = checkPathMTU(CheckTypePathMTU, config, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, discovery+1, pings)
+
+	// Trigger discovers it again
+	engine := NewEngine(nil, Config{RegistryEnvPath: "/nonexistent"}, logrus.New(), CheckFunctions{})
+	engine.Trigger()
+	_, err = checkPathMTU(CheckTypePathMTU, config, ns)
+	assert.NoError(t, err)
+	assert.Equal(t, discovery+2, pings)
+}
+
+func TestPingMTURegexp(t *testing.T) {
+	for output, mtu := range map[string]string{
+		"From 192.168.111.1 icmp_seq=1 Frag needed and DF set (mtu = 1400)": "1400",
+		"ping: local error: message too long, mtu=1400":                     "1400",
+		"From fd00::1 icmp_seq=1 Packet too big: mtu=1280":                  "1280",
+	} {
+		m := pingMTURegexp.FindStringSubmatch(output)
+		if assert.NotNil(t, m, output) {
+			assert.Equal(t, mtu, m[1])
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package checks
+
+import "sync"
+
+// probeCache keeps the result of the probes sent on the network by a
+// check, too slow or too noisy to be sent at every run. They are sent
+// again only when the part of the network state they depend on
+// changes, or after the checks are triggered.
+type probeCache struct {
+	mu    sync.Mutex
+	key   string
+	valid bool
+	value interface{}
+}
+
+// get returns the value of the previous probes when sent with the same
+// key, and calls probe otherwise
+func (c *probeCache) get(key string, probe func() interface{}) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.valid || c.key != key {
+		c.key, c.value, c.valid = key, probe(), true
+	}
+	return c.value
+}
+
+func (c *probeCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.valid, c.value = false, nil
+}
+
+// probeCaches are reset by Trigger
+var probeCaches = []*probeCache{&arpCache, &mtuCache}
+
+func resetProbeCaches() {
+	for _, c := range probeCaches {
+		c.reset()
+	}
+}
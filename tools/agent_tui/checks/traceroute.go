@@ -0,0 +1,56 @@
+package checks
+
+import (
+	"fmt"
+	stdnet "net"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
+)
+
+// TracerouteTarget is a host whose path can be traced when the
+// connectivity checks fail
+type TracerouteTarget struct {
+	// Name tells what the host is used for
+	Name string
+	Host string
+}
+
+func (t TracerouteTarget) String() string {
+	return fmt.Sprintf("%s (%s)", t.Host, t.Name)
+}
+
+// TracerouteTargets returns the release image registry and the
+// rendezvous node, when they are known
+func TracerouteTargets(c Config) []TracerouteTarget {
+	rendezvousHostEnvPath := c.RendezvousHostEnvPath
+	if rendezvousHostEnvPath == "" {
+		rendezvousHostEnvPath = rendezvous.HostEnvPath
+	}
+	targets := []TracerouteTarget{}
+	if c.ReleaseImageHostname != "" {
+		targets = append(targets, TracerouteTarget{Name: "release image", Host: c.ReleaseImageHostname})
+	}
+	if ip := rendezvous.Read(rendezvousHostEnvPath); ip != "" {
+		targets = append(targets, TracerouteTarget{Name: "rendezvous IP", Host: ip})
+	}
+	return targets
+}
+
+// Traceroute resolves the host, if needed, and traces the path toward
+// its first address, calling hop for each TTL. It returns the address
+// traced.
+func Traceroute(host string, opts net.TracerouteOptions, hop func(net.Hop), stop <-chan struct{}) (stdnet.IP, error) {
+	ip := stdnet.ParseIP(host)
+	if ip == nil {
+		ips, err := stdnet.LookupIP(host)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no address found for %s", host)
+		}
+		ip = ips[0]
+	}
+	return ip, net.Traceroute(ip, opts, hop, stop)
+}
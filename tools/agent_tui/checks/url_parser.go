@@ -38,3 +38,22 @@ func ParseSchemeHostnamePortFromURL(urlString string, schemeIfMissing string) (s
 		return fmt.Sprintf("%s://%s", parsedUrl.Scheme, parsedUrl.Hostname()), nil
 	}
 }
+
+// PrepareConfig fills the fields derived from the release image URL
+func PrepareConfig(config *Config) error {
+	// Set hostname
+	hostname, err := ParseHostnameFromURL(config.ReleaseImageURL)
+	if err != nil {
+		return err
+	}
+	config.ReleaseImageHostname = hostname
+
+	// Set scheme
+	schemeHostnamePort, err := ParseSchemeHostnamePortFromURL(config.ReleaseImageURL, "https://")
+	if err != nil {
+		return fmt.Errorf("error creating <scheme>://<hostname>:<port> from releaseImageURL: %s", config.ReleaseImageURL)
+	}
+	config.ReleaseImageSchemeHostnamePort = schemeHostnamePort
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/logging"
+)
+
+type checkCommand struct {
+	output  string
+	verbose bool
+}
+
+func newCheckCommand() *command {
+	c := &checkCommand{}
+	return &command{
+		name:        "check",
+		synopsis:    "[flags]",
+		description: "Run the connectivity checks once and report their results",
+		flags: func(fs *flag.FlagSet) {
+			outputFlag(fs, &c.output, outputText)
+			fs.BoolVar(&c.verbose, "verbose", false, "print the output of the successful checks too")
+		},
+		run: c.run,
+	}
+}
+
+// checkResult is the printable version of checks.CheckResult
+type checkResult struct {
+	Type     string `json:"type"`
+	Success  bool   `json:"success"`
+	Duration string `json:"duration"`
+	Details  string `json:"details,omitempty"`
+}
+
+func (c *checkCommand) run(env Env, opts *config.Options, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+
+	results, err := runChecks(opts)
+	if err != nil {
+		return err
+	}
+
+	if c.output == outputText {
+		writeCheckResults(env.Stdout, results, c.verbose)
+	} else if err := writeStructured(env.Stdout, c.output, results); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if !r.Success {
+			return &exitError{code: 1}
+		}
+	}
+	return nil
+}
+
+// runChecks runs all the checks once, using the same engine of the TUI
+func runChecks(opts *config.Options) ([]checkResult, error) {
+	engineResults, err := runEngineChecks(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []checkResult{}
+	for _, r := range engineResults {
+		results = append(results, checkResult{
+			Type:     r.Type,
+			Success:  r.Success,
+			Duration: r.Duration.Round(time.Millisecond).String(),
+			Details:  r.Details,
+		})
+	}
+	return results, nil
+}
+
+// runEngineChecks and runConnectivityChecks can be replaced by the tests
+var (
+	runEngineChecks       = engineChecks
+	runConnectivityChecks = engineConnectivityChecks
+)
+
+func engineChecks(opts *config.Options) ([]checks.CheckResult, error) {
+	var results []checks.CheckResult
+	err := withEngine(opts, func(engine *checks.Engine) {
+		results = engine.RunOnce()
+	})
+	return results, err
+}
+
+// engineConnectivityChecks runs only the quick connectivity checks, to
+// compare the connectivity before and after a network change
+func engineConnectivityChecks(opts *config.Options) ([]checks.CheckResult, error) {
+	var results []checks.CheckResult
+	err := withEngine(opts, func(engine *checks.Engine) {
+		results = engine.RunConnectivity()
+	})
+	return results, err
+}
+
+// withEngine calls run with the same engine of the TUI, logging to the
+// agent-tui log
+func withEngine(opts *config.Options, run func(engine *checks.Engine)) error {
+	if opts.ReleaseImage == "" {
+		return fmt.Errorf("the release image must be specified with --release-image or RELEASE_IMAGE")
+	}
+	checksConfig := opts.ChecksConfig()
+	if err := checks.PrepareConfig(&checksConfig); err != nil {
+		return err
+	}
+
+	logger, logFile, err := logging.NewLogger(logging.Options{
+		Path:       opts.LogPath,
+		Level:      opts.LogLevel,
+		Format:     opts.LogFormat,
+		MaxSizeMB:  opts.LogMaxSizeMB,
+		MaxBackups: opts.LogMaxBackups,
+	})
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	run(checks.NewEngine(nil, checksConfig, logger))
+	return nil
+}
+
+func writeCheckResults(w io.Writer, results []checkResult, verbose bool) {
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No checks to run, a local registry is configured")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDURATION")
+	for _, r := range results {
+		status := "PASS"
+		if !r.Success {
+			status = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Type, status, r.Duration)
+	}
+	tw.Flush()
+
+	for _, r := range results {
+		if r.Success && !verbose {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n%s\n", r.Type, strings.TrimSpace(r.Details))
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+const programName = "agent-tui"
+
+// Env contains what a command needs to interact with the outside world
+type Env struct {
+	Getenv func(string) string
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// command is an agent-tui subcommand. Every command accepts the same
+// options of the TUI, plus its own flags.
+type command struct {
+	name        string
+	synopsis    string
+	description string
+	flags       func(fs *flag.FlagSet)
+	run         func(env Env, opts *config.Options, args []string) error
+}
+
+// exitError allows a command to fail with a specific exit code,
+// without printing any further message
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
+func commands() map[string]func() *command {
+	return map[string]func() *command{
+		"check":      newCheckCommand,
+		"export":     newExportCommand,
+		"import":     newImportCommand,
+		"netstate":   newNetStateCommand,
+		"rendezvous": newRendezvousCommand,
+		"report":     newReportCommand,
+	}
+}
+
+// IsCommand returns true if name is one of the agent-tui subcommands
+func IsCommand(name string) bool {
+	_, found := commands()[name]
+	return found || name == "help"
+}
+
+// Run executes the subcommand found in args[0], returning the exit code
+func Run(args []string, env Env) int {
+	if len(args) == 0 || args[0] == "help" {
+		usage(env.Stdout)
+		return 0
+	}
+	newCommand, found := commands()[args[0]]
+	if !found {
+		fmt.Fprintf(env.Stderr, "Unknown command %q\n\n", args[0])
+		usage(env.Stderr)
+		return 2
+	}
+	cmd := newCommand()
+
+	name := fmt.Sprintf("%s %s", programName, cmd.name)
+	opts, remaining, err := config.LoadWithFlags(name, cmd.synopsis, args[1:], env.Getenv, env.Stderr, cmd.flags)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	// the options are validated, so the provider is known to be valid
+	provider, _ := opts.Provider()
+	net.SetProvider(provider)
+
+	if err := cmd.run(env, opts, remaining); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			return exitErr.code
+		}
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [flags]\n", programName)
+	fmt.Fprintf(w, "       %s <command> [flags] [arguments]\n\n", programName)
+	fmt.Fprintf(w, "Without a command, the full screen interface is started.\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+
+	names := []string{}
+	for name := range commands() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := commands()[name]()
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> --help' for the command flags.\n", programName)
+}
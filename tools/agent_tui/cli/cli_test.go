@@ -0,0 +1,357 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	gonet "net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/media"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
+	"github.com/stretchr/testify/assert"
+)
+
+func runCommand(args []string, env map[string]string) (int, string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := Run(args, Env{
+		Getenv: func(name string) string { return env[name] },
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	return code, stdout.String(), stderr.String()
+}
+
+func testNetState() net.NetState {
+	_, ipNet, _ := gonet.ParseCIDR("192.168.111.0/24")
+	ipNet.IP = gonet.ParseIP("192.168.111.80").To4()
+	return net.NetState{
+		Hostname: net.Hostname{Running: "master-0"},
+		DNS: net.DNSResolver{Running: net.DNSConfig{
+			Servers: []string{"192.168.111.1"},
+		}},
+		Routes: net.RoutesRC{Running: []net.Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1"},
+			{Destination: "192.168.111.0/24", NextHopIface: "eth0"},
+		}},
+		Ifaces: []net.Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MTU: 1500, IPv4: net.IPConfig{Enabled: true, Addresses: []gonet.IPNet{*ipNet}}},
+			{Name: "lo", Type: "loopback", State: "up", MTU: 65536},
+		},
+	}
+}
+
+func TestNetState(t *testing.T) {
+	retrieveNetState = func() (net.NetState, error) { return testNetState(), nil }
+	defer func() { retrieveNetState = net.RetrieveNetState }()
+
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "table",
+			args: []string{"netstate"},
+			expected: `Hostname: master-0
+DNS servers: 192.168.111.1
+DNS search domains: 
+
+INTERFACE  TYPE      STATE  MTU    IPV4               IPV6
+eth0       ethernet  up     1500   192.168.111.80/24  -
+lo         loopback  up     65536  -                  -
+
+DESTINATION       NEXT HOP       INTERFACE
+0.0.0.0/0         192.168.111.1  eth0
+192.168.111.0/24  -              eth0
+`,
+		},
+		{
+			name: "yaml",
+			args: []string{"netstate", "--output", "yaml"},
+			expected: `hostname:
+    running: master-0
+dns-resolver:
+    running:
+        server:
+            - 192.168.111.1
+routes:
+    running:
+        - destination: 0.0.0.0/0
+          next-hop-interface: eth0
+          next-hop-address: 192.168.111.1
+        - destination: 192.168.111.0/24
+          next-hop-interface: eth0
+          next-hop-address: ""
+interfaces:
+    - name: eth0
+      type: ethernet
+      state: up
+      mtu: 1500
+      ipv4:
+        enabled: true
+        address:
+            - ip: 192.168.111.80
+              prefix-length: 24
+      ipv6: {}
+    - name: lo
+      type: loopback
+      state: up
+      mtu: 65536
+      ipv4: {}
+      ipv6: {}
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, stdout, stderr := runCommand(tc.args, nil)
+			assert.Equal(t, 0, code, stderr)
+			assert.Equal(t, tc.expected, stdout)
+		})
+	}
+}
+
+func TestRendezvous(t *testing.T) {
+	checkConnectivity = func(ip string, timeout time.Duration) ([]byte, error) {
+		return []byte("connection refused"), errors.New("exit status 7")
+	}
+	defer func() { checkConnectivity = rendezvous.CheckConnectivity }()
+
+	path := filepath.Join(t.TempDir(), "rendezvous-host.env")
+	assert.NoError(t, os.WriteFile(path+".template", []byte("NODE_ZERO_IP={{.RendezvousIP}}\n"), 0644))
+	assert.NoError(t, os.WriteFile(path, []byte("NODE_ZERO_IP={{.RendezvousIP}}\n"), 0644))
+	env := map[string]string{"AGENT_TUI_RENDEZVOUS_HOST_ENV_PATH": path}
+
+	code, _, stderr := runCommand([]string{"rendezvous", "get"}, env)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "the rendezvous IP is not configured")
+
+	code, _, stderr = runCommand([]string{"rendezvous", "set", "not-an-ip"}, env)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "not-an-ip is not a valid IP address")
+
+	code, stdout, stderr := runCommand([]string{"rendezvous", "set", "192.168.111.80"}, env)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Saved 192.168.111.80 as rendezvous IP\n", stdout)
+	assert.Contains(t, stderr, "Warning: the rendezvous IP 192.168.111.80 was not found or yet active: connection refused")
+
+	code, stdout, _ = runCommand([]string{"rendezvous", "get"}, env)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "192.168.111.80\n", stdout)
+}
+
+func TestExport(t *testing.T) {
+	retrieveNetState = func() (net.NetState, error) { return testNetState(), nil }
+	dir := t.TempDir()
+	removableMounts = func() ([]media.Mount, error) {
+		return []media.Mount{
+			{Device: "/dev/sr0", Path: "/run/media/iso", ReadOnly: true},
+			{Device: "/dev/sdb1", Path: dir},
+		}, nil
+	}
+	defer func() {
+		retrieveNetState = net.RetrieveNetState
+		removableMounts = media.RemovableMounts
+	}()
+
+	code, stdout, _ := runCommand([]string{"export", "--interfaces", "eth0"}, nil)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "networkConfig:")
+	assert.Contains(t, stdout, "next-hop-address: 192.168.111.1")
+
+	code, _, stderr := runCommand([]string{"export", "--media"}, nil)
+	assert.Equal(t, 0, code)
+	path := filepath.Join(dir, net.ExportFileName)
+	assert.Equal(t, fmt.Sprintf("Network configuration written to %s\n", path), stderr)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, stdout, string(data))
+
+	code, _, stderr = runCommand([]string{"export", "--interfaces", "eth5"}, nil)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "interface eth5 not found")
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	nmstate := `interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+  mtu: 9000
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "eth0.yaml"), []byte(nmstate), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.yml"), []byte("foo: bar\n"), 0644))
+
+	var applied []byte
+	committed, rolledBack := false, false
+	checkResults := [][]checks.CheckResult{}
+	retrieveNetState = func() (net.NetState, error) { return testNetState(), nil }
+	removableMounts = func() ([]media.Mount, error) {
+		return []media.Mount{{Device: "/dev/sdb1", Path: dir}}, nil
+	}
+	applyCheckpoint = func(state interface{}, timeout time.Duration) error {
+		applied = state.(json.RawMessage)
+		return nil
+	}
+	commitCheckpoint = func() error { committed = true; return nil }
+	rollbackCheckpoint = func() error { rolledBack = true; return nil }
+	runConnectivityChecks = func(opts *config.Options) ([]checks.CheckResult, error) {
+		results := checkResults[0]
+		checkResults = checkResults[1:]
+		return results, nil
+	}
+	defer func() {
+		retrieveNetState = net.RetrieveNetState
+		removableMounts = media.RemovableMounts
+		applyCheckpoint = net.ApplyWithCheckpoint
+		commitCheckpoint = net.CommitCheckpoint
+		rollbackCheckpoint = net.RollbackCheckpoint
+		runConnectivityChecks = engineConnectivityChecks
+	}()
+
+	code, stdout, _ := runCommand([]string{"import", "--list"}, nil)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, filepath.Join(dir, "eth0.yaml")+"\n"+filepath.Join(dir, "invalid.yml")+"\n", stdout)
+
+	code, _, stderr := runCommand([]string{"import"}, nil)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "multiple NMState files found")
+
+	code, _, stderr = runCommand([]string{"import", filepath.Join(dir, "invalid.yml")}, nil)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "unknown nmstate keys: foo")
+
+	code, stdout, _ = runCommand([]string{"import", "--dry-run", filepath.Join(dir, "eth0.yaml")}, nil)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "~ interfaces/eth0/mtu: 1500 -> 9000")
+	assert.Nil(t, applied)
+
+	passing := []checks.CheckResult{{Type: checks.CheckTypeReleaseImageHostPing, Success: true}}
+	failing := []checks.CheckResult{{Type: checks.CheckTypeReleaseImageHostPing, Success: false}}
+	env := map[string]string{"RELEASE_IMAGE": "quay.io/openshift-release-dev/ocp-release:4.15.0-x86_64"}
+
+	checkResults = [][]checks.CheckResult{passing, failing}
+	code, _, stderr = runCommand([]string{"import", filepath.Join(dir, "eth0.yaml")}, env)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "the previous network configuration has been restored")
+	assert.JSONEq(t, `{"interfaces":[{"name":"eth0","type":"ethernet","state":"up","mtu":9000}]}`, string(applied))
+	assert.True(t, rolledBack)
+	assert.False(t, committed)
+
+	checkResults = [][]checks.CheckResult{passing, passing}
+	code, stdout, _ = runCommand([]string{"import", filepath.Join(dir, "eth0.yaml")}, env)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "Network configuration applied")
+	assert.True(t, committed)
+}
+
+func TestReportTraceroute(t *testing.T) {
+	retrieveNetState = func() (net.NetState, error) { return testNetState(), nil }
+	defer func() { retrieveNetState = net.RetrieveNetState }()
+	traceroute = func(host string, opts net.TracerouteOptions, hop func(net.Hop), stop <-chan struct{}) (gonet.IP, error) {
+		if host == "quay.io" {
+			return nil, errors.New("no such host")
+		}
+		hop(net.Hop{TTL: 1, Addr: "192.168.111.1", RTTs: []time.Duration{time.Millisecond}})
+		hop(net.Hop{TTL: 2, Addr: host, RTTs: []time.Duration{2 * time.Millisecond}, Reached: true})
+		return gonet.ParseIP(host), nil
+	}
+	defer func() { traceroute = checks.Traceroute }()
+
+	env := map[string]string{
+		"RELEASE_IMAGE":           "quay.io/openshift-release-dev/ocp-release:4.18.0-x86_64",
+		"AGENT_TUI_RENDEZVOUS_IP": "192.168.111.80",
+	}
+	code, stdout, _ := runCommand([]string{"report", "--skip-checks", "--traceroute"}, env)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, `== Traceroute ==
+traceroute to quay.io (release image)
+Error: no such host
+traceroute to 192.168.111.80 (rendezvous IP)
+ 1  192.168.111.1  1.000 ms
+ 2  192.168.111.80  2.000 ms
+`)
+
+	code, stdout, _ = runCommand([]string{"report", "--skip-checks", "--traceroute", "--output", "json"}, env)
+	assert.Equal(t, 0, code)
+	r := report{}
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &r))
+	assert.Equal(t, []tracerouteResult{
+		{Target: "quay.io", Name: "release image", Error: "no such host"},
+		{Target: "192.168.111.80", Name: "rendezvous IP", IP: "192.168.111.80",
+			Hops: []string{" 1  192.168.111.1  1.000 ms", " 2  192.168.111.80  2.000 ms"}},
+	}, r.Traceroutes)
+}
+
+func TestReportLLDP(t *testing.T) {
+	retrieveNetState = func() (net.NetState, error) { return testNetState(), nil }
+	defer func() { retrieveNetState = net.RetrieveNetState }()
+	physicalNICs = func(ns net.NetState) []net.NIC {
+		return []net.NIC{{Name: "eth0"}, {Name: "eth1"}}
+	}
+	defer func() { physicalNICs = net.PhysicalNICs }()
+	listenLLDP = func(ifaces []string, window time.Duration, neighbor func(net.LLDPNeighbor), stop <-chan struct{}) error {
+		neighbor(net.LLDPNeighbor{Iface: "eth0", ChassisID: "2c:6b:f5:11:22:00", PortID: "ge-0/0/12", SystemName: "sw-rack3"})
+		// the VLANs are advertised later
+		neighbor(net.LLDPNeighbor{Iface: "eth0", ChassisID: "2c:6b:f5:11:22:00", PortID: "ge-0/0/12", SystemName: "sw-rack3",
+			PortVLANID: 100, VLANs: []net.LLDPVLAN{{ID: 200, Name: "baremetal"}}, MTU: 9216})
+		return errors.New("cannot listen for LLDP on eth1: operation not permitted")
+	}
+	defer func() { listenLLDP = net.ListenLLDP }()
+
+	code, stdout, _ := runCommand([]string{"report", "--skip-checks", "--lldp"}, nil)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, `== LLDP neighbors ==
+INTERFACE  SWITCH    CHASSIS ID         PORT       VLANS                            MTU
+eth0       sw-rack3  2c:6b:f5:11:22:00  ge-0/0/12  100 (untagged), 200 (baremetal)  9216
+eth1       -         -                  -          -                                -
+Error: cannot listen for LLDP on eth1: operation not permitted
+`)
+
+	code, stdout, _ = runCommand([]string{"report", "--skip-checks", "--lldp", "--output", "json"}, nil)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, `"lldpNeighbors": [
+    {
+      "iface": "eth0",
+      "chassisID": "2c:6b:f5:11:22:00",
+      "portID": "ge-0/0/12",
+      "systemName": "sw-rack3",
+      "portVLANID": 100,
+      "vlans": [
+        {
+          "id": 200,
+          "name": "baremetal"
+        }
+      ],
+      "mtu": 9216
+    }
+  ]`)
+
+	code, stdout, _ = runCommand([]string{"report", "--skip-checks"}, nil)
+	assert.Equal(t, 0, code)
+	assert.NotContains(t, stdout, "LLDP")
+}
+
+func TestRun(t *testing.T) {
+	code, _, stderr := runCommand([]string{"unknown"}, nil)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `Unknown command "unknown"`)
+
+	code, stdout, _ := runCommand([]string{"help"}, nil)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "rendezvous   Get or set the rendezvous IP")
+
+	code, _, stderr = runCommand([]string{"check", "--output", "json"}, nil)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "the release image must be specified")
+}
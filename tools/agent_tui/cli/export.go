@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/media"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+// removableMounts can be replaced by the tests
+var removableMounts = media.RemovableMounts
+
+type exportCommand struct {
+	interfaces string
+	file       string
+	toMedia    bool
+}
+
+func newExportCommand() *command {
+	c := &exportCommand{}
+	return &command{
+		name:        "export",
+		synopsis:    "[flags]",
+		description: "Export the network configuration as agent-config.yaml host settings",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&c.interfaces, "interfaces", "", "comma separated list of the interfaces to export (default: the interfaces up with IP enabled)")
+			fs.StringVar(&c.file, "file", "", "write the settings to the file instead of the standard output")
+			fs.BoolVar(&c.toMedia, "media", false, fmt.Sprintf("write the settings to %s on the first writable removable media", net.ExportFileName))
+		},
+		run: c.run,
+	}
+}
+
+func (c *exportCommand) run(env Env, opts *config.Options, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+	if c.file != "" && c.toMedia {
+		return fmt.Errorf("--file and --media can't be used together")
+	}
+
+	netState, err := retrieveNetState()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the network state: %w", err)
+	}
+	names := []string{}
+	for _, name := range strings.Split(c.interfaces, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	host, err := net.Export(netState, names)
+	if err != nil {
+		return err
+	}
+	data, err := host.YAML()
+	if err != nil {
+		return err
+	}
+
+	path := c.file
+	if c.toMedia {
+		path, err = mediaExportPath()
+		if err != nil {
+			return err
+		}
+	}
+	if path == "" {
+		_, err = env.Stdout.Write(data)
+		return err
+	}
+	if err := media.WriteFile(path, data); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stderr, "Network configuration written to %s\n", path)
+	return nil
+}
+
+func mediaExportPath() (string, error) {
+	mounts, err := removableMounts()
+	if err != nil {
+		return "", fmt.Errorf("failed to list the removable media: %w", err)
+	}
+	for _, mount := range mounts {
+		if !mount.ReadOnly {
+			return filepath.Join(mount.Path, net.ExportFileName), nil
+		}
+	}
+	return "", fmt.Errorf("no writable removable media found")
+}
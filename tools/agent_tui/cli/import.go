@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/media"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+// The nmstate functions can be replaced by the tests
+var (
+	applyCheckpoint    = net.ApplyWithCheckpoint
+	commitCheckpoint   = net.CommitCheckpoint
+	rollbackCheckpoint = net.RollbackCheckpoint
+)
+
+// Time available to the connectivity checks, bounded by
+// checks.ConnectivityChecksTimeout, before the checkpoint
+// is rolled back by nmstate
+const importCheckpointTimeout = 2 * time.Minute
+
+type importCommand struct {
+	list   bool
+	dryRun bool
+}
+
+func newImportCommand() *command {
+	c := &importCommand{}
+	return &command{
+		name:        "import",
+		synopsis:    "[flags] [file or directory]",
+		description: "Validate and apply a NMState file, from the removable media by default",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&c.list, "list", false, "only list the candidate files")
+			fs.BoolVar(&c.dryRun, "dry-run", false, "only validate the file and print the changes")
+		},
+		run: c.run,
+	}
+}
+
+func (c *importCommand) run(env Env, opts *config.Options, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+	}
+
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	}
+	candidates, err := importCandidates(path)
+	if err != nil {
+		return err
+	}
+	if c.list {
+		for _, candidate := range candidates {
+			fmt.Fprintln(env.Stdout, candidate)
+		}
+		return nil
+	}
+	switch len(candidates) {
+	case 0:
+		return fmt.Errorf("no NMState file found")
+	case 1:
+	default:
+		return fmt.Errorf("multiple NMState files found, specify one of them:\n%s", strings.Join(candidates, "\n"))
+	}
+
+	data, err := os.ReadFile(candidates[0])
+	if err != nil {
+		return err
+	}
+	imported, err := net.ParseNMState(data)
+	if err != nil {
+		return fmt.Errorf("invalid NMState file %s: %w", candidates[0], err)
+	}
+	current, err := retrieveNetState()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the network state: %w", err)
+	}
+	writeChanges(env.Stdout, net.Diff(current, imported.Predict(current)))
+	if c.dryRun {
+		return nil
+	}
+
+	return applyImported(env, opts, imported)
+}
+
+// importCandidates returns the path if it's a file, or the NMState
+// files found in the directory or on the removable media
+func importCandidates(path string) ([]string, error) {
+	if path == "" {
+		mounts, err := removableMounts()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the removable media: %w", err)
+		}
+		return media.FindOnMounts(mounts, net.ImportFileExtensions, net.ImportSearchDepth), nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	return media.FindFiles(path, net.ImportFileExtensions, net.ImportSearchDepth)
+}
+
+func writeChanges(w io.Writer, changes []net.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes to the network state")
+		return
+	}
+	fmt.Fprintln(w, "Changes to the network state:")
+	for _, change := range changes {
+		fmt.Fprintf(w, "  %s\n", change)
+	}
+}
+
+// applyImported applies the document in a checkpoint, which is rolled
+// back if any of the connectivity checks passing before fails
+// afterwards. The checks are skipped when the release image is not
+// configured.
+func applyImported(env Env, opts *config.Options, imported net.ImportedState) error {
+	var before []checks.CheckResult
+	runChecks := opts.ReleaseImage != ""
+	if runChecks {
+		var err error
+		if before, err = runConnectivityChecks(opts); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(env.Stderr, "Warning: the release image is not configured, the connectivity will not be verified")
+	}
+
+	if err := applyCheckpoint(imported.JSON, importCheckpointTimeout); err != nil {
+		return fmt.Errorf("failed to apply the network configuration: %w", err)
+	}
+
+	if runChecks {
+		after, err := runConnectivityChecks(opts)
+		if err == nil {
+			if regressions := checks.Regressions(before, after); len(regressions) > 0 {
+				err = fmt.Errorf("checks failing after the change: %s", strings.Join(regressions, ", "))
+			}
+		}
+		if err != nil {
+			if rollbackErr := rollbackCheckpoint(); rollbackErr != nil {
+				return fmt.Errorf("%v, and failed to restore the previous network configuration: %w", err, rollbackErr)
+			}
+			return fmt.Errorf("%v, the previous network configuration has been restored", err)
+		}
+	}
+
+	if err := commitCheckpoint(); err != nil {
+		return fmt.Errorf("failed to save the network configuration: %w", err)
+	}
+	fmt.Fprintln(env.Stdout, "Network configuration applied")
+	return nil
+}
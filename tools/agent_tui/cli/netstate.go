@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+// retrieveNetState can be replaced by the tests
+var retrieveNetState = net.RetrieveNetState
+
+type netStateCommand struct {
+	output string
+}
+
+func newNetStateCommand() *command {
+	c := &netStateCommand{}
+	return &command{
+		name:        "netstate",
+		synopsis:    "[flags]",
+		description: "Print the current network state",
+		flags: func(fs *flag.FlagSet) {
+			outputFlag(fs, &c.output, outputTable)
+		},
+		run: c.run,
+	}
+}
+
+func (c *netStateCommand) run(env Env, opts *config.Options, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+
+	netState, err := retrieveNetState()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the network state: %w", err)
+	}
+
+	if c.output == outputTable || c.output == outputText {
+		writeNetStateTable(env.Stdout, netState)
+		return nil
+	}
+	return writeStructured(env.Stdout, c.output, netState)
+}
+
+func writeNetStateTable(w io.Writer, ns net.NetState) {
+	fmt.Fprintf(w, "Hostname: %s\n", ns.Hostname.Running)
+	fmt.Fprintf(w, "DNS servers: %s\n", strings.Join(ns.DNS.Running.Servers, ", "))
+	fmt.Fprintf(w, "DNS search domains: %s\n\n", strings.Join(ns.DNS.Running.SearchDomains, ", "))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "INTERFACE\tTYPE\tSTATE\tMTU\tIPV4\tIPV6")
+	for _, iface := range ns.Ifaces {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			iface.Name, iface.Type, iface.State, iface.MTU, formatAddresses(iface.IPv4), formatAddresses(iface.IPv6))
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DESTINATION\tNEXT HOP\tINTERFACE")
+	for _, route := range ns.Routes.Running {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", route.Destination, valueOrDash(route.NextHopAddr), route.NextHopIface)
+	}
+	tw.Flush()
+}
+
+func formatAddresses(ipc net.IPConfig) string {
+	if !ipc.Enabled || len(ipc.Addresses) == 0 {
+		return "-"
+	}
+	addresses := []string{}
+	for _, address := range ipc.Addresses {
+		addresses = append(addresses, address.String())
+	}
+	return strings.Join(addresses, ",")
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+// outputFlag registers the --output flag, accepting text (or table)
+// and the specified structured formats
+func outputFlag(fs *flag.FlagSet, p *string, text string) {
+	fs.StringVar(p, "output", text, fmt.Sprintf("output format: %s, %s, %s", text, outputJSON, outputYAML))
+}
+
+// writeStructured writes v as JSON or YAML. The YAML document is
+// converted from the JSON one, so that the same field names are used.
+func writeStructured(w io.Writer, format string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case outputJSON:
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case outputYAML:
+		data, err = net.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
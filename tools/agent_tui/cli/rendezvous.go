@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
+)
+
+// checkConnectivity can be replaced by the tests
+var checkConnectivity = rendezvous.CheckConnectivity
+
+func newRendezvousCommand() *command {
+	return &command{
+		name:        "rendezvous",
+		synopsis:    "[flags] get | set <ip>",
+		description: "Get or set the rendezvous IP",
+		run:         runRendezvous,
+	}
+}
+
+func runRendezvous(env Env, opts *config.Options, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing action, must be one of get, set <ip>")
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) != 1 {
+			return fmt.Errorf("get does not accept arguments")
+		}
+		ip := rendezvous.Read(opts.RendezvousHostEnvPath)
+		if ip == "" {
+			return fmt.Errorf("the rendezvous IP is not configured in %s", opts.RendezvousHostEnvPath)
+		}
+		fmt.Fprintln(env.Stdout, ip)
+		return nil
+
+	case "set":
+		if len(args) != 2 {
+			return fmt.Errorf("set requires the rendezvous IP as argument")
+		}
+		ip := args[1]
+		if msg := rendezvous.ValidateIP(ip); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		// Like in the TUI, a failed connectivity check does not prevent
+		// saving the IP, since the rendezvous node may not be up yet
+		if output, err := checkConnectivity(ip, opts.ConnectivityTimeout); err != nil {
+			fmt.Fprintf(env.Stderr, "Warning: the rendezvous IP %s was not found or yet active: %s\n", ip, output)
+		}
+		if err := rendezvous.Save(opts.RendezvousHostEnvPath, ip); err != nil {
+			return fmt.Errorf("failed to save the rendezvous IP: %w", err)
+		}
+		fmt.Fprintf(env.Stdout, "Saved %s as rendezvous IP\n", ip)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown action %q, must be one of get, set <ip>", args[0])
+	}
+}
@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/pkg/version"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+)
+
+type reportCommand struct {
+	output     string
+	skipChecks bool
+	traceroute bool
+	lldp       bool
+}
+
+// can be replaced by the tests
+var (
+	traceroute   = checks.Traceroute
+	physicalNICs = net.PhysicalNICs
+	listenLLDP   = net.ListenLLDP
+)
+
+func newReportCommand() *command {
+	c := &reportCommand{}
+	return &command{
+		name:        "report",
+		synopsis:    "[flags]",
+		description: "Print a diagnostic report with configuration, checks and network state",
+		flags: func(fs *flag.FlagSet) {
+			outputFlag(fs, &c.output, outputText)
+			fs.BoolVar(&c.skipChecks, "skip-checks", false, "do not run the connectivity checks")
+			fs.BoolVar(&c.traceroute, "traceroute", false, "trace the path to the release image host and the rendezvous IP")
+			fs.BoolVar(&c.lldp, "lldp", false, fmt.Sprintf("listen %s for the LLDP advertisements of the switches", net.DefaultLLDPWindow))
+		},
+		run: c.run,
+	}
+}
+
+// report collects everything useful to troubleshoot an installation.
+// Failures in collecting a section are reported instead of aborting.
+type report struct {
+	Time          time.Time          `json:"time"`
+	Version       string             `json:"version"`
+	Commit        string             `json:"commit"`
+	ConfigPath    string             `json:"configPath,omitempty"`
+	Settings      []config.Setting   `json:"settings"`
+	Interactive   bool               `json:"interactive"`
+	RendezvousIP  string             `json:"rendezvousIP"`
+	Checks        []checkResult      `json:"checks,omitempty"`
+	ChecksError   string             `json:"checksError,omitempty"`
+	NetState      *net.NetState      `json:"netState,omitempty"`
+	NetStateError string             `json:"netStateError,omitempty"`
+	Traceroutes   []tracerouteResult `json:"traceroutes,omitempty"`
+	LLDPIfaces    []string           `json:"lldpIfaces,omitempty"`
+	LLDPNeighbors []net.LLDPNeighbor `json:"lldpNeighbors,omitempty"`
+	LLDPError     string             `json:"lldpError,omitempty"`
+}
+
+type tracerouteResult struct {
+	Target string   `json:"target"`
+	Name   string   `json:"name"`
+	IP     string   `json:"ip,omitempty"`
+	Hops   []string `json:"hops,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func (c *reportCommand) run(env Env, opts *config.Options, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+
+	r := report{
+		Time:         time.Now(),
+		Version:      version.Raw,
+		Commit:       version.Commit,
+		ConfigPath:   opts.ConfigPath,
+		Settings:     opts.Settings(),
+		Interactive:  opts.IsInteractive(),
+		RendezvousIP: opts.ResolveRendezvousIP(),
+	}
+
+	if !c.skipChecks {
+		results, err := runChecks(opts)
+		if err != nil {
+			r.ChecksError = err.Error()
+		}
+		r.Checks = results
+	}
+
+	netState, err := retrieveNetState()
+	if err != nil {
+		r.NetStateError = err.Error()
+	} else {
+		r.NetState = &netState
+	}
+
+	if c.traceroute {
+		r.Traceroutes = runTraceroutes(opts, r.RendezvousIP)
+	}
+
+	if c.lldp && r.NetState != nil {
+		r.LLDPIfaces, r.LLDPNeighbors, err = collectLLDP(*r.NetState)
+		if err != nil {
+			r.LLDPError = err.Error()
+		}
+	}
+
+	if c.output == outputText {
+		writeReport(env.Stdout, r, c.skipChecks)
+		return nil
+	}
+	return writeStructured(env.Stdout, c.output, r)
+}
+
+func writeReport(w io.Writer, r report, skipChecks bool) {
+	fmt.Fprintf(w, "agent-tui report, %s\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(w, "Version: %s (commit %s)\n", r.Version, r.Commit)
+	fmt.Fprintf(w, "Interactive: %v\n", r.Interactive)
+	fmt.Fprintf(w, "Rendezvous IP: %s\n", valueOrDash(r.RendezvousIP))
+
+	fmt.Fprintf(w, "\n== Settings ==\n")
+	if r.ConfigPath != "" {
+		fmt.Fprintf(w, "Config file: %s\n", r.ConfigPath)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPTION\tVALUE\tSOURCE")
+	for _, s := range r.Settings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, valueOrDash(s.Value), s.Source)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n== Checks ==\n")
+	switch {
+	case skipChecks:
+		fmt.Fprintln(w, "Skipped")
+	case r.ChecksError != "":
+		fmt.Fprintf(w, "Error: %s\n", r.ChecksError)
+	default:
+		writeCheckResults(w, r.Checks, false)
+	}
+
+	fmt.Fprintf(w, "\n== Network state ==\n")
+	if r.NetState == nil {
+		fmt.Fprintf(w, "Error: %s\n", r.NetStateError)
+	} else {
+		writeNetStateTable(w, *r.NetState)
+	}
+
+	if len(r.Traceroutes) > 0 {
+		fmt.Fprintf(w, "\n== Traceroute ==\n")
+	}
+	for _, t := range r.Traceroutes {
+		fmt.Fprintf(w, "traceroute to %s (%s)", t.Target, t.Name)
+		if t.IP != "" && t.IP != t.Target {
+			fmt.Fprintf(w, ", %s", t.IP)
+		}
+		fmt.Fprintln(w)
+		for _, hop := range t.Hops {
+			fmt.Fprintln(w, hop)
+		}
+		if t.Error != "" {
+			fmt.Fprintf(w, "Error: %s\n", t.Error)
+		}
+	}
+
+	if r.LLDPIfaces != nil {
+		fmt.Fprintf(w, "\n== LLDP neighbors ==\n")
+		if len(r.LLDPIfaces) == 0 {
+			fmt.Fprintln(w, "No physical interface")
+		} else {
+			writeLLDPNeighbors(w, r.LLDPIfaces, r.LLDPNeighbors)
+		}
+		if r.LLDPError != "" {
+			fmt.Fprintf(w, "Error: %s\n", r.LLDPError)
+		}
+	}
+}
+
+// writeLLDPNeighbors lists the switch ports the interfaces are
+// connected to, with a dash for the interfaces without neighbor
+func writeLLDPNeighbors(w io.Writer, ifaces []string, neighbors []net.LLDPNeighbor) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "INTERFACE\tSWITCH\tCHASSIS ID\tPORT\tVLANS\tMTU")
+	for _, iface := range ifaces {
+		found := false
+		for _, n := range neighbors {
+			if n.Iface != iface {
+				continue
+			}
+			found = true
+			mtu := ""
+			if n.MTU != 0 {
+				mtu = strconv.Itoa(n.MTU)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", iface, valueOrDash(n.SystemName), n.ChassisID,
+				n.Port(), valueOrDash(n.VLANList()), valueOrDash(mtu))
+		}
+		if !found {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\t-\n", iface)
+		}
+	}
+	tw.Flush()
+}
+
+// collectLLDP listens for the LLDP advertisements received by the
+// physical interfaces, returning the interfaces listened to and the
+// neighbors found
+func collectLLDP(netState net.NetState) ([]string, []net.LLDPNeighbor, error) {
+	ifaces := []string{}
+	for _, nic := range physicalNICs(netState) {
+		ifaces = append(ifaces, nic.Name)
+	}
+	neighbors := []net.LLDPNeighbor{}
+	err := listenLLDP(ifaces, net.DefaultLLDPWindow, func(neighbor net.LLDPNeighbor) {
+		// a changed neighbor replaces the previous one
+		i := slices.IndexFunc(neighbors, func(n net.LLDPNeighbor) bool {
+			return n.Iface == neighbor.Iface && n.ChassisID == neighbor.ChassisID && n.PortID == neighbor.PortID
+		})
+		if i < 0 {
+			neighbors = append(neighbors, neighbor)
+		} else {
+			neighbors[i] = neighbor
+		}
+	}, nil)
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return slices.Index(ifaces, neighbors[i].Iface) < slices.Index(ifaces, neighbors[j].Iface)
+	})
+	return ifaces, neighbors, err
+}
+
+// runTraceroutes traces the path to the release image host and the
+// rendezvous IP, when they are known
+func runTraceroutes(opts *config.Options, rendezvousIP string) []tracerouteResult {
+	targets := []checks.TracerouteTarget{}
+	checksConfig := opts.ChecksConfig()
+	if opts.ReleaseImage != "" && checks.PrepareConfig(&checksConfig) == nil {
+		targets = append(targets, checks.TracerouteTarget{Name: "release image", Host: checksConfig.ReleaseImageHostname})
+	}
+	if rendezvousIP != "" {
+		targets = append(targets, checks.TracerouteTarget{Name: "rendezvous IP", Host: rendezvousIP})
+	}
+
+	results := []tracerouteResult{}
+	for _, target := range targets {
+		result := tracerouteResult{Target: target.Host, Name: target.Name}
+		ip, err := traceroute(target.Host, net.DefaultTracerouteOptions, func(hop net.Hop) {
+			result.Hops = append(result.Hops, hop.String())
+		}, nil)
+		if ip != nil {
+			result.IP = ip.String()
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// kernelCmdlinePrefix is the prefix of the kernel parameters read by
+	// agent-tui, followed by the flag name, e.g. agent-tui.mode=interactive
+	kernelCmdlinePrefix = "agent-tui."
+)
+
+// kernelCmdlinePath can be replaced by the tests
+var kernelCmdlinePath = "/proc/cmdline"
+
+// parseKernelCmdline splits the kernel command line into its parameters.
+// Values may be enclosed in double quotes to include spaces, and
+// parameters without a value are returned with an empty one.
+func parseKernelCmdline(cmdline string) map[string]string {
+	params := map[string]string{}
+
+	var current strings.Builder
+	inQuotes := false
+	flush := func() {
+		param := current.String()
+		current.Reset()
+		if param == "" {
+			return
+		}
+		key, value, _ := strings.Cut(param, "=")
+		params[key] = value
+	}
+	for _, r := range cmdline {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return params
+}
+
+// loadKernelCmdline reads the agent-tui options from the kernel command
+// line. Since they can't be easily fixed on a booted host, unknown or
+// invalid parameters are reported as warnings instead of errors.
+func (o *Options) loadKernelCmdline(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the kernel command line: %w", err)
+	}
+
+	options := map[string]option{}
+	for _, opt := range o.options() {
+		options[opt.name] = opt
+	}
+	params := parseKernelCmdline(string(data))
+	keys := []string{}
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := params[key]
+		name, found := strings.CutPrefix(key, kernelCmdlinePrefix)
+		if !found {
+			continue
+		}
+		opt, found := options[name]
+		if !found {
+			o.Warnings = append(o.Warnings, fmt.Sprintf("ignoring unknown kernel parameter %s", key))
+			continue
+		}
+		// boolean parameters can be specified without a value
+		if _, isBool := opt.value.(boolValue); isBool && value == "" {
+			value = "true"
+		}
+		previous, previousSource := opt.value.String(), o.Sources[name]
+		if err := o.set(opt, value, SourceKernelCmdline); err != nil {
+			o.Warnings = append(o.Warnings, fmt.Sprintf("ignoring kernel parameter %s: %v", key, err))
+			continue
+		}
+		if err := o.validateOption(name); err != nil {
+			// the previous value was already accepted, restoring it can't fail
+			_ = opt.value.Set(previous)
+			o.Sources[name] = previousSource
+			o.Warnings = append(o.Warnings, fmt.Sprintf("ignoring kernel parameter %s: %v", key, err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,413 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/logging"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ModeAuto           = "auto"
+	ModeInteractive    = "interactive"
+	ModeNonInteractive = "non-interactive"
+
+	DefaultConfigPath                = "/etc/assisted/agent-tui.yaml"
+	DefaultLogPath                   = "/tmp/agent_tui.log"
+	DefaultInteractiveUISentinelPath = "/etc/assisted/interactive-ui"
+	DefaultRendezvousHostEnvPath     = rendezvous.HostEnvPath
+
+	configPathEnv = "AGENT_TUI_CONFIG"
+)
+
+// Source describes where an option value comes from
+type Source string
+
+const (
+	SourceDefault           Source = "default"
+	SourceFile              Source = "config file"
+	SourceKernelCmdline     Source = "kernel command line"
+	SourceEnv               Source = "environment"
+	SourceFlag              Source = "command line"
+	SourceRendezvousHostEnv Source = "rendezvous host env file"
+)
+
+// Options contains the agent-tui configuration. Each option can be set,
+// in order of precedence, by a command line flag, an environment variable,
+// a kernel command line parameter prefixed by "agent-tui." or a YAML config
+// file, using the flag name as the key.
+type Options struct {
+	ReleaseImage string
+
+	LogPath            string
+	LogLevel           string
+	LogFormat          string
+	LogMaxSizeMB       int
+	LogMaxBackups      int
+	LogSummaryInterval time.Duration
+	JournalUnits       []string
+
+	InteractiveUISentinelPath string
+	RendezvousHostEnvPath     string
+	RegistryEnvPath           string
+
+	PromptTimeout       time.Duration
+	ConnectivityTimeout time.Duration
+	CheckFrequency      time.Duration
+
+	Mode string
+	// Skip disables agent-tui, which exits right away
+	Skip bool
+	// AutoContinue skips the prompts when no user input is required
+	AutoContinue bool
+	// RendezvousIP overrides the one stored in the rendezvous host env file
+	RendezvousIP string
+	// CheckEndpoints are additional URLs checked with an http GET
+	CheckEndpoints []string
+	// NetStateProvider selects how the network state is retrieved
+	NetStateProvider string
+	// NetStateFixture is the file read by the fixture provider
+	NetStateFixture string
+
+	// ConfigPath is the YAML config file that was loaded, if any
+	ConfigPath string
+	// ShowVersion is set when the version was requested
+	ShowVersion bool
+	// Sources records where the value of each option comes from
+	Sources map[string]Source
+	// Warnings are the non fatal issues found while loading the options
+	Warnings []string
+}
+
+// option binds a flag name, and its environment variable, to a field
+type option struct {
+	name  string
+	env   string
+	usage string
+	value flag.Value
+}
+
+func (o *Options) options() []option {
+	return []option{
+		{"release-image", "RELEASE_IMAGE", "URL of the OpenShift release image", stringValue{&o.ReleaseImage}},
+		{"log-path", "AGENT_TUI_LOG_PATH", "log file path", stringValue{&o.LogPath}},
+		{"log-level", "AGENT_TUI_LOG_LEVEL", "log level: debug, info, warning, error", stringValue{&o.LogLevel}},
+		{"log-format", "AGENT_TUI_LOG_FORMAT", "log format: text, json", stringValue{&o.LogFormat}},
+		{"log-max-size-mb", "AGENT_TUI_LOG_MAX_SIZE_MB", "size in MB after which the log is rotated, 0 to disable", intValue{&o.LogMaxSizeMB}},
+		{"log-max-backups", "AGENT_TUI_LOG_MAX_BACKUPS", "number of rotated log files kept", intValue{&o.LogMaxBackups}},
+		{"log-summary-interval", "AGENT_TUI_LOG_SUMMARY_INTERVAL", "how often a summary of the checks is logged", durationValue{&o.LogSummaryInterval}},
+		{"journal-units", "AGENT_TUI_JOURNAL_UNITS", "comma separated list of units displayed in the log viewer", listValue{&o.JournalUnits}},
+		{"interactive-ui-path", "AGENT_TUI_INTERACTIVE_UI_PATH", "sentinel file enabling the interactive mode", stringValue{&o.InteractiveUISentinelPath}},
+		{"rendezvous-host-env-path", "AGENT_TUI_RENDEZVOUS_HOST_ENV_PATH", "file where the rendezvous IP is stored", stringValue{&o.RendezvousHostEnvPath}},
+		{"registry-env-path", "AGENT_TUI_REGISTRY_ENV_PATH", "file whose presence indicates a local registry, skipping the release image checks", stringValue{&o.RegistryEnvPath}},
+		{"prompt-timeout", "AGENT_TUI_PROMPT_TIMEOUT", "how long the prompts wait before continuing", durationValue{&o.PromptTimeout}},
+		{"connectivity-timeout", "AGENT_TUI_CONNECTIVITY_TIMEOUT", "timeout of the rendezvous IP connectivity check", durationValue{&o.ConnectivityTimeout}},
+		{"check-frequency", "AGENT_TUI_CHECK_FREQUENCY", "how often the checks are run", durationValue{&o.CheckFrequency}},
+		{"mode", "AGENT_TUI_MODE", "UI mode: auto (interactive if the sentinel file exists), interactive, non-interactive", stringValue{&o.Mode}},
+		{"skip", "AGENT_TUI_SKIP", "exit right away without displaying the UI", boolValue{&o.Skip}},
+		{"auto-continue", "AGENT_TUI_AUTO_CONTINUE", "continue without prompting the user when no action is required", boolValue{&o.AutoContinue}},
+		{"rendezvous-ip", "AGENT_TUI_RENDEZVOUS_IP", "rendezvous IP, overriding the one in the rendezvous host env file", stringValue{&o.RendezvousIP}},
+		{"check-endpoints", "AGENT_TUI_CHECK_ENDPOINTS", "comma separated list of additional URLs checked with an http GET", listValue{&o.CheckEndpoints}},
+		{"netstate-provider", "AGENT_TUI_NETSTATE_PROVIDER", "how the network state is retrieved: " + strings.Join(net.Providers, ", "), stringValue{&o.NetStateProvider}},
+		{"netstate-fixture", "AGENT_TUI_NETSTATE_FIXTURE", "nmstate YAML or JSON file read by the fixture provider", stringValue{&o.NetStateFixture}},
+	}
+}
+
+// Defaults returns the options used when nothing else is configured
+func Defaults() *Options {
+	o := &Options{
+		LogPath:                   DefaultLogPath,
+		LogLevel:                  logging.DefaultLevel,
+		LogFormat:                 logging.FormatText,
+		LogMaxSizeMB:              logging.DefaultMaxSizeMB,
+		LogMaxBackups:             logging.DefaultMaxBackups,
+		LogSummaryInterval:        checks.DefaultLogSummaryInterval,
+		InteractiveUISentinelPath: DefaultInteractiveUISentinelPath,
+		RendezvousHostEnvPath:     DefaultRendezvousHostEnvPath,
+		RegistryEnvPath:           checks.DefaultRegistryEnvPath,
+		PromptTimeout:             checks.DefaultPromptTimeout,
+		ConnectivityTimeout:       checks.DefaultConnectivityTimeout,
+		CheckFrequency:            checks.DefaultCheckFrequency,
+		Mode:                      ModeAuto,
+		NetStateProvider:          net.ProviderAuto,
+		Sources:                   map[string]Source{},
+	}
+	for _, opt := range o.options() {
+		o.Sources[opt.name] = SourceDefault
+	}
+	return o
+}
+
+// Load parses the command line arguments and merges them with the
+// environment variables and the config file, on top of the defaults.
+// flag.ErrHelp is returned if the usage was requested.
+func Load(name string, args []string, getenv func(string) string, output io.Writer) (*Options, error) {
+	o, remaining, err := LoadWithFlags(name, "[flags]", args, getenv, output, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(remaining) > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(remaining, " "))
+	}
+	return o, nil
+}
+
+// LoadWithFlags works like Load, but allows registering additional flags
+// and returns the positional arguments. The synopsis is displayed in the
+// usage after the command name.
+func LoadWithFlags(name string, synopsis string, args []string, getenv func(string) string, output io.Writer, register func(fs *flag.FlagSet)) (*Options, []string, error) {
+	o := Defaults()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(output, "Usage: %s %s\n\n", name, synopsis)
+		fmt.Fprintf(output, "Each flag can also be set with the environment variable shown in brackets, with\n")
+		fmt.Fprintf(output, "the %s<flag> kernel parameter, or in the YAML config file using the flag name\n", kernelCmdlinePrefix)
+		fmt.Fprintf(output, "as the key.\n\n")
+		fs.PrintDefaults()
+	}
+
+	flagValues := map[string]*rawValue{}
+	for _, opt := range o.options() {
+		_, isBool := opt.value.(boolValue)
+		v := &rawValue{isBool: isBool}
+		flagValues[opt.name] = v
+		fs.Var(v, opt.name, fmt.Sprintf("%s [%s] (default %q)", opt.usage, opt.env, opt.value.String()))
+	}
+	configPath := fs.String("config", "", fmt.Sprintf("YAML config file [%s] (default %q, if present)", configPathEnv, DefaultConfigPath))
+	fs.BoolVar(&o.ShowVersion, "version", false, "print the version and exit")
+	if register != nil {
+		register(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	// config file
+	path, required := *configPath, true
+	if path == "" {
+		path = getenv(configPathEnv)
+	}
+	if path == "" {
+		path, required = DefaultConfigPath, false
+	}
+	if err := o.loadFile(path, required); err != nil {
+		return nil, nil, err
+	}
+
+	// kernel command line
+	if err := o.loadKernelCmdline(kernelCmdlinePath); err != nil {
+		return nil, nil, err
+	}
+
+	// environment variables
+	for _, opt := range o.options() {
+		if value := getenv(opt.env); value != "" {
+			if err := o.set(opt, value, SourceEnv); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// command line flags
+	for _, opt := range o.options() {
+		if v := flagValues[opt.name]; v.set {
+			if err := o.set(opt, v.value, SourceFlag); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if err := o.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return o, fs.Args(), nil
+}
+
+func (o *Options) set(opt option, value string, source Source) error {
+	if err := opt.value.Set(value); err != nil {
+		return fmt.Errorf("invalid value %q for %s from %s: %w", value, opt.name, source, err)
+	}
+	o.Sources[opt.name] = source
+	return nil
+}
+
+// loadFile reads the options from a YAML file. A missing file is
+// an error only when it was explicitly requested.
+func (o *Options) loadFile(path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	options := map[string]option{}
+	for _, opt := range o.options() {
+		options[opt.name] = opt
+	}
+	for key, value := range values {
+		opt, found := options[key]
+		if !found {
+			return fmt.Errorf("unknown option %q in config file %s", key, path)
+		}
+		if err := o.set(opt, yamlValueToString(value), SourceFile); err != nil {
+			return err
+		}
+	}
+	o.ConfigPath = path
+	return nil
+}
+
+func yamlValueToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Setting is the effective value of an option, and where it comes from
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+}
+
+// Settings returns the value of every option, in the same order
+// they are listed in the usage
+func (o *Options) Settings() []Setting {
+	settings := []Setting{}
+	for _, opt := range o.options() {
+		settings = append(settings, Setting{
+			Name:   opt.name,
+			Value:  opt.value.String(),
+			Source: o.Sources[opt.name],
+		})
+	}
+	return settings
+}
+
+// Validate checks the options that can't be verified while parsing
+func (o *Options) Validate() error {
+	for _, opt := range o.options() {
+		if err := o.validateOption(opt.name); err != nil {
+			return err
+		}
+	}
+	if _, err := o.Provider(); err != nil {
+		return fmt.Errorf("invalid netstate-provider: %w", err)
+	}
+	return nil
+}
+
+// validateOption checks the value of an option that doesn't depend on
+// the other ones
+func (o *Options) validateOption(name string) error {
+	switch name {
+	case "mode":
+		switch o.Mode {
+		case ModeAuto, ModeInteractive, ModeNonInteractive:
+		default:
+			return fmt.Errorf("invalid mode %q, must be one of %s, %s, %s", o.Mode, ModeAuto, ModeInteractive, ModeNonInteractive)
+		}
+	case "log-format":
+		switch o.LogFormat {
+		case logging.FormatText, logging.FormatJSON:
+		default:
+			return fmt.Errorf("invalid log format %q, must be one of %s, %s", o.LogFormat, logging.FormatText, logging.FormatJSON)
+		}
+	case "prompt-timeout", "connectivity-timeout", "check-frequency":
+		d := map[string]time.Duration{
+			"prompt-timeout":       o.PromptTimeout,
+			"connectivity-timeout": o.ConnectivityTimeout,
+			"check-frequency":      o.CheckFrequency,
+		}[name]
+		if d <= 0 {
+			return fmt.Errorf("%s must be greater than zero", name)
+		}
+	case "rendezvous-ip":
+		if o.RendezvousIP != "" {
+			if msg := rendezvous.ValidateIP(o.RendezvousIP); msg != "" {
+				return fmt.Errorf("invalid rendezvous-ip: %s", msg)
+			}
+		}
+	}
+	return nil
+}
+
+// Provider returns the configured provider of the network state
+func (o *Options) Provider() (net.Provider, error) {
+	return net.NewProvider(o.NetStateProvider, o.NetStateFixture)
+}
+
+// ResolveRendezvousIP returns the rendezvous IP, reading it from the
+// rendezvous host env file when not explicitly configured
+func (o *Options) ResolveRendezvousIP() string {
+	if o.RendezvousIP == "" {
+		if ip := rendezvous.Read(o.RendezvousHostEnvPath); ip != "" {
+			o.RendezvousIP = ip
+			o.Sources["rendezvous-ip"] = SourceRendezvousHostEnv
+		}
+	}
+	return o.RendezvousIP
+}
+
+// IsInteractive returns true if the interactive UI must be displayed.
+// In auto mode, it checks if the interactive UI sentinel file exists.
+func (o *Options) IsInteractive() bool {
+	switch o.Mode {
+	case ModeInteractive:
+		return true
+	case ModeNonInteractive:
+		return false
+	}
+	_, err := os.Stat(o.InteractiveUISentinelPath)
+	return err == nil
+}
+
+// ChecksConfig returns the configuration used by the checks and the UI
+func (o *Options) ChecksConfig() checks.Config {
+	return checks.Config{
+		ReleaseImageURL:       o.ReleaseImage,
+		LogPath:               o.LogPath,
+		LogLevel:              o.LogLevel,
+		LogFormat:             o.LogFormat,
+		LogMaxSizeMB:          o.LogMaxSizeMB,
+		LogMaxBackups:         o.LogMaxBackups,
+		LogSummaryInterval:    o.LogSummaryInterval,
+		JournalUnits:          o.JournalUnits,
+		RendezvousHostEnvPath: o.RendezvousHostEnvPath,
+		RegistryEnvPath:       o.RegistryEnvPath,
+		PromptTimeout:         o.PromptTimeout,
+		ConnectivityTimeout:   o.ConnectivityTimeout,
+		CheckFrequency:        o.CheckFrequency,
+		AutoContinue:          o.AutoContinue,
+		CheckEndpoints:        o.CheckEndpoints,
+	}
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/stretchr/testify/assert"
+)
+
+// setKernelCmdline replaces the kernel command line read by Load, so
+// that the tests don't depend on the one of the host
+func setKernelCmdline(t *testing.T, cmdline string) {
+	path := filepath.Join(t.TempDir(), "cmdline")
+	assert.NoError(t, os.WriteFile(path, []byte(cmdline+"\n"), 0644))
+	previous := kernelCmdlinePath
+	kernelCmdlinePath = path
+	t.Cleanup(func() { kernelCmdlinePath = previous })
+}
+
+func TestLoad(t *testing.T) {
+	cases := []struct {
+		name          string
+		args          []string
+		env           map[string]string
+		configFile    string
+		cmdline       string
+		expectedError string
+		check         func(t *testing.T, o *Options)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, o *Options) {
+				assert.Equal(t, DefaultLogPath, o.LogPath)
+				assert.Equal(t, checks.DefaultCheckFrequency, o.CheckFrequency)
+				assert.Equal(t, ModeAuto, o.Mode)
+				assert.Equal(t, net.ProviderAuto, o.NetStateProvider)
+				assert.Equal(t, SourceDefault, o.Sources["log-path"])
+				assert.Empty(t, o.ConfigPath)
+			},
+		},
+		{
+			name: "env",
+			env: map[string]string{
+				"RELEASE_IMAGE":             "quay.io/openshift-release-dev/ocp-release:4.15.0-x86_64",
+				"AGENT_TUI_LOG_MAX_BACKUPS": "7",
+				"AGENT_TUI_JOURNAL_UNITS":   "a.service, b.service",
+			},
+			check: func(t *testing.T, o *Options) {
+				assert.Equal(t, "quay.io/openshift-release-dev/ocp-release:4.15.0-x86_64", o.ReleaseImage)
+				assert.Equal(t, 7, o.LogMaxBackups)
+				assert.Equal(t, []string{"a.service", "b.service"}, o.JournalUnits)
+				assert.Equal(t, SourceEnv, o.Sources["release-image"])
+			},
+		},
+		{
+			name: "config file",
+			configFile: `
+log-path: /var/log/agent-tui.log
+prompt-timeout: 1m
+journal-units:
+  - agent.service
+  - other.service
+mode: interactive
+`,
+			check: func(t *testing.T, o *Options) {
+				assert.Equal(t, "/var/log/agent-tui.log", o.LogPath)
+				assert.Equal(t, time.Minute, o.PromptTimeout)
+				assert.Equal(t, []string{"agent.service", "other.service"}, o.JournalUnits)
+				assert.Equal(t, ModeInteractive, o.Mode)
+				assert.Equal(t, SourceFile, o.Sources["log-path"])
+				assert.True(t, o.IsInteractive())
+			},
+		},
+		{
+			name: "precedence",
+			args: []string{"--check-frequency", "30s"},
+			env: map[string]string{
+				"AGENT_TUI_CHECK_FREQUENCY": "20s",
+				"AGENT_TUI_PROMPT_TIMEOUT":  "40s",
+			},
+			configFile: `
+check-frequency: 10s
+prompt-timeout: 10s
+connectivity-timeout: 10s
+`,
+			check: func(t *testing.T, o *Options) {
+				assert.Equal(t, 30*time.Second, o.CheckFrequency)
+				assert.Equal(t, SourceFlag, o.Sources["check-frequency"])
+				assert.Equal(t, 40*time.Second, o.PromptTimeout)
+				assert.Equal(t, SourceEnv, o.Sources["prompt-timeout"])
+				assert.Equal(t, 10*time.Second, o.ConnectivityTimeout)
+				assert.Equal(t, SourceFile, o.Sources["connectivity-timeout"])
+			},
+		},
+		{
+			name:    "kernel cmdline",
+			cmdline: `BOOT_IMAGE=/images/pxeboot/vmlinuz ignition.firstboot agent-tui.rendezvous-ip=192.168.111.80 agent-tui.auto-continue agent-tui.check-endpoints="http://a.example.com, http://b.example.com" agent-tui.prompt-timeout=1m agent-tui.log-level=debug agent-tui.foo=bar agent-tui.check-frequency=often`,
+			env: map[string]string{
+				"AGENT_TUI_LOG_LEVEL": "warning",
+			},
+			configFile: `
+prompt-timeout: 10s
+`,
+			check: func(t *testing.T, o *Options) {
+				assert.Equal(t, "192.168.111.80", o.RendezvousIP)
+				assert.True(t, o.AutoContinue)
+				assert.Equal(t, []string{"http://a.example.com", "http://b.example.com"}, o.CheckEndpoints)
+				assert.Equal(t, time.Minute, o.PromptTimeout)
+				assert.Equal(t, SourceKernelCmdline, o.Sources["prompt-timeout"])
+				assert.Equal(t, "warning", o.LogLevel)
+				assert.Equal(t, SourceEnv, o.Sources["log-level"])
+				assert.Equal(t, checks.DefaultCheckFrequency, o.CheckFrequency)
+				assert.Equal(t, []string{
+					`ignoring kernel parameter agent-tui.check-frequency: invalid value "often" for check-frequency from kernel command line: time: invalid duration "often"`,
+					"ignoring unknown kernel parameter agent-tui.foo",
+				}, o.Warnings)
+			},
+		},
+		{
+			name:    "invalid kernel parameters",
+			cmdline: "agent-tui.mode=bogus agent-tui.rendezvous-ip=192.168.111.300",
+			configFile: `
+mode: interactive
+`,
+			check: func(t *testing.T, o *Options) {
+				assert.Equal(t, ModeInteractive, o.Mode)
+				assert.Equal(t, SourceFile, o.Sources["mode"])
+				assert.Empty(t, o.RendezvousIP)
+				assert.Equal(t, SourceDefault, o.Sources["rendezvous-ip"])
+				if assert.Len(t, o.Warnings, 2) {
+					assert.Equal(t, `ignoring kernel parameter agent-tui.mode: invalid mode "bogus", must be one of auto, interactive, non-interactive`, o.Warnings[0])
+					assert.Contains(t, o.Warnings[1], "ignoring kernel parameter agent-tui.rendezvous-ip: invalid rendezvous-ip")
+				}
+			},
+		},
+		{
+			name:    "bool flag without value",
+			args:    []string{"--skip"},
+			cmdline: "agent-tui.skip=false",
+			check: func(t *testing.T, o *Options) {
+				assert.True(t, o.Skip)
+				assert.Equal(t, SourceFlag, o.Sources["skip"])
+			},
+		},
+		{
+			name:          "invalid rendezvous IP",
+			env:           map[string]string{"AGENT_TUI_RENDEZVOUS_IP": "192.168.111"},
+			expectedError: "invalid rendezvous-ip: 192.168.111 is not a valid IP address",
+		},
+		{
+			name: "version",
+			args: []string{"--version"},
+			check: func(t *testing.T, o *Options) {
+				assert.True(t, o.ShowVersion)
+			},
+		},
+		{
+			name:          "unknown config key",
+			configFile:    "log-paht: /tmp/agent-tui.log",
+			expectedError: `unknown option "log-paht"`,
+		},
+		{
+			name:          "invalid duration",
+			args:          []string{"--prompt-timeout", "soon"},
+			expectedError: `invalid value "soon" for prompt-timeout from command line`,
+		},
+		{
+			name:          "invalid mode",
+			env:           map[string]string{"AGENT_TUI_MODE": "headless"},
+			expectedError: `invalid mode "headless"`,
+		},
+		{
+			name: "fixture provider",
+			env: map[string]string{
+				"AGENT_TUI_NETSTATE_PROVIDER": "fixture",
+				"AGENT_TUI_NETSTATE_FIXTURE":  "/tmp/netstate.yaml",
+			},
+			check: func(t *testing.T, o *Options) {
+				p, err := o.Provider()
+				assert.NoError(t, err)
+				assert.Equal(t, net.FixtureProvider{Path: "/tmp/netstate.yaml"}, p)
+			},
+		},
+		{
+			name:          "fixture provider without file",
+			args:          []string{"--netstate-provider", "fixture"},
+			expectedError: "invalid netstate-provider: the fixture provider requires a fixture file",
+		},
+		{
+			name:          "unknown provider",
+			args:          []string{"--netstate-provider", "networkd"},
+			expectedError: `invalid netstate-provider: unknown network state provider "networkd"`,
+		},
+		{
+			name:          "non positive frequency",
+			args:          []string{"--check-frequency", "0s"},
+			expectedError: "check-frequency must be greater than zero",
+		},
+		{
+			name:          "unexpected arguments",
+			args:          []string{"foo"},
+			expectedError: "unexpected arguments: foo",
+		},
+		{
+			name:          "missing explicit config file",
+			args:          []string{"--config", "/does/not/exist.yaml"},
+			expectedError: "failed to read config file",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := map[string]string{}
+			for k, v := range tc.env {
+				env[k] = v
+			}
+			if tc.configFile != "" {
+				path := filepath.Join(t.TempDir(), "agent-tui.yaml")
+				assert.NoError(t, os.WriteFile(path, []byte(tc.configFile), 0644))
+				env[configPathEnv] = path
+			}
+			setKernelCmdline(t, tc.cmdline)
+
+			o, err := Load("agent-tui", tc.args, func(name string) string { return env[name] }, &bytes.Buffer{})
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			tc.check(t, o)
+		})
+	}
+}
+
+func TestParseKernelCmdline(t *testing.T) {
+	params := parseKernelCmdline(`ro root=UUID=1234 quiet agent-tui.mode=interactive agent-tui.check-endpoints="http://a b" console=ttyS0,115200n8` + "\n")
+	assert.Equal(t, map[string]string{
+		"ro":                        "",
+		"root":                      "UUID=1234",
+		"quiet":                     "",
+		"agent-tui.mode":            "interactive",
+		"agent-tui.check-endpoints": "http://a b",
+		"console":                   "ttyS0,115200n8",
+	}, params)
+}
+
+func TestResolveRendezvousIP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rendezvous-host.env")
+	assert.NoError(t, os.WriteFile(path, []byte("NODE_ZERO_IP=192.168.111.81\n"), 0644))
+
+	o := Defaults()
+	o.RendezvousHostEnvPath = path
+	assert.Equal(t, "192.168.111.81", o.ResolveRendezvousIP())
+	assert.Equal(t, SourceRendezvousHostEnv, o.Sources["rendezvous-ip"])
+
+	o = Defaults()
+	o.RendezvousHostEnvPath = path
+	o.RendezvousIP = "192.168.111.80"
+	assert.Equal(t, "192.168.111.80", o.ResolveRendezvousIP())
+	assert.Equal(t, SourceDefault, o.Sources["rendezvous-ip"])
+}
+
+func TestLoadHelp(t *testing.T) {
+	setKernelCmdline(t, "")
+	out := &bytes.Buffer{}
+	_, err := Load("agent-tui", []string{"--help"}, func(string) string { return "" }, out)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	assert.Contains(t, out.String(), "Usage: agent-tui [flags]")
+	assert.Contains(t, out.String(), "[AGENT_TUI_CHECK_FREQUENCY]")
+	assert.Contains(t, out.String(), "-rendezvous-host-env-path")
+}
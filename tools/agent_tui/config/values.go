@@ -0,0 +1,122 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The following types implement flag.Value, so that every option can
+// be set from a string, independently from its origin.
+
+type stringValue struct{ p *string }
+
+func (v stringValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return *v.p
+}
+
+func (v stringValue) Set(s string) error {
+	*v.p = s
+	return nil
+}
+
+type intValue struct{ p *int }
+
+func (v intValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return strconv.Itoa(*v.p)
+}
+
+func (v intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v.p = n
+	return nil
+}
+
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return v.p.String()
+}
+
+func (v durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v.p = d
+	return nil
+}
+
+// listValue is a comma separated list of strings
+type listValue struct{ p *[]string }
+
+func (v listValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return strings.Join(*v.p, ",")
+}
+
+func (v listValue) Set(s string) error {
+	list := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*v.p = list
+	return nil
+}
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return strconv.FormatBool(*v.p)
+}
+
+func (v boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v.p = b
+	return nil
+}
+
+// rawValue keeps the string passed on the command line, so that it
+// could be applied after the other sources
+type rawValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+// IsBoolFlag allows boolean flags to be specified without a value
+func (v *rawValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (v *rawValue) String() string {
+	return v.value
+}
+
+func (v *rawValue) Set(s string) error {
+	v.value = s
+	v.set = true
+	return nil
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	DefaultLevel      = "info"
+	DefaultMaxSizeMB  = 10
+	DefaultMaxBackups = 3
+)
+
+// Options describes how the agent-tui log must be written
+type Options struct {
+	Path       string
+	Level      string // any level supported by logrus
+	Format     string // text or json
+	MaxSizeMB  int    // the log is rotated when it grows over this size, 0 to disable
+	MaxBackups int    // number of rotated files kept
+}
+
+// NewLogger creates a logger writing to a rotated file. The returned
+// RotatingFile must be closed when the logger is not needed anymore.
+func NewLogger(opts Options) (*logrus.Logger, *RotatingFile, error) {
+	logger := logrus.New()
+
+	level := opts.Level
+	if level == "" {
+		level = DefaultLevel
+	}
+	parsedLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.SetLevel(parsedLevel)
+
+	switch opts.Format {
+	case "", FormatText:
+		logger.SetFormatter(&logrus.TextFormatter{
+			DisableColors: true,
+			FullTimestamp: true,
+		})
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return nil, nil, fmt.Errorf("unsupported log format %q, must be one of %s, %s", opts.Format, FormatText, FormatJSON)
+	}
+
+	f, err := NewRotatingFile(opts.Path, int64(opts.MaxSizeMB)*1024*1024, opts.MaxBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.SetOutput(f)
+
+	return logger, f, nil
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a log file that gets rotated when it grows over
+// the configured size. Rotated files are renamed by appending a
+// numeric suffix, and only the most recent maxBackups are kept.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewRotatingFile opens (or creates) the log file in append mode. A
+// maxSize lower or equal than zero disables the rotation.
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", r.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file %s: %w", r.path, err)
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			// the writes go on to the current file, and the rotation
+			// is retried once maxSize more bytes are written to it
+			r.size = 0
+			n, _ := r.file.Write(p)
+			r.size += int64(n)
+			return n, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the existing backups, moves the current file to the
+// first backup and then starts a new file. The current file is closed
+// only once the new one is open, so that it's kept on errors.
+func (r *RotatingFile) rotate() error {
+	if r.maxBackups > 0 {
+		// the oldest backup is removed, if present
+		os.Remove(r.backupName(r.maxBackups))
+		for i := r.maxBackups - 1; i >= 1; i-- {
+			if _, err := os.Stat(r.backupName(i)); err == nil {
+				if err := os.Rename(r.backupName(i), r.backupName(i+1)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := os.Rename(r.path, r.backupName(1)); err != nil {
+			return err
+		}
+	} else if err := os.Truncate(r.path, 0); err != nil {
+		return err
+	}
+
+	previous := r.file
+	if err := r.open(); err != nil {
+		return err
+	}
+	return previous.Close()
+}
+
+func (r *RotatingFile) backupName(index int) string {
+	return fmt.Sprintf("%s.%d", r.path, index)
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
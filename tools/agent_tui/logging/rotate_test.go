@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent_tui.log")
+
+	// stale content must be preserved, and not overwritten
+	assert.NoError(t, os.WriteFile(path, []byte("previous run\n"), 0644))
+
+	r, err := NewRotatingFile(path, 20, 2)
+	assert.NoError(t, err)
+
+	write := func(s string) {
+		_, err := r.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(name)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	write("line 1\n")
+	assert.Equal(t, "previous run\nline 1\n", read(path))
+
+	write("line 2\n")
+	assert.Equal(t, "line 2\n", read(path))
+	assert.Equal(t, "previous run\nline 1\n", read(path+".1"))
+
+	write("line 3 is longer\n")
+	write("line 4 is longer\n")
+	assert.Equal(t, "line 4 is longer\n", read(path))
+	assert.Equal(t, "line 3 is longer\n", read(path+".1"))
+	assert.Equal(t, "line 2\n", read(path+".2"))
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, r.Close())
+}
+
+func TestRotatingFileRotationError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent_tui.log")
+
+	r, err := NewRotatingFile(path, 10, 1)
+	assert.NoError(t, err)
+	_, err = r.Write([]byte(strings.Repeat("a", 8)))
+	assert.NoError(t, err)
+
+	// the current file can't be renamed over a directory
+	assert.NoError(t, os.MkdirAll(filepath.Join(path+".1", "busy"), 0755))
+	n, err := r.Write([]byte(strings.Repeat("b", 8)))
+	assert.Error(t, err)
+	assert.Equal(t, 8, n)
+	// the rotation is not retried right away
+	_, err = r.Write([]byte("c"))
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("a", 8)+strings.Repeat("b", 8)+"c", string(data))
+
+	// the rotation succeeds once the backup can be replaced
+	assert.NoError(t, os.RemoveAll(path+".1"))
+	_, err = r.Write([]byte(strings.Repeat("d", 8)))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("d", 8), string(data))
+}
+
+func TestRotatingFileWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent_tui.log")
+
+	r, err := NewRotatingFile(path, 10, 0)
+	assert.NoError(t, err)
+	_, err = r.Write([]byte(strings.Repeat("a", 8)))
+	assert.NoError(t, err)
+	_, err = r.Write([]byte(strings.Repeat("b", 8)))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("b", 8), string(data))
+}
+
+func TestNewLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent_tui.log")
+
+	logger, f, err := NewLogger(Options{Path: path, Level: "warn", Format: FormatJSON})
+	assert.NoError(t, err)
+	logger.Info("not logged")
+	logger.Warn("logged")
+	assert.NoError(t, f.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "not logged")
+	assert.Contains(t, string(data), `"msg":"logged"`)
+
+	_, _, err = NewLogger(Options{Path: path, Format: "xml"})
+	assert.Error(t, err)
+	_, _, err = NewLogger(Options{Path: path, Level: "verbose"})
+	assert.Error(t, err)
+	_, _, err = NewLogger(Options{Path: filepath.Join(path, "missing", "agent_tui.log")})
+	assert.Error(t, err)
+}
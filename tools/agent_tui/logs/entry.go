@@ -0,0 +1,160 @@
+package logs
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry is a single log line, independently from its origin
+type Entry struct {
+	Time    time.Time
+	Level   logrus.Level
+	Source  string
+	Message string
+}
+
+// Source is a log provider that can be polled for new entries
+type Source interface {
+	// Name returns a short description of the source
+	Name() string
+	// ReadNew returns the entries added since the previous call
+	ReadNew() ([]Entry, error)
+}
+
+var logrusTextField = regexp.MustCompile(`(\w+)=("(?:[^"\\]|\\.)*"|\S*)`)
+
+// ParseLogrusLine parses a line produced by logrus, either using the
+// text or the JSON formatter. Lines that do not match any of the formats
+// are returned as a plain info message.
+func ParseLogrusLine(source string, line string) Entry {
+	entry := Entry{
+		Source:  source,
+		Level:   logrus.InfoLevel,
+		Message: line,
+	}
+
+	fields := map[string]string{}
+	if strings.HasPrefix(line, "{") {
+		raw := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return entry
+		}
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				fields[k] = s
+			}
+		}
+	} else {
+		for _, m := range logrusTextField.FindAllStringSubmatch(line, -1) {
+			value := m[2]
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			fields[m[1]] = value
+		}
+	}
+
+	msg, found := fields["msg"]
+	if !found {
+		return entry
+	}
+	entry.Message = msg
+	if level, err := logrus.ParseLevel(fields["level"]); err == nil {
+		entry.Level = level
+	}
+	if t, err := time.Parse(time.RFC3339, fields["time"]); err == nil {
+		entry.Time = t
+	}
+	return entry
+}
+
+// ParseJournalJSON parses a single entry produced by journalctl -o json
+func ParseJournalJSON(line string) (Entry, string, error) {
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return Entry{}, "", err
+	}
+
+	entry := Entry{
+		Level:   logrus.InfoLevel,
+		Message: journalString(raw["MESSAGE"]),
+		Source:  journalString(raw["_SYSTEMD_UNIT"]),
+	}
+	if entry.Source == "" {
+		entry.Source = journalString(raw["SYSLOG_IDENTIFIER"])
+	}
+	if usec, err := strconv.ParseInt(journalString(raw["__REALTIME_TIMESTAMP"]), 10, 64); err == nil {
+		entry.Time = time.UnixMicro(usec)
+	}
+	if priority, err := strconv.Atoi(journalString(raw["PRIORITY"])); err == nil {
+		entry.Level = journalPriorityToLevel(priority)
+	}
+	return entry, journalString(raw["__CURSOR"]), nil
+}
+
+// journalString decodes a journal field. Fields that are not valid
+// UTF-8 are exported by journalctl as an array of bytes.
+func journalString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []interface{}:
+		b := make([]byte, 0, len(value))
+		for _, c := range value {
+			if n, ok := c.(float64); ok {
+				b = append(b, byte(n))
+			}
+		}
+		return string(b)
+	}
+	return ""
+}
+
+// journalPriorityToLevel maps syslog priorities to logrus levels
+func journalPriorityToLevel(priority int) logrus.Level {
+	switch {
+	case priority <= 3: // emerg, alert, crit, err
+		return logrus.ErrorLevel
+	case priority == 4: // warning
+		return logrus.WarnLevel
+	case priority <= 6: // notice, info
+		return logrus.InfoLevel
+	default: // debug
+		return logrus.DebugLevel
+	}
+}
+
+// Filter returns the entries from the specified source (or from any
+// source if empty), at least as severe as the given level and
+// containing the search text.
+func Filter(entries []Entry, source string, level logrus.Level, search string) []Entry {
+	search = strings.ToLower(search)
+	filtered := []Entry{}
+	for _, e := range entries {
+		if source != "" && e.Source != source {
+			continue
+		}
+		if e.Level > level {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(e.Message), search) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+// SortByTime sorts the entries chronologically, preserving the
+// original order of the entries with the same time
+func SortByTime(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+}
@@ -0,0 +1,152 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogrusLine(t *testing.T) {
+	cases := []struct {
+		name     string
+		line     string
+		expected Entry
+	}{
+		{
+			name: "text formatter",
+			line: `time="2023-02-01T10:00:01Z" level=warning msg="DNS check failed: \"timeout\"\n"`,
+			expected: Entry{
+				Time:    time.Date(2023, 2, 1, 10, 0, 1, 0, time.UTC),
+				Level:   logrus.WarnLevel,
+				Source:  "agent-tui",
+				Message: "DNS check failed: \"timeout\"\n",
+			},
+		},
+		{
+			name: "json formatter",
+			line: `{"level":"error","msg":"nmtui failed","time":"2023-02-01T10:00:03Z"}`,
+			expected: Entry{
+				Time:    time.Date(2023, 2, 1, 10, 0, 3, 0, time.UTC),
+				Level:   logrus.ErrorLevel,
+				Source:  "agent-tui",
+				Message: "nmtui failed",
+			},
+		},
+		{
+			name: "unknown format",
+			line: "panic: something went wrong",
+			expected: Entry{
+				Level:   logrus.InfoLevel,
+				Source:  "agent-tui",
+				Message: "panic: something went wrong",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := ParseLogrusLine("agent-tui", tc.line)
+			assert.Equal(t, tc.expected.Message, entry.Message)
+			assert.Equal(t, tc.expected.Level, entry.Level)
+			assert.Equal(t, tc.expected.Source, entry.Source)
+			assert.True(t, tc.expected.Time.Equal(entry.Time))
+		})
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/agent_tui.log")
+	assert.NoError(t, err)
+
+	logPath := filepath.Join(t.TempDir(), "agent_tui.log")
+	assert.NoError(t, os.WriteFile(logPath, fixture, 0644))
+
+	source := NewFileSource("agent-tui", logPath)
+	entries, err := source.ReadNew()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 5)
+	assert.Equal(t, logrus.WarnLevel, entries[1].Level)
+	assert.Equal(t, logrus.ErrorLevel, entries[3].Level)
+	assert.Equal(t, "not a logrus line", entries[4].Message)
+	assert.Equal(t, entries[3].Time, entries[4].Time)
+
+	// nothing new
+	entries, err = source.ReadNew()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// incomplete lines are returned only when terminated
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`time="2023-02-01T10:00:04Z" level=info msg="partial`)
+	assert.NoError(t, err)
+	entries, err = source.ReadNew()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	_, err = f.WriteString(` line"` + "\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	entries, err = source.ReadNew()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "partial line", entries[0].Message)
+
+	// after a truncation the file is read from the beginning
+	assert.NoError(t, os.WriteFile(logPath, []byte(`time="2023-02-01T11:00:00Z" level=debug msg="restarted"`+"\n"), 0644))
+	entries, err = source.ReadNew()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "restarted", entries[0].Message)
+}
+
+func TestJournalSource(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/journal.json")
+	assert.NoError(t, err)
+
+	calls := [][]string{}
+	source := NewJournalSource("agent.service", 100)
+	source.run = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		if len(calls) == 1 {
+			return fixture, nil
+		}
+		return []byte{}, nil
+	}
+
+	entries, err := source.ReadNew()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "Started agent", entries[0].Message)
+	assert.Equal(t, logrus.InfoLevel, entries[0].Level)
+	assert.Equal(t, "agent.service", entries[0].Source)
+	assert.True(t, time.Date(2023, 2, 1, 10, 0, 0, 0, time.UTC).Equal(entries[0].Time))
+	assert.Equal(t, logrus.WarnLevel, entries[1].Level)
+	assert.Equal(t, logrus.ErrorLevel, entries[2].Level)
+	assert.Equal(t, "bad\xff", entries[2].Message)
+	assert.Equal(t, "-n 100", strings.Join(calls[0][len(calls[0])-2:], " "))
+
+	// following reads start after the last received entry
+	_, err = source.ReadNew()
+	assert.NoError(t, err)
+	assert.Equal(t, "--after-cursor s=1;i=3", strings.Join(calls[1][len(calls[1])-2:], " "))
+}
+
+func TestFilter(t *testing.T) {
+	entries := []Entry{
+		{Source: "agent-tui", Level: logrus.InfoLevel, Message: "Release Image URL"},
+		{Source: "agent-tui", Level: logrus.WarnLevel, Message: "DNS check failed"},
+		{Source: "agent.service", Level: logrus.ErrorLevel, Message: "Failed to register host"},
+		{Source: "agent.service", Level: logrus.DebugLevel, Message: "Polling"},
+	}
+
+	assert.Len(t, Filter(entries, "", logrus.DebugLevel, ""), 4)
+	assert.Len(t, Filter(entries, "", logrus.InfoLevel, ""), 3)
+	assert.Equal(t, entries[1:3], Filter(entries, "", logrus.WarnLevel, ""))
+	assert.Equal(t, entries[2:4], Filter(entries, "agent.service", logrus.DebugLevel, ""))
+	assert.Equal(t, entries[1:3], Filter(entries, "", logrus.DebugLevel, "FAILED"))
+}
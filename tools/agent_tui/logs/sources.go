@@ -0,0 +1,147 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Maximum number of bytes read from a log file in a single poll
+const maxReadSize = 1 << 20
+
+// FileSource tails a log file written by logrus
+type FileSource struct {
+	name     string
+	path     string
+	offset   int64
+	partial  []byte
+	lastTime time.Time
+}
+
+func NewFileSource(name string, path string) *FileSource {
+	return &FileSource{
+		name: name,
+		path: path,
+	}
+}
+
+func (f *FileSource) Name() string {
+	return f.name
+}
+
+// ReadNew returns the complete lines appended to the file since the
+// previous call. If the file was truncated or rotated, it is read
+// again from the beginning.
+func (f *FileSource) ReadNew() ([]Entry, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < f.offset {
+		f.offset = 0
+		f.partial = nil
+	}
+	if info.Size()-f.offset > maxReadSize {
+		// skip the oldest content, too much to be displayed anyhow
+		f.offset = info.Size() - maxReadSize
+		f.partial = nil
+	}
+
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	f.offset += int64(len(data))
+
+	data = append(f.partial, data...)
+	lastNewline := bytes.LastIndexByte(data, '\n')
+	if lastNewline < 0 {
+		f.partial = data
+		return nil, nil
+	}
+	f.partial = append([]byte{}, data[lastNewline+1:]...)
+
+	entries := []Entry{}
+	for _, line := range strings.Split(string(data[:lastNewline]), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry := ParseLogrusLine(f.name, line)
+		if entry.Time.IsZero() {
+			// lines without a timestamp (ie a panic trace) are
+			// kept together with the previous entry
+			entry.Time = f.lastTime
+		}
+		f.lastTime = entry.Time
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// JournalSource reads the journal of a systemd unit
+type JournalSource struct {
+	unit   string
+	lines  int
+	cursor string
+
+	// run executes journalctl, it can be replaced for testing
+	run func(args ...string) ([]byte, error)
+}
+
+func NewJournalSource(unit string, lines int) *JournalSource {
+	return &JournalSource{
+		unit:  unit,
+		lines: lines,
+		run: func(args ...string) ([]byte, error) {
+			return exec.Command("journalctl", args...).Output()
+		},
+	}
+}
+
+func (j *JournalSource) Name() string {
+	return j.unit
+}
+
+// ReadNew returns the journal entries of the unit logged since the
+// previous call. The first call returns only the most recent entries.
+func (j *JournalSource) ReadNew() ([]Entry, error) {
+	args := []string{"-o", "json", "--no-pager", "-u", j.unit}
+	if j.cursor != "" {
+		args = append(args, "--after-cursor", j.cursor)
+	} else {
+		args = append(args, "-n", fmt.Sprint(j.lines))
+	}
+
+	out, err := j.run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the journal of %s: %w", j.unit, err)
+	}
+
+	entries := []Entry{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry, cursor, err := ParseJournalJSON(line)
+		if err != nil {
+			return entries, err
+		}
+		entry.Source = j.unit
+		entries = append(entries, entry)
+		j.cursor = cursor
+	}
+	return entries, nil
+}
@@ -1,67 +1,75 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 
-	"github.com/joho/godotenv"
-
+	"github.com/openshift/agent-installer-utils/pkg/version"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui"
-	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
-	"github.com/openshift/agent-installer-utils/tools/agent_tui/ui"
-)
-
-const (
-	RENDEZVOUS_IP_TEMPLATE_VALUE = "{{.RendezvousIP}}"
-	INTERACTIVE_UI_SENTINEL_PATH = "/etc/assisted/interactive-ui"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/cli"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
 )
 
 func main() {
-	releaseImage := os.Getenv("RELEASE_IMAGE")
-	logPath := os.Getenv("AGENT_TUI_LOG_PATH")
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:], cli.Env{
+			Getenv: os.Getenv,
+			Stdout: os.Stdout,
+			Stderr: os.Stderr,
+		}))
+	}
+
+	opts, err := config.Load("agent-tui", os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if opts.ShowVersion {
+		fmt.Printf("agent-tui %s\n", version.Raw)
+		fmt.Printf("commit: %s\n", version.Commit)
+		return
+	}
+
+	// the options are validated, so the provider is known to be valid
+	provider, _ := opts.Provider()
+	net.SetProvider(provider)
+
+	for _, warning := range opts.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if opts.Skip {
+		fmt.Println("agent-tui is disabled by the skip setting, exiting.")
+		return
+	}
 
-	if releaseImage == "" {
-		fmt.Println("RELEASE_IMAGE environment variable is not specified.")
+	if opts.ReleaseImage == "" {
+		fmt.Println("The release image is not specified with --release-image or the RELEASE_IMAGE environment variable.")
 		fmt.Println("Unable to perform connectivity checks.")
 		fmt.Println("Exiting agent-tui.")
 		os.Exit(1)
 	}
-	if logPath == "" {
-		logPath = "/tmp/agent_tui.log"
-		fmt.Printf("AGENT_TUI_LOG_PATH is unspecified, logging to: %v\n", logPath)
+	if opts.Sources["log-path"] == config.SourceDefault {
+		fmt.Printf("AGENT_TUI_LOG_PATH is unspecified, logging to: %v\n", opts.LogPath)
 	}
-	rendezvousIP := getRendezvousIP()
-	interactiveUIMode := IsInteractiveUIEnabled()
+
+	// A rendezvous IP explicitly configured, for example on the kernel
+	// command line, is only kept in memory. It's saved to the rendezvous
+	// host env file once the user confirms it on the rendezvous IP page.
+	rendezvousIP := opts.ResolveRendezvousIP()
 
 	ctx := agent_tui.AppContext{
 		App:               nil,
 		RendezvousIP:      rendezvousIP,
-		InteractiveUIMode: interactiveUIMode,
-		Config: checks.Config{
-			ReleaseImageURL: releaseImage,
-			LogPath:         logPath,
-		},
+		InteractiveUIMode: opts.IsInteractive(),
+		Config:            opts.ChecksConfig(),
+		Settings:          opts.Settings(),
+		Warnings:          opts.Warnings,
 	}
 	agent_tui.App(ctx)
 }
-
-// getRendezvousIP reads NODE_ZERO_IP from /etc/assisted/rendezvous-host.env.
-func getRendezvousIP() (nodeZeroIP string) {
-	envMap, err := godotenv.Read(ui.RENDEZVOUS_HOST_ENV_PATH)
-	if err != nil {
-		return ""
-	}
-	nodeZeroIP = envMap["NODE_ZERO_IP"]
-	if nodeZeroIP == RENDEZVOUS_IP_TEMPLATE_VALUE {
-		nodeZeroIP = ""
-	}
-
-	return nodeZeroIP
-}
-
-// IsInteractiveUIEnabled checks if the interactive UI sentinel file exists.
-// Returns true if /etc/assisted/interactive-ui exists, false otherwise.
-func IsInteractiveUIEnabled() bool {
-	_, err := os.Stat(INTERACTIVE_UI_SENTINEL_PATH)
-	return err == nil
-}
@@ -0,0 +1,163 @@
+package media
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The paths can be replaced by the tests
+var (
+	mountsPath   = "/proc/self/mounts"
+	sysBlockPath = "/sys/block"
+)
+
+// Mount is a mounted filesystem
+type Mount struct {
+	Device   string
+	Path     string
+	FSType   string
+	ReadOnly bool
+}
+
+// RemovableMounts returns the filesystems mounted from removable
+// devices, like USB sticks or SD cards
+func RemovableMounts() ([]Mount, error) {
+	data, err := os.ReadFile(mountsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := []Mount{}
+	for _, mount := range parseMounts(string(data)) {
+		if isRemovable(mount.Device) {
+			mounts = append(mounts, mount)
+		}
+	}
+	return mounts, nil
+}
+
+func parseMounts(data string) []Mount {
+	mounts := []Mount{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		readOnly := false
+		for _, option := range strings.Split(fields[3], ",") {
+			readOnly = readOnly || option == "ro"
+		}
+		mounts = append(mounts, Mount{
+			Device:   fields[0],
+			Path:     unescapeMountPath(fields[1]),
+			FSType:   fields[2],
+			ReadOnly: readOnly,
+		})
+	}
+	return mounts
+}
+
+// unescapeMountPath decodes the octal escapes used by the kernel
+// for the spaces and the other special characters
+func unescapeMountPath(path string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(path)
+}
+
+// isRemovable checks whether the disk of a device, for example sdb for
+// /dev/sdb1, is flagged as removable or is attached through USB
+func isRemovable(device string) bool {
+	disk := diskName(filepath.Base(device))
+	data, err := os.ReadFile(filepath.Join(sysBlockPath, disk, "removable"))
+	if err == nil && strings.TrimSpace(string(data)) == "1" {
+		return true
+	}
+	target, err := os.Readlink(filepath.Join(sysBlockPath, disk))
+	return err == nil && strings.Contains(target, "/usb")
+}
+
+// diskName strips the partition number from a partition name, like
+// sdb1 or nvme0n1p2
+func diskName(partition string) string {
+	disk := strings.TrimRight(partition, "0123456789")
+	if disk == partition {
+		return partition
+	}
+	// devices whose name ends with a digit use a p before the partition number
+	if strings.HasSuffix(disk, "p") && len(disk) > 1 && strings.ContainsAny(disk[len(disk)-2:len(disk)-1], "0123456789") {
+		return strings.TrimSuffix(disk, "p")
+	}
+	if _, err := os.Stat(filepath.Join(sysBlockPath, disk)); err != nil {
+		// not a partition, for example sr0
+		return partition
+	}
+	return disk
+}
+
+// WriteFile writes the file and flushes it to the disk, since a
+// removable media could be unplugged right after
+func WriteFile(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// FindFiles returns the regular files of dir and of its subdirectories,
+// up to maxDepth levels, having one of the extensions. The hidden
+// directories and the unreadable ones are skipped.
+func FindFiles(dir string, extensions []string, maxDepth int) ([]string, error) {
+	files := []string{}
+	root := filepath.Clean(dir)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			depth := strings.Count(strings.TrimPrefix(path, root), string(filepath.Separator))
+			if path != root && (depth > maxDepth || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		for _, extension := range extensions {
+			if strings.EqualFold(filepath.Ext(path), extension) {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// FindOnMounts returns the files found by FindFiles on all the mounts.
+// The mounts that can't be read are skipped.
+func FindOnMounts(mounts []Mount, extensions []string, maxDepth int) []string {
+	files := []string{}
+	for _, mount := range mounts {
+		found, err := FindFiles(mount.Path, extensions, maxDepth)
+		if err == nil {
+			files = append(files, found...)
+		}
+	}
+	return files
+}
@@ -0,0 +1,63 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemovableMounts(t *testing.T) {
+	dir := t.TempDir()
+	mountsPath = filepath.Join(dir, "mounts")
+	sysBlockPath = filepath.Join(dir, "block")
+	defer func() {
+		mountsPath = "/proc/self/mounts"
+		sysBlockPath = "/sys/block"
+	}()
+
+	assert.NoError(t, os.WriteFile(mountsPath, []byte(`proc /proc proc rw,nosuid 0 0
+/dev/sda4 / xfs rw,relatime 0 0
+/dev/sdb1 /run/media/core/USB\040STICK vfat rw,relatime 0 0
+/dev/sr0 /run/media/iso iso9660 ro,relatime 0 0
+/dev/nvme0n1p2 /var xfs rw,relatime 0 0
+/dev/sdc1 /mnt/usb-disk ext4 rw,relatime 0 0
+`), 0644))
+	for disk, removable := range map[string]string{"sda": "0", "sdb": "1", "sr0": "1", "nvme0n1": "0"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(sysBlockPath, disk), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(sysBlockPath, disk, "removable"), []byte(removable+"\n"), 0644))
+	}
+	// USB disks are not flagged as removable, but are attached through USB
+	usbDisk := filepath.Join(dir, "devices/pci0000:00/0000:00:14.0/usb2/2-1/block/sdc")
+	assert.NoError(t, os.MkdirAll(usbDisk, 0755))
+	assert.NoError(t, os.Symlink(usbDisk, filepath.Join(sysBlockPath, "sdc")))
+
+	mounts, err := RemovableMounts()
+	assert.NoError(t, err)
+	assert.Equal(t, []Mount{
+		{Device: "/dev/sdb1", Path: "/run/media/core/USB STICK", FSType: "vfat"},
+		{Device: "/dev/sr0", Path: "/run/media/iso", FSType: "iso9660", ReadOnly: true},
+		{Device: "/dev/sdc1", Path: "/mnt/usb-disk", FSType: "ext4"},
+	}, mounts)
+}
+
+func TestFindFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"a.yaml", "b.YML", "c.txt", "nested/d.yaml", "nested/deeper/e.yaml", ".hidden/f.yaml"} {
+		path := filepath.Join(dir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte("interfaces: []"), 0644))
+	}
+
+	files, err := FindFiles(dir, []string{".yaml", ".yml"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.yaml"),
+		filepath.Join(dir, "b.YML"),
+		filepath.Join(dir, "nested/d.yaml"),
+	}, files)
+
+	_, err = FindFiles(filepath.Join(dir, "missing"), []string{".yaml"}, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package net
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"syscall"
+	"time"
+)
+
+const (
+	arpRequest = 1
+	arpReply   = 2
+
+	sizeofEthHeader = 14
+	sizeofARP       = 28
+	// the shorter frames are padded by the drivers
+	minEthFrame = 60
+
+	// RFC 5227 sends a few probes, spaced by a random interval
+	arpProbeCount = 3
+)
+
+var ethBroadcast = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// arpPacket is an ARP message for IPv4 over ethernet
+type arpPacket struct {
+	Op        uint16
+	SenderMAC net.HardwareAddr
+	SenderIP  net.IP
+	TargetMAC net.HardwareAddr
+	TargetIP  net.IP
+}
+
+// arpProbeFrame returns an ethernet frame with an ARP probe for ip, as
+// defined by RFC 5227: a broadcast request with an all-zero sender
+// address, so that the caches of the other hosts are not updated
+func arpProbeFrame(mac net.HardwareAddr, ip net.IP) []byte {
+	frame := make([]byte, minEthFrame)
+	copy(frame[0:6], ethBroadcast)
+	copy(frame[6:12], mac)
+	binary.BigEndian.PutUint16(frame[12:14], syscall.ETH_P_ARP)
+
+	arp := frame[sizeofEthHeader:]
+	binary.BigEndian.PutUint16(arp[0:2], syscall.ARPHRD_ETHER)
+	binary.BigEndian.PutUint16(arp[2:4], syscall.ETH_P_IP)
+	arp[4] = 6
+	arp[5] = net.IPv4len
+	binary.BigEndian.PutUint16(arp[6:8], arpRequest)
+	copy(arp[8:14], mac)
+	// the sender and target hardware addresses are left zeroed
+	copy(arp[24:28], ip.To4())
+	return frame
+}
+
+// parseARP parses the ARP message of an ethernet frame
+func parseARP(frame []byte) (arpPacket, bool) {
+	if len(frame) < sizeofEthHeader+sizeofARP || binary.BigEndian.Uint16(frame[12:14]) != syscall.ETH_P_ARP {
+		return arpPacket{}, false
+	}
+	arp := frame[sizeofEthHeader:]
+	if binary.BigEndian.Uint16(arp[0:2]) != syscall.ARPHRD_ETHER || binary.BigEndian.Uint16(arp[2:4]) != syscall.ETH_P_IP ||
+		arp[4] != 6 || arp[5] != net.IPv4len {
+		return arpPacket{}, false
+	}
+	return arpPacket{
+		Op:        binary.BigEndian.Uint16(arp[6:8]),
+		SenderMAC: net.HardwareAddr(bytes.Clone(arp[8:14])),
+		SenderIP:  net.IP(bytes.Clone(arp[14:18])),
+		TargetMAC: net.HardwareAddr(bytes.Clone(arp[18:24])),
+		TargetIP:  net.IP(bytes.Clone(arp[24:28])),
+	}, true
+}
+
+// conflicts returns true if the packet, received while probing ip from
+// mac, shows that another host uses or is probing the same address
+func (p arpPacket) conflicts(mac net.HardwareAddr, ip net.IP) bool {
+	if bytes.Equal(p.SenderMAC, mac) {
+		return false
+	}
+	if p.SenderIP.Equal(ip) {
+		return true
+	}
+	// another host probing the same address at the same time
+	return p.Op == arpRequest && p.SenderIP.Equal(net.IPv4zero) && p.TargetIP.Equal(ip)
+}
+
+// ARPProbe sends ARP probes for ip from the interface, and returns the
+// MAC addresses of the other hosts using the same address. It needs the
+// CAP_NET_RAW capability.
+func ARPProbe(ifaceName string, ip net.IP, timeout time.Duration) ([]string, error) {
+	if ip.To4() == nil {
+		return nil, fmt.Errorf("%s is not an IPv4 address", ip)
+	}
+	iface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return nil, err
+	}
+	if len(iface.HardwareAddr) != 6 {
+		return nil, fmt.Errorf("interface %s has no ethernet address", ifaceName)
+	}
+
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW, int(htons(syscall.ETH_P_ARP)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the ARP socket: %w", err)
+	}
+	defer syscall.Close(fd)
+	if err := syscall.Bind(fd, &syscall.SockaddrLinklayer{Protocol: htons(syscall.ETH_P_ARP), Ifindex: iface.Index}); err != nil {
+		return nil, fmt.Errorf("cannot bind the ARP socket to %s: %w", ifaceName, err)
+	}
+	// the reads are interrupted periodically to send the next probe
+	interval := timeout / arpProbeCount
+	tv := syscall.NsecToTimeval(min(interval, 100*time.Millisecond).Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		return nil, err
+	}
+
+	frame := arpProbeFrame(iface.HardwareAddr, ip)
+	to := &syscall.SockaddrLinklayer{Ifindex: iface.Index, Halen: 6}
+	copy(to.Addr[:], ethBroadcast)
+
+	macs := map[string]bool{}
+	buf := make([]byte, 1500)
+	start := time.Now()
+	for sent := 0; time.Since(start) < timeout; {
+		if sent < arpProbeCount && time.Since(start) >= time.Duration(sent)*interval {
+			if err := syscall.Sendto(fd, frame, 0, to); err != nil {
+				return nil, fmt.Errorf("cannot send the ARP probe on %s: %w", ifaceName, err)
+			}
+			sent++
+		}
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p, ok := parseARP(buf[:n]); ok && p.conflicts(iface.HardwareAddr, ip) {
+			macs[p.SenderMAC.String()] = true
+		}
+	}
+
+	conflicts := []string{}
+	for mac := range macs {
+		conflicts = append(conflicts, mac)
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// htons converts a short from the host to the network byte order
+func htons(v uint16) uint16 {
+	b := binary.BigEndian.AppendUint16(nil, v)
+	return binary.NativeEndian.Uint16(b)
+}
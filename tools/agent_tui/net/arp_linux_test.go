@@ -0,0 +1,74 @@
+package net
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestARPProbeFrame(t *testing.T) {
+	mac, _ := net.ParseMAC("52:54:00:aa:bb:cc")
+	ip := net.ParseIP("192.168.111.20")
+
+	frame := arpProbeFrame(mac, ip)
+	assert.Len(t, frame, minEthFrame)
+	assert.Equal(t, []byte(ethBroadcast), frame[0:6])
+	probe, ok := parseARP(frame)
+	assert.True(t, ok)
+	assert.Equal(t, uint16(arpRequest), probe.Op)
+	assert.Equal(t, mac, probe.SenderMAC)
+	assert.True(t, probe.SenderIP.Equal(net.IPv4zero))
+	assert.True(t, probe.TargetIP.Equal(ip))
+	// our own probe, looped back by the packet socket
+	assert.False(t, probe.conflicts(mac, ip))
+
+	// a reply captured from the host owning the address
+	reply := []byte{
+		0x52, 0x54, 0x00, 0xaa, 0xbb, 0xcc, 0x52, 0x54, 0x00, 0x12, 0x34, 0x56, 0x08, 0x06,
+		0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x02,
+		0x52, 0x54, 0x00, 0x12, 0x34, 0x56, 192, 168, 111, 20,
+		0x52, 0x54, 0x00, 0xaa, 0xbb, 0xcc, 0, 0, 0, 0,
+	}
+	p, ok := parseARP(reply)
+	assert.True(t, ok)
+	assert.Equal(t, uint16(arpReply), p.Op)
+	assert.Equal(t, "52:54:00:12:34:56", p.SenderMAC.String())
+	assert.True(t, p.conflicts(mac, ip))
+	assert.False(t, p.conflicts(mac, net.ParseIP("192.168.111.21")))
+
+	// another host probing the same address
+	other, _ := net.ParseMAC("52:54:00:12:34:56")
+	p, _ = parseARP(arpProbeFrame(other, ip))
+	assert.True(t, p.conflicts(mac, ip))
+
+	_, ok = parseARP(reply[:30])
+	assert.False(t, ok)
+	ipv4 := append([]byte{}, reply...)
+	binary.BigEndian.PutUint16(ipv4[12:14], syscall.ETH_P_IP)
+	_, ok = parseARP(ipv4)
+	assert.False(t, ok)
+}
+
+func TestParseAddressDAD(t *testing.T) {
+	// ifaddrmsg of a tentative address on the loopback, with IFA_ADDRESS
+	// and IFA_FLAGS marking it as failed
+	data := []byte{syscall.AF_INET6, 64, ifaFTentative, 0, 0, 0, 0, 0}
+	binary.NativeEndian.PutUint32(data[4:8], 1)
+	data = append(data, 20, 0, syscall.IFA_ADDRESS, 0)
+	data = append(data, net.ParseIP("fd00::20")...)
+	data = append(data, 8, 0, ifaFlags, 0)
+	data = binary.NativeEndian.AppendUint32(data, ifaFTentative|ifaFDADFailed)
+
+	state, ok := parseAddressDAD(&syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWADDR}, Data: data})
+	assert.True(t, ok)
+	assert.True(t, state.IP.Equal(net.ParseIP("fd00::20")))
+	assert.True(t, state.Tentative)
+	assert.True(t, state.Failed)
+
+	data[0] = syscall.AF_INET
+	_, ok = parseAddressDAD(&syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWADDR}, Data: data})
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+package net
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxVLANID       = 4094
+	maxIfaceNameLen = 15
+)
+
+// BondModes are the bonding modes supported by the kernel, the most
+// common first
+var BondModes = []string{
+	"active-backup",
+	"802.3ad",
+	"balance-rr",
+	"balance-xor",
+	"balance-alb",
+	"balance-tlb",
+	"broadcast",
+}
+
+// BondSettings describe a bond of physical interfaces, with an optional
+// VLAN on top of it. The IP settings apply to the VLAN when VLANID is
+// set, and to the bond otherwise.
+type BondSettings struct {
+	Name   string
+	Mode   string
+	Ports  []string
+	VLANID int
+	MTU    int
+	IPv4   IPSettings
+	IPv6   IPSettings
+	DNS    *DNSConfig
+}
+
+// IPIfaceName returns the name of the interface carrying the IP
+// settings, following the <bond>.<vlan id> convention for the VLAN
+func (s BondSettings) IPIfaceName() string {
+	if s.VLANID != 0 {
+		return fmt.Sprintf("%s.%d", s.Name, s.VLANID)
+	}
+	return s.Name
+}
+
+func (s BondSettings) ipIfaceSettings() IfaceSettings {
+	settings := IfaceSettings{
+		Name: s.IPIfaceName(),
+		Type: "bond",
+		MTU:  s.MTU,
+		IPv4: s.IPv4,
+		IPv6: s.IPv6,
+	}
+	if s.VLANID != 0 {
+		settings.Type = "vlan"
+	}
+	return settings
+}
+
+// Validate checks the bond against the current network state, where
+// its name must not be used and the ports must exist
+func (s BondSettings) Validate(ns NetState) error {
+	errs := []string{}
+	if s.Name == "" {
+		errs = append(errs, "the bond name is required")
+	} else if len(s.IPIfaceName()) > maxIfaceNameLen {
+		errs = append(errs, fmt.Sprintf("the interface name %s is longer than %d characters", s.IPIfaceName(), maxIfaceNameLen))
+	} else if ns.getIfaceByName(s.Name) != nil {
+		errs = append(errs, fmt.Sprintf("interface %s already exists", s.Name))
+	}
+
+	validMode := false
+	for _, mode := range BondModes {
+		validMode = validMode || mode == s.Mode
+	}
+	if !validMode {
+		errs = append(errs, fmt.Sprintf("unknown bond mode %q", s.Mode))
+	}
+
+	if len(s.Ports) == 0 {
+		errs = append(errs, "at least one port is required")
+	}
+	for _, port := range s.Ports {
+		if ns.getIfaceByName(port) == nil {
+			errs = append(errs, fmt.Sprintf("port %s not found", port))
+		}
+	}
+
+	if s.VLANID < 0 || s.VLANID > maxVLANID {
+		errs = append(errs, fmt.Sprintf("the VLAN ID must be between 1 and %d", maxVLANID))
+	}
+	if err := s.ipIfaceSettings().Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// DesiredState converts the settings to a nmstate desired state. The
+// IP settings of the ports, and of the bond when a VLAN is used, are
+// disabled.
+func (s BondSettings) DesiredState() DesiredState {
+	state := NetworkSettings{Ifaces: []IfaceSettings{s.ipIfaceSettings()}, DNS: s.DNS}.DesiredState()
+	ipIface := state.Interfaces[0]
+	disabled := func() *DesiredIPConfig { return &DesiredIPConfig{Enabled: false} }
+
+	ifaces := []DesiredIface{}
+	for _, port := range s.Ports {
+		ifaces = append(ifaces, DesiredIface{
+			Name:  port,
+			Type:  "ethernet",
+			State: "up",
+			IPv4:  disabled(),
+			IPv6:  disabled(),
+		})
+	}
+	bond := DesiredIface{
+		Name:  s.Name,
+		Type:  "bond",
+		State: "up",
+		MTU:   s.MTU,
+		IPv4:  ipIface.IPv4,
+		IPv6:  ipIface.IPv6,
+		LinkAggregation: &LinkAggregation{
+			Mode:  s.Mode,
+			Ports: s.Ports,
+		},
+	}
+	if s.VLANID != 0 {
+		bond.IPv4, bond.IPv6 = disabled(), disabled()
+		ipIface.VLAN = &VLAN{BaseIface: s.Name, ID: s.VLANID}
+		ifaces = append(ifaces, bond, ipIface)
+	} else {
+		ifaces = append(ifaces, bond)
+	}
+	state.Interfaces = ifaces
+	return state
+}
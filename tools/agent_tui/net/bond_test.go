@@ -0,0 +1,117 @@
+package net
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bondTestNetState() NetState {
+	return NetState{Ifaces: []Iface{
+		{Name: "eth0", Type: "ethernet", State: "up"},
+		{Name: "eth1", Type: "ethernet", State: "up"},
+	}}
+}
+
+func TestBondSettingsValidate(t *testing.T) {
+	cases := []struct {
+		name          string
+		settings      BondSettings
+		expectedError string
+	}{
+		{
+			name: "valid",
+			settings: BondSettings{
+				Name: "bond0", Mode: "802.3ad", Ports: []string{"eth0", "eth1"}, VLANID: 100,
+				IPv4: IPSettings{Enabled: true, DHCP: true},
+			},
+		},
+		{
+			name: "existing name",
+			settings: BondSettings{
+				Name: "eth0", Mode: "active-backup", Ports: []string{"eth1"},
+			},
+			expectedError: "interface eth0 already exists",
+		},
+		{
+			name: "too long",
+			settings: BondSettings{
+				Name: "uplink-bond", Mode: "active-backup", Ports: []string{"eth0"}, VLANID: 1000,
+			},
+			expectedError: "the interface name uplink-bond.1000 is longer than 15 characters",
+		},
+		{
+			name: "invalid values",
+			settings: BondSettings{
+				Name: "bond0", Mode: "lacp", Ports: []string{"eth5"}, VLANID: 4095,
+				IPv4: IPSettings{Enabled: true},
+			},
+			expectedError: "unknown bond mode \"lacp\"\n" +
+				"port eth5 not found\n" +
+				"the VLAN ID must be between 1 and 4094\n" +
+				"bond0.4095: IPv4 requires at least one address when DHCP is disabled",
+		},
+		{
+			name:          "no ports",
+			settings:      BondSettings{Name: "bond0", Mode: "active-backup"},
+			expectedError: "at least one port is required",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.Validate(bondTestNetState())
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestBondSettingsDesiredState(t *testing.T) {
+	settings := BondSettings{
+		Name:   "bond0",
+		Mode:   "802.3ad",
+		Ports:  []string{"eth0", "eth1"},
+		VLANID: 100,
+		MTU:    9000,
+		IPv4:   IPSettings{Enabled: true, Addresses: mustParseAddresses(t, "192.168.100.80/24"), Gateway: net.ParseIP("192.168.100.1")},
+	}
+	assert.Equal(t, "bond0.100", settings.IPIfaceName())
+
+	data, err := json.Marshal(settings.DesiredState())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"interfaces": [
+			{"name": "eth0", "type": "ethernet", "state": "up", "ipv4": {"enabled": false}, "ipv6": {"enabled": false}},
+			{"name": "eth1", "type": "ethernet", "state": "up", "ipv4": {"enabled": false}, "ipv6": {"enabled": false}},
+			{"name": "bond0", "type": "bond", "state": "up", "mtu": 9000,
+			 "ipv4": {"enabled": false}, "ipv6": {"enabled": false},
+			 "link-aggregation": {"mode": "802.3ad", "port": ["eth0", "eth1"]}},
+			{"name": "bond0.100", "type": "vlan", "state": "up", "mtu": 9000,
+			 "ipv4": {"enabled": true, "dhcp": false, "address": [{"ip": "192.168.100.80", "prefix-length": 24}]},
+			 "ipv6": {"enabled": false},
+			 "vlan": {"base-iface": "bond0", "id": 100}}
+		],
+		"routes": {"config": [
+			{"destination": "0.0.0.0/0", "next-hop-interface": "bond0.100", "state": "absent"},
+			{"destination": "0.0.0.0/0", "next-hop-interface": "bond0.100", "next-hop-address": "192.168.100.1"},
+			{"destination": "::/0", "next-hop-interface": "bond0.100", "state": "absent"}
+		]}
+	}`, string(data))
+
+	// without VLAN the bond carries the IP settings
+	settings.VLANID = 0
+	settings.IPv4 = IPSettings{Enabled: true, DHCP: true}
+	state := settings.DesiredState()
+	assert.Len(t, state.Interfaces, 3)
+	bond := state.Interfaces[2]
+	assert.Equal(t, "bond0", bond.Name)
+	assert.True(t, bond.IPv4.Enabled)
+	assert.True(t, *bond.IPv4.DHCP)
+	assert.Nil(t, bond.VLAN)
+}
@@ -0,0 +1,70 @@
+package net
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+)
+
+// The address flags, as defined in linux/if_addr.h
+const (
+	ifaFlags = 8
+
+	ifaFDADFailed = 0x08
+	ifaFTentative = 0x40
+)
+
+// AddressDAD is the state of the duplicate address detection of an
+// IPv6 address
+type AddressDAD struct {
+	Iface string
+	IP    net.IP
+	// Tentative is set until the detection completes
+	Tentative bool
+	// Failed is set when another host answered for the address
+	Failed bool
+}
+
+// IPv6DAD returns the duplicate address detection state of the IPv6
+// addresses, the link-local ones included
+func IPv6DAD() ([]AddressDAD, error) {
+	msgs, err := netlinkDump(syscall.RTM_GETADDR)
+	if err != nil {
+		return nil, err
+	}
+
+	states := []AddressDAD{}
+	for i := range msgs {
+		state, ok := parseAddressDAD(&msgs[i])
+		if ok {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+func parseAddressDAD(m *syscall.NetlinkMessage) (AddressDAD, bool) {
+	if m.Header.Type != syscall.RTM_NEWADDR || len(m.Data) < syscall.SizeofIfAddrmsg || m.Data[0] != syscall.AF_INET6 {
+		return AddressDAD{}, false
+	}
+	attrs := parseNestedAttrs(m.Data[syscall.SizeofIfAddrmsg:])
+	ip := net.IP(attrs[syscall.IFA_ADDRESS])
+	if len(ip) != net.IPv6len {
+		return AddressDAD{}, false
+	}
+
+	// IFA_FLAGS has all the 32 bits of the flags, the header only 8
+	flags := uint32(m.Data[2])
+	if value, ok := attrUint32(attrs, ifaFlags); ok {
+		flags = value
+	}
+	state := AddressDAD{
+		IP:        ip,
+		Tentative: flags&ifaFTentative != 0,
+		Failed:    flags&ifaFDADFailed != 0,
+	}
+	if iface, err := net.InterfaceByIndex(int(int32(binary.NativeEndian.Uint32(m.Data[4:8])))); err == nil {
+		state.Iface = iface.Name
+	}
+	return state, true
+}
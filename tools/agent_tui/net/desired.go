@@ -0,0 +1,314 @@
+package net
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	minMTU     = 68
+	minIPv6MTU = 1280
+	maxMTU     = 9216
+
+	ipv4DefaultDestination = "0.0.0.0/0"
+	ipv6DefaultDestination = "::/0"
+)
+
+// IPSettings are the user editable settings of an address family
+type IPSettings struct {
+	Enabled bool
+	// DHCP enables DHCP for IPv4, DHCPv6 and autoconf for IPv6
+	DHCP      bool
+	Addresses []net.IPNet
+	Gateway   net.IP
+}
+
+// IfaceSettings are the user editable settings of an interface
+type IfaceSettings struct {
+	Name string
+	Type string
+	MTU  int
+	IPv4 IPSettings
+	IPv6 IPSettings
+}
+
+// NetworkSettings describes the changes to be applied. DNS is nil
+// when the DNS configuration must not be modified.
+type NetworkSettings struct {
+	Ifaces []IfaceSettings
+	DNS    *DNSConfig
+}
+
+// The following types describe the subset of the nmstate desired
+// state used by agent-tui
+
+type DesiredState struct {
+	Interfaces []DesiredIface `json:"interfaces,omitempty"`
+	Routes     *DesiredRoutes `json:"routes,omitempty"`
+	DNS        *DesiredDNS    `json:"dns-resolver,omitempty"`
+}
+
+type DesiredIface struct {
+	Name            string           `json:"name"`
+	Type            string           `json:"type"`
+	State           string           `json:"state"`
+	MTU             int              `json:"mtu,omitempty"`
+	IPv4            *DesiredIPConfig `json:"ipv4,omitempty"`
+	IPv6            *DesiredIPConfig `json:"ipv6,omitempty"`
+	LinkAggregation *LinkAggregation `json:"link-aggregation,omitempty"`
+	VLAN            *VLAN            `json:"vlan,omitempty"`
+}
+
+// DesiredIPConfig differs from IPConfig since all the flags must be
+// explicitly set, for example to disable DHCP
+type DesiredIPConfig struct {
+	Enabled   bool       `json:"enabled"`
+	DHCP      *bool      `json:"dhcp,omitempty"`
+	Autoconf  *bool      `json:"autoconf,omitempty"`
+	Addresses []_Address `json:"address,omitempty"`
+}
+
+type DesiredRoutes struct {
+	Config []DesiredRoute `json:"config"`
+}
+
+type DesiredRoute struct {
+	Destination  string `json:"destination"`
+	NextHopIface string `json:"next-hop-interface,omitempty"`
+	NextHopAddr  string `json:"next-hop-address,omitempty"`
+	Metric       int    `json:"metric,omitempty"`
+	TableID      int    `json:"table-id,omitempty"`
+	State        string `json:"state,omitempty"`
+}
+
+type DesiredDNS struct {
+	Config DNSConfig `json:"config"`
+}
+
+// NewIfaceSettings returns the current settings of an interface. The
+// default gateways are taken from the routes of the main table using
+// the interface, with the lowest metric.
+func NewIfaceSettings(ns NetState, iface Iface) IfaceSettings {
+	settings := IfaceSettings{
+		Name: iface.Name,
+		Type: iface.Type,
+		MTU:  iface.MTU,
+		IPv4: IPSettings{
+			Enabled:   iface.IPv4.Enabled,
+			DHCP:      iface.IPv4.DHCP,
+			Addresses: iface.IPv4.Addresses,
+		},
+		IPv6: IPSettings{
+			Enabled: iface.IPv6.Enabled,
+			DHCP:    iface.IPv6.DHCP || iface.IPv6.Autoconf,
+		},
+	}
+	// link local addresses are generated automatically, so they
+	// are not part of the settings
+	for _, address := range iface.IPv6.Addresses {
+		if !address.IP.IsLinkLocalUnicast() {
+			settings.IPv6.Addresses = append(settings.IPv6.Addresses, address)
+		}
+	}
+
+	for _, family := range []struct {
+		family  Family
+		gateway *net.IP
+	}{
+		{FamilyIPv4, &settings.IPv4.Gateway},
+		{FamilyIPv6, &settings.IPv6.Gateway},
+	} {
+		// the routes are ordered by metric, so the gateway is the
+		// one of the preferred route
+		for _, route := range ns.DefaultRoutes(family.family) {
+			if route.NextHopIface == iface.Name && route.NextHopAddr != "" && route.InMainTable() {
+				*family.gateway = net.ParseIP(route.NextHopAddr)
+				break
+			}
+		}
+	}
+	return settings
+}
+
+// Validate checks that the settings are consistent
+func (s IfaceSettings) Validate() error {
+	errs := []string{}
+
+	minimum := minMTU
+	if s.IPv6.Enabled {
+		minimum = minIPv6MTU
+	}
+	if s.MTU != 0 && (s.MTU < minimum || s.MTU > maxMTU) {
+		errs = append(errs, fmt.Sprintf("MTU must be between %d and %d", minimum, maxMTU))
+	}
+
+	for _, family := range []struct {
+		name     string
+		settings IPSettings
+		isIPv4   bool
+	}{
+		{"IPv4", s.IPv4, true},
+		{"IPv6", s.IPv6, false},
+	} {
+		ip := family.settings
+		if !ip.Enabled {
+			continue
+		}
+		for _, address := range ip.Addresses {
+			if (address.IP.To4() != nil) != family.isIPv4 {
+				errs = append(errs, fmt.Sprintf("%s is not an %s address", address.String(), family.name))
+			}
+		}
+		if ip.DHCP {
+			continue
+		}
+		if len(ip.Addresses) == 0 {
+			errs = append(errs, fmt.Sprintf("%s requires at least one address when DHCP is disabled", family.name))
+		}
+		if ip.Gateway != nil {
+			if (ip.Gateway.To4() != nil) != family.isIPv4 {
+				errs = append(errs, fmt.Sprintf("%s gateway %s is not an %s address", family.name, ip.Gateway, family.name))
+			} else if !onLink(ip.Gateway, ip.Addresses) {
+				errs = append(errs, fmt.Sprintf("%s gateway %s is not in the subnet of any address", family.name, ip.Gateway))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %s", s.Name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func onLink(ip net.IP, addresses []net.IPNet) bool {
+	for _, address := range addresses {
+		subnet := net.IPNet{IP: address.IP.Mask(address.Mask), Mask: address.Mask}
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks all the interfaces and the DNS settings
+func (s NetworkSettings) Validate() error {
+	errs := []string{}
+	for _, iface := range s.Ifaces {
+		if err := iface.Validate(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if s.DNS != nil {
+		for _, server := range s.DNS.Servers {
+			if net.ParseIP(server) == nil {
+				errs = append(errs, fmt.Sprintf("DNS server %s is not a valid IP address", server))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// DesiredState converts the settings to a nmstate desired state. The
+// default routes of the static interfaces are replaced by their gateways.
+func (s NetworkSettings) DesiredState() DesiredState {
+	state := DesiredState{}
+	routes := []DesiredRoute{}
+
+	for _, iface := range s.Ifaces {
+		state.Interfaces = append(state.Interfaces, DesiredIface{
+			Name:  iface.Name,
+			Type:  iface.Type,
+			State: "up",
+			MTU:   iface.MTU,
+			IPv4:  desiredIPConfig(iface.IPv4, false),
+			IPv6:  desiredIPConfig(iface.IPv6, true),
+		})
+
+		for _, family := range []struct {
+			settings    IPSettings
+			destination string
+		}{
+			{iface.IPv4, ipv4DefaultDestination},
+			{iface.IPv6, ipv6DefaultDestination},
+		} {
+			if family.settings.DHCP {
+				continue
+			}
+			routes = append(routes, DesiredRoute{
+				Destination:  family.destination,
+				NextHopIface: iface.Name,
+				State:        "absent",
+			})
+			if family.settings.Enabled && family.settings.Gateway != nil {
+				routes = append(routes, DesiredRoute{
+					Destination:  family.destination,
+					NextHopIface: iface.Name,
+					NextHopAddr:  family.settings.Gateway.String(),
+				})
+			}
+		}
+	}
+
+	if len(routes) > 0 {
+		state.Routes = &DesiredRoutes{Config: routes}
+	}
+	if s.DNS != nil {
+		state.DNS = &DesiredDNS{Config: *s.DNS}
+	}
+	return state
+}
+
+func desiredIPConfig(settings IPSettings, isIPv6 bool) *DesiredIPConfig {
+	if !settings.Enabled {
+		return &DesiredIPConfig{Enabled: false}
+	}
+
+	dhcp := settings.DHCP
+	config := &DesiredIPConfig{
+		Enabled: true,
+		DHCP:    &dhcp,
+	}
+	if isIPv6 {
+		config.Autoconf = &dhcp
+	}
+	if !dhcp {
+		for _, address := range settings.Addresses {
+			prefixlen, _ := address.Mask.Size()
+			config.Addresses = append(config.Addresses, _Address{
+				IP:        address.IP.String(),
+				Prefixlen: prefixlen,
+			})
+		}
+	}
+	return config
+}
+
+// ParseAddresses parses a comma separated list of addresses in CIDR notation
+func ParseAddresses(text string) ([]net.IPNet, error) {
+	addresses := []net.IPNet{}
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		ip, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid address, expected the <ip>/<prefix length> format", item)
+		}
+		addresses = append(addresses, net.IPNet{IP: ip, Mask: ipNet.Mask})
+	}
+	return addresses, nil
+}
+
+// FormatAddresses is the inverse of ParseAddresses
+func FormatAddresses(addresses []net.IPNet) string {
+	items := []string{}
+	for _, address := range addresses {
+		items = append(items, address.String())
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,140 @@
+package net
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseAddresses(t *testing.T, text string) []net.IPNet {
+	addresses, err := ParseAddresses(text)
+	assert.NoError(t, err)
+	return addresses
+}
+
+func TestNewIfaceSettings(t *testing.T) {
+	ns := NetState{
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1"},
+			{Destination: "::/0", NextHopIface: "eth1", NextHopAddr: "fd00::1"},
+		}},
+	}
+	iface := Iface{
+		Name: "eth0",
+		Type: "ethernet",
+		MTU:  1500,
+		IPv4: IPConfig{Enabled: true, Addresses: mustParseAddresses(t, "192.168.111.80/24")},
+		IPv6: IPConfig{Enabled: true, Autoconf: true, Addresses: mustParseAddresses(t, "fe80::1/64, fd00::80/64")},
+	}
+
+	settings := NewIfaceSettings(ns, iface)
+	assert.Equal(t, "192.168.111.80/24", FormatAddresses(settings.IPv4.Addresses))
+	assert.False(t, settings.IPv4.DHCP)
+	assert.Equal(t, "192.168.111.1", settings.IPv4.Gateway.String())
+	assert.True(t, settings.IPv6.DHCP)
+	assert.Equal(t, "fd00::80/64", FormatAddresses(settings.IPv6.Addresses))
+	assert.Nil(t, settings.IPv6.Gateway)
+}
+
+func TestIfaceSettingsValidate(t *testing.T) {
+	cases := []struct {
+		name          string
+		settings      IfaceSettings
+		expectedError string
+	}{
+		{
+			name: "valid static",
+			settings: IfaceSettings{
+				Name: "eth0",
+				MTU:  9000,
+				IPv4: IPSettings{Enabled: true, Addresses: mustParseAddresses(t, "192.168.111.80/24"), Gateway: net.ParseIP("192.168.111.1")},
+			},
+		},
+		{
+			name: "static without addresses",
+			settings: IfaceSettings{
+				Name: "eth0",
+				IPv4: IPSettings{Enabled: true},
+			},
+			expectedError: "eth0: IPv4 requires at least one address when DHCP is disabled",
+		},
+		{
+			name: "gateway not on link",
+			settings: IfaceSettings{
+				Name: "eth0",
+				IPv4: IPSettings{Enabled: true, Addresses: mustParseAddresses(t, "192.168.111.80/24"), Gateway: net.ParseIP("192.168.112.1")},
+			},
+			expectedError: "IPv4 gateway 192.168.112.1 is not in the subnet of any address",
+		},
+		{
+			name: "wrong family",
+			settings: IfaceSettings{
+				Name: "eth0",
+				IPv6: IPSettings{Enabled: true, Addresses: mustParseAddresses(t, "192.168.111.80/24")},
+			},
+			expectedError: "192.168.111.80/24 is not an IPv6 address",
+		},
+		{
+			name: "IPv6 MTU",
+			settings: IfaceSettings{
+				Name: "eth0",
+				MTU:  1000,
+				IPv6: IPSettings{Enabled: true, DHCP: true},
+			},
+			expectedError: "MTU must be between 1280 and 9216",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestDesiredState(t *testing.T) {
+	settings := NetworkSettings{
+		Ifaces: []IfaceSettings{{
+			Name: "eth0",
+			Type: "ethernet",
+			MTU:  1500,
+			IPv4: IPSettings{Enabled: true, Addresses: mustParseAddresses(t, "192.168.111.80/24"), Gateway: net.ParseIP("192.168.111.1")},
+			IPv6: IPSettings{Enabled: true, DHCP: true},
+		}},
+		DNS: &DNSConfig{Servers: []string{"192.168.111.1"}},
+	}
+
+	data, err := json.Marshal(settings.DesiredState())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"interfaces": [{
+			"name": "eth0",
+			"type": "ethernet",
+			"state": "up",
+			"mtu": 1500,
+			"ipv4": {"enabled": true, "dhcp": false, "address": [{"ip": "192.168.111.80", "prefix-length": 24}]},
+			"ipv6": {"enabled": true, "dhcp": true, "autoconf": true}
+		}],
+		"routes": {"config": [
+			{"destination": "0.0.0.0/0", "next-hop-interface": "eth0", "state": "absent"},
+			{"destination": "0.0.0.0/0", "next-hop-interface": "eth0", "next-hop-address": "192.168.111.1"}
+		]},
+		"dns-resolver": {"config": {"server": ["192.168.111.1"]}}
+	}`, string(data))
+}
+
+func TestParseAddresses(t *testing.T) {
+	addresses, err := ParseAddresses(" 192.168.111.80/24, fd00::80/64 ,")
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.111.80/24, fd00::80/64", FormatAddresses(addresses))
+
+	_, err = ParseAddresses("192.168.111.80")
+	assert.ErrorContains(t, err, "192.168.111.80 is not a valid address")
+}
@@ -0,0 +1,520 @@
+package net
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	dhcpv4ClientPort = 68
+	dhcpv4ServerPort = 67
+	dhcpv6ClientPort = 546
+	dhcpv6ServerPort = 547
+
+	// the fixed part of a DHCPv4 message, followed by the magic cookie
+	// and the options
+	sizeofBOOTP      = 236
+	dhcpMagicCookie  = 0x63825363
+	bootRequest      = 1
+	bootReply        = 2
+	bootpBroadcast   = 0x8000
+	minDHCPv4Message = 300
+
+	sizeofIPv4Header = 20
+	sizeofIPv6Header = 40
+	sizeofUDPHeader  = 8
+
+	dhcpOptPad          = 0
+	dhcpOptSubnetMask   = 1
+	dhcpOptRouter       = 3
+	dhcpOptDNS          = 6
+	dhcpOptLeaseTime    = 51
+	dhcpOptMessageType  = 53
+	dhcpOptServerID     = 54
+	dhcpOptParamRequest = 55
+	dhcpOptEnd          = 255
+
+	dhcpMsgDiscover = 1
+	dhcpMsgOffer    = 2
+
+	dhcpv6MsgSolicit   = 1
+	dhcpv6MsgAdvertise = 2
+
+	dhcpv6OptClientID    = 1
+	dhcpv6OptServerID    = 2
+	dhcpv6OptIANA        = 3
+	dhcpv6OptIAAddr      = 5
+	dhcpv6OptORO         = 6
+	dhcpv6OptElapsedTime = 8
+	dhcpv6OptStatusCode  = 13
+	dhcpv6OptDNSServers  = 23
+
+	dhcpv6StatusSuccess = 0
+	// a DUID based on the link-layer address, RFC 8415 section 11.4
+	duidLL = 3
+
+	// the probes are sent again until the end of the timeout, for the
+	// servers missing the first one
+	dhcpRetransmit = 2 * time.Second
+)
+
+var (
+	// All_DHCP_Relay_Agents_and_Servers, and its multicast MAC address
+	dhcpv6Servers    = net.ParseIP("ff02::1:2")
+	dhcpv6ServersMAC = net.HardwareAddr{0x33, 0x33, 0x00, 0x01, 0x00, 0x02}
+)
+
+// DHCPOffer is an answer to a DHCP probe
+type DHCPOffer struct {
+	Iface string
+	// Version is 4 for DHCPv4 and 6 for DHCPv6
+	Version int
+	// Server is the address of a DHCPv4 server, or the DUID of a DHCPv6
+	// one
+	Server string
+	// IP is the address offered, with its prefix length when known. It's
+	// empty if the server has no address available.
+	IP string
+	// Gateway is not set for DHCPv6, the IPv6 routers advertise themselves
+	Gateway   string
+	DNS       []string
+	LeaseTime time.Duration
+	// Status is the message of a DHCPv6 server without an address
+	// available
+	Status string
+}
+
+func (o DHCPOffer) String() string {
+	ip := o.IP
+	if ip == "" {
+		ip = "no address"
+		if o.Status != "" {
+			ip += fmt.Sprintf(" (%s)", o.Status)
+		}
+	}
+	fields := []string{fmt.Sprintf("DHCPv%d server %s offers %s", o.Version, o.Server, ip)}
+	if o.Gateway != "" {
+		fields = append(fields, "gateway "+o.Gateway)
+	}
+	if len(o.DNS) > 0 {
+		fields = append(fields, "DNS "+strings.Join(o.DNS, " "))
+	}
+	if o.LeaseTime != 0 {
+		fields = append(fields, "lease "+o.LeaseTime.String())
+	}
+	return strings.Join(fields, ", ")
+}
+
+// dhcpv4Discover returns a DHCPDISCOVER message asking for the address,
+// the router, the DNS servers and the lease time. The broadcast flag is
+// set, since the interface may not have an address to receive unicast.
+func dhcpv4Discover(xid uint32, mac net.HardwareAddr) []byte {
+	msg := make([]byte, sizeofBOOTP+4, minDHCPv4Message)
+	msg[0] = bootRequest
+	msg[1] = syscall.ARPHRD_ETHER
+	msg[2] = byte(len(mac))
+	binary.BigEndian.PutUint32(msg[4:8], xid)
+	binary.BigEndian.PutUint16(msg[10:12], bootpBroadcast)
+	copy(msg[28:44], mac)
+	binary.BigEndian.PutUint32(msg[sizeofBOOTP:sizeofBOOTP+4], dhcpMagicCookie)
+
+	msg = append(msg, dhcpOptMessageType, 1, dhcpMsgDiscover)
+	msg = append(msg, dhcpOptParamRequest, 5, dhcpOptSubnetMask, dhcpOptRouter, dhcpOptDNS, dhcpOptLeaseTime, dhcpOptServerID)
+	msg = append(msg, dhcpOptEnd)
+	// some servers ignore the messages shorter than a BOOTP one
+	for len(msg) < minDHCPv4Message {
+		msg = append(msg, dhcpOptPad)
+	}
+	return msg
+}
+
+// parseDHCPv4Offer parses a DHCPOFFER answering the DHCPDISCOVER with
+// the given transaction ID
+func parseDHCPv4Offer(msg []byte, xid uint32) (DHCPOffer, error) {
+	if len(msg) < sizeofBOOTP+4 || binary.BigEndian.Uint32(msg[sizeofBOOTP:sizeofBOOTP+4]) != dhcpMagicCookie {
+		return DHCPOffer{}, errors.New("not a DHCP message")
+	}
+	if msg[0] != bootReply || binary.BigEndian.Uint32(msg[4:8]) != xid {
+		return DHCPOffer{}, errors.New("not an answer to the probe")
+	}
+	offer := DHCPOffer{Version: 4}
+	yiaddr := net.IP(bytes.Clone(msg[16:20]))
+	var mask net.IPMask
+	msgType := 0
+	for options := msg[sizeofBOOTP+4:]; len(options) > 0; {
+		code := options[0]
+		if code == dhcpOptEnd {
+			break
+		}
+		if code == dhcpOptPad {
+			options = options[1:]
+			continue
+		}
+		if len(options) < 2 || len(options) < 2+int(options[1]) {
+			return DHCPOffer{}, fmt.Errorf("option %d truncated", code)
+		}
+		value := options[2 : 2+int(options[1])]
+		options = options[2+len(value):]
+
+		switch {
+		case code == dhcpOptMessageType && len(value) == 1:
+			msgType = int(value[0])
+		case code == dhcpOptServerID && len(value) == net.IPv4len:
+			offer.Server = net.IP(value).String()
+		case code == dhcpOptSubnetMask && len(value) == net.IPv4len:
+			mask = net.IPMask(bytes.Clone(value))
+		case code == dhcpOptRouter && len(value) >= net.IPv4len:
+			offer.Gateway = net.IP(value[:net.IPv4len]).String()
+		case code == dhcpOptDNS:
+			for i := 0; i+net.IPv4len <= len(value); i += net.IPv4len {
+				offer.DNS = append(offer.DNS, net.IP(value[i:i+net.IPv4len]).String())
+			}
+		case code == dhcpOptLeaseTime && len(value) == 4:
+			offer.LeaseTime = time.Duration(binary.BigEndian.Uint32(value)) * time.Second
+		}
+	}
+	if msgType != dhcpMsgOffer {
+		return DHCPOffer{}, fmt.Errorf("DHCP message type %d is not an offer", msgType)
+	}
+	offer.IP = yiaddr.String()
+	if ones, bits := mask.Size(); bits != 0 {
+		offer.IP = fmt.Sprintf("%s/%d", yiaddr, ones)
+	}
+	return offer, nil
+}
+
+// dhcpv6Solicit returns a SOLICIT message asking for a non-temporary
+// address and the DNS servers. The rapid commit option is not sent, so
+// that the servers answer with an ADVERTISE and don't assign a lease.
+func dhcpv6Solicit(xid uint32, mac net.HardwareAddr) []byte {
+	msg := []byte{dhcpv6MsgSolicit, byte(xid >> 16), byte(xid >> 8), byte(xid)}
+	option := func(code uint16, value []byte) {
+		msg = binary.BigEndian.AppendUint16(msg, code)
+		msg = binary.BigEndian.AppendUint16(msg, uint16(len(value)))
+		msg = append(msg, value...)
+	}
+
+	duid := binary.BigEndian.AppendUint16(nil, duidLL)
+	duid = binary.BigEndian.AppendUint16(duid, syscall.ARPHRD_ETHER)
+	option(dhcpv6OptClientID, append(duid, mac...))
+	option(dhcpv6OptElapsedTime, []byte{0, 0})
+	option(dhcpv6OptORO, binary.BigEndian.AppendUint16(nil, dhcpv6OptDNSServers))
+	// the IAID, derived from the MAC address, and T1 and T2 left to the
+	// server
+	iana := make([]byte, 12)
+	copy(iana[0:4], mac[len(mac)-4:])
+	option(dhcpv6OptIANA, iana)
+	return msg
+}
+
+// dhcpv6Options splits the options of a DHCPv6 message
+func dhcpv6Options(data []byte) (map[uint16][][]byte, error) {
+	options := map[uint16][][]byte{}
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, errors.New("option truncated")
+		}
+		code, length := binary.BigEndian.Uint16(data[0:2]), int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+length {
+			return nil, fmt.Errorf("option %d truncated", code)
+		}
+		options[code] = append(options[code], data[4:4+length])
+		data = data[4+length:]
+	}
+	return options, nil
+}
+
+// parseDHCPv6Advertise parses an ADVERTISE answering the SOLICIT with
+// the given transaction ID
+func parseDHCPv6Advertise(msg []byte, xid uint32) (DHCPOffer, error) {
+	if len(msg) < 4 || msg[0] != dhcpv6MsgAdvertise || uint32(msg[1])<<16|uint32(msg[2])<<8|uint32(msg[3]) != xid&0xffffff {
+		return DHCPOffer{}, errors.New("not an answer to the probe")
+	}
+	options, err := dhcpv6Options(msg[4:])
+	if err != nil {
+		return DHCPOffer{}, err
+	}
+	offer := DHCPOffer{Version: 6}
+	if len(options[dhcpv6OptServerID]) == 0 {
+		return DHCPOffer{}, errors.New("server ID missing")
+	}
+	offer.Server = net.HardwareAddr(options[dhcpv6OptServerID][0]).String()
+	for _, value := range options[dhcpv6OptDNSServers] {
+		for i := 0; i+net.IPv6len <= len(value); i += net.IPv6len {
+			offer.DNS = append(offer.DNS, net.IP(value[i:i+net.IPv6len]).String())
+		}
+	}
+	for _, value := range options[dhcpv6OptStatusCode] {
+		if len(value) >= 2 && binary.BigEndian.Uint16(value[0:2]) != dhcpv6StatusSuccess {
+			offer.Status = string(value[2:])
+		}
+	}
+	for _, iana := range options[dhcpv6OptIANA] {
+		if len(iana) < 12 {
+			continue
+		}
+		iaOptions, err := dhcpv6Options(iana[12:])
+		if err != nil {
+			return DHCPOffer{}, err
+		}
+		for _, value := range iaOptions[dhcpv6OptStatusCode] {
+			if len(value) >= 2 && binary.BigEndian.Uint16(value[0:2]) != dhcpv6StatusSuccess {
+				offer.Status = string(value[2:])
+			}
+		}
+		for _, value := range iaOptions[dhcpv6OptIAAddr] {
+			if len(value) < 24 || offer.IP != "" {
+				continue
+			}
+			// the prefix length is advertised by the routers
+			offer.IP = net.IP(value[0:16]).String()
+			offer.LeaseTime = time.Duration(binary.BigEndian.Uint32(value[20:24])) * time.Second
+		}
+	}
+	return offer, nil
+}
+
+// ProbeDHCP sends a DHCPv4 DISCOVER and a DHCPv6 SOLICIT from the
+// interface, and returns the offers received until the timeout. The
+// offers are never accepted, so that the configuration of the interface
+// is not changed and no lease is committed. Since the DISCOVER carries
+// the MAC address of the interface, a server may still hold the address
+// offered for a while, until it's requested or the offer expires. The
+// errors of each family are returned along with the offers of the other
+// one.
+func ProbeDHCP(ifaceName string, timeout time.Duration) ([]DHCPOffer, error) {
+	iface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return nil, err
+	}
+	if len(iface.HardwareAddr) < 4 {
+		return nil, fmt.Errorf("interface %s has no hardware address", ifaceName)
+	}
+
+	// both families are probed at the same time
+	var v6Offers []DHCPOffer
+	var v6Err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v6Offers, v6Err = probeDHCPv6(iface, timeout)
+		if v6Err != nil {
+			v6Err = fmt.Errorf("DHCPv6 probe failed: %w", v6Err)
+		}
+	}()
+	offers, v4Err := probeDHCPv4(iface, timeout)
+	if v4Err != nil {
+		v4Err = fmt.Errorf("DHCPv4 probe failed: %w", v4Err)
+	}
+	<-done
+
+	offers = append(offers, v6Offers...)
+	for i := range offers {
+		offers[i].Iface = ifaceName
+	}
+	return offers, errors.Join(v4Err, v6Err)
+}
+
+// dhcpSocket opens a packet socket sending and receiving the IP packets
+// of the given ethernet protocol on the interface. The probes are sent
+// with their IP and UDP headers, and the answers are filtered by port,
+// so that the port of the DHCP clients is not bound: the answers meant
+// for the DHCP client of the host, if any, are still delivered to it.
+func dhcpSocket(iface *net.Interface, proto uint16) (int, error) {
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, int(htons(proto)))
+	if err != nil {
+		return -1, err
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrLinklayer{Protocol: htons(proto), Ifindex: iface.Index}); err != nil {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("cannot bind to %s: %w", iface.Name, err)
+	}
+	tv := syscall.NsecToTimeval((100 * time.Millisecond).Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		syscall.Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+// dhcpv4Packet returns the IPv4 packet carrying msg from the port of the
+// DHCPv4 clients to the one of the servers. It's broadcast from the
+// unspecified address, since the interface may have no address.
+func dhcpv4Packet(msg []byte) []byte {
+	pkt := make([]byte, sizeofIPv4Header+sizeofUDPHeader, sizeofIPv4Header+sizeofUDPHeader+len(msg))
+	pkt[0] = 4<<4 | sizeofIPv4Header/4
+	binary.BigEndian.PutUint16(pkt[2:4], uint16(cap(pkt)))
+	pkt[8] = 64
+	pkt[9] = syscall.IPPROTO_UDP
+	copy(pkt[16:20], net.IPv4bcast.To4())
+	binary.BigEndian.PutUint16(pkt[10:12], inetChecksum(pkt[:sizeofIPv4Header]))
+
+	udp := pkt[sizeofIPv4Header:]
+	binary.BigEndian.PutUint16(udp[0:2], dhcpv4ClientPort)
+	binary.BigEndian.PutUint16(udp[2:4], dhcpv4ServerPort)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(sizeofUDPHeader+len(msg)))
+	// the UDP checksum is optional over IPv4
+	return append(pkt, msg...)
+}
+
+// dhcpv6Packet returns the IPv6 packet carrying msg from the port of the
+// DHCPv6 clients on the link-local address src to the servers
+func dhcpv6Packet(src net.IP, msg []byte) []byte {
+	pkt := make([]byte, sizeofIPv6Header+sizeofUDPHeader, sizeofIPv6Header+sizeofUDPHeader+len(msg))
+	pkt[0] = 6 << 4
+	binary.BigEndian.PutUint16(pkt[4:6], uint16(sizeofUDPHeader+len(msg)))
+	pkt[6] = syscall.IPPROTO_UDP
+	// the servers and relays are on the link
+	pkt[7] = 1
+	copy(pkt[8:24], src.To16())
+	copy(pkt[24:40], dhcpv6Servers)
+
+	udp := pkt[sizeofIPv6Header:]
+	binary.BigEndian.PutUint16(udp[0:2], dhcpv6ClientPort)
+	binary.BigEndian.PutUint16(udp[2:4], dhcpv6ServerPort)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(sizeofUDPHeader+len(msg)))
+	pkt = append(pkt, msg...)
+
+	// the UDP checksum is mandatory over IPv6, and covers the addresses,
+	// the length and the protocol of the pseudo-header
+	pseudo := append([]byte{}, pkt[8:40]...)
+	pseudo = binary.BigEndian.AppendUint32(pseudo, uint32(sizeofUDPHeader+len(msg)))
+	pseudo = append(pseudo, 0, 0, 0, syscall.IPPROTO_UDP)
+	sum := inetChecksum(append(pseudo, pkt[sizeofIPv6Header:]...))
+	if sum == 0 {
+		sum = 0xffff
+	}
+	binary.BigEndian.PutUint16(pkt[sizeofIPv6Header+6:sizeofIPv6Header+8], sum)
+	return pkt
+}
+
+// udpPayload returns the payload of an IPv4 or IPv6 packet carrying an
+// UDP datagram to the given port, and false for any other packet
+func udpPayload(pkt []byte, port uint16) ([]byte, bool) {
+	var udp []byte
+	switch {
+	case len(pkt) >= sizeofIPv4Header && pkt[0]>>4 == 4:
+		ihl := int(pkt[0]&0x0f) * 4
+		// the fragments are ignored, the answers fit in a packet
+		if pkt[9] != syscall.IPPROTO_UDP || ihl < sizeofIPv4Header || len(pkt) < ihl ||
+			binary.BigEndian.Uint16(pkt[6:8])&0x3fff != 0 {
+			return nil, false
+		}
+		udp = pkt[ihl:]
+	case len(pkt) >= sizeofIPv6Header && pkt[0]>>4 == 6:
+		// the answers of the servers have no extension header
+		if pkt[6] != syscall.IPPROTO_UDP {
+			return nil, false
+		}
+		udp = pkt[sizeofIPv6Header:]
+	default:
+		return nil, false
+	}
+	if len(udp) < sizeofUDPHeader || binary.BigEndian.Uint16(udp[2:4]) != port {
+		return nil, false
+	}
+	// the frames may be padded after the datagram
+	length := int(binary.BigEndian.Uint16(udp[4:6]))
+	if length < sizeofUDPHeader || length > len(udp) {
+		return nil, false
+	}
+	return udp[sizeofUDPHeader:length], true
+}
+
+// linkLocalAddress returns the IPv6 link-local address of the interface
+func linkLocalAddress(iface *net.Interface) (net.IP, error) {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() == nil && ipNet.IP.IsLinkLocalUnicast() {
+			return ipNet.IP, nil
+		}
+	}
+	return nil, fmt.Errorf("no IPv6 link-local address on %s", iface.Name)
+}
+
+// exchangeDHCP sends pkt periodically until the timeout, and returns the
+// distinct offers parsed from the answers received on port
+func exchangeDHCP(fd int, pkt []byte, to syscall.Sockaddr, port uint16, timeout time.Duration, parse func([]byte) (DHCPOffer, error)) ([]DHCPOffer, error) {
+	offers := []DHCPOffer{}
+	seen := map[string]bool{}
+	// as large as an IP packet can be
+	buf := make([]byte, 65536)
+	start := time.Now()
+	for sent := 0; time.Since(start) < timeout; {
+		if time.Since(start) >= time.Duration(sent)*dhcpRetransmit {
+			if err := syscall.Sendto(fd, pkt, 0, to); err != nil {
+				return nil, err
+			}
+			sent++
+		}
+		n, from, err := syscall.Recvfrom(fd, buf, 0)
+		if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ll, ok := from.(*syscall.SockaddrLinklayer); ok && ll.Pkttype == syscall.PACKET_OUTGOING {
+			continue
+		}
+		msg, ok := udpPayload(buf[:n], port)
+		if !ok {
+			continue
+		}
+		offer, err := parse(msg)
+		if err != nil {
+			continue
+		}
+		if key := offer.Server + " " + offer.IP; !seen[key] {
+			seen[key] = true
+			offers = append(offers, offer)
+		}
+	}
+	return offers, nil
+}
+
+func probeDHCPv4(iface *net.Interface, timeout time.Duration) ([]DHCPOffer, error) {
+	fd, err := dhcpSocket(iface, syscall.ETH_P_IP)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	xid := rand.Uint32()
+	to := &syscall.SockaddrLinklayer{Protocol: htons(syscall.ETH_P_IP), Ifindex: iface.Index, Halen: 6}
+	copy(to.Addr[:], ethBroadcast)
+	pkt := dhcpv4Packet(dhcpv4Discover(xid, iface.HardwareAddr))
+	return exchangeDHCP(fd, pkt, to, dhcpv4ClientPort, timeout, func(msg []byte) (DHCPOffer, error) {
+		return parseDHCPv4Offer(msg, xid)
+	})
+}
+
+func probeDHCPv6(iface *net.Interface, timeout time.Duration) ([]DHCPOffer, error) {
+	src, err := linkLocalAddress(iface)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := dhcpSocket(iface, syscall.ETH_P_IPV6)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	xid := rand.Uint32() & 0xffffff
+	to := &syscall.SockaddrLinklayer{Protocol: htons(syscall.ETH_P_IPV6), Ifindex: iface.Index, Halen: 6}
+	copy(to.Addr[:], dhcpv6ServersMAC)
+	pkt := dhcpv6Packet(src, dhcpv6Solicit(xid, iface.HardwareAddr))
+	return exchangeDHCP(fd, pkt, to, dhcpv6ClientPort, timeout, func(msg []byte) (DHCPOffer, error) {
+		return parseDHCPv6Advertise(msg, xid)
+	})
+}
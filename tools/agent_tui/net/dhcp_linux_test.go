@@ -0,0 +1,293 @@
+package net
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testDHCPMAC      = net.HardwareAddr{0x52, 0x54, 0x00, 0xaa, 0xbb, 0x01}
+	testDHCPv6Server = []byte{0, 3, 0, 1, 0x52, 0x54, 0x00, 0x99, 0x99, 0x99}
+)
+
+// dhcpv4TestOffer returns the DHCPOFFER of the DHCP stand-in
+func dhcpv4TestOffer(xid uint32, chaddr []byte) []byte {
+	msg := make([]byte, sizeofBOOTP+4)
+	msg[0] = bootReply
+	msg[1] = syscall.ARPHRD_ETHER
+	msg[2] = 6
+	binary.BigEndian.PutUint32(msg[4:8], xid)
+	binary.BigEndian.PutUint16(msg[10:12], bootpBroadcast)
+	copy(msg[16:20], net.ParseIP("10.99.0.50").To4())
+	copy(msg[28:34], chaddr)
+	binary.BigEndian.PutUint32(msg[sizeofBOOTP:sizeofBOOTP+4], dhcpMagicCookie)
+	msg = append(msg, dhcpOptMessageType, 1, dhcpMsgOffer)
+	msg = append(msg, dhcpOptServerID, 4, 10, 99, 0, 1)
+	msg = append(msg, dhcpOptSubnetMask, 4, 255, 255, 255, 0)
+	msg = append(msg, dhcpOptRouter, 4, 10, 99, 0, 1)
+	msg = append(msg, dhcpOptDNS, 8, 10, 99, 0, 53, 10, 99, 0, 54)
+	msg = append(msg, dhcpOptLeaseTime, 4, 0, 0, 0x0e, 0x10)
+	return append(msg, dhcpOptEnd)
+}
+
+// dhcpv6TestAdvertise returns the ADVERTISE of the DHCP stand-in
+func dhcpv6TestAdvertise(xid uint32, iaid []byte, status uint16) []byte {
+	option := func(msg []byte, code uint16, value []byte) []byte {
+		msg = binary.BigEndian.AppendUint16(msg, code)
+		msg = binary.BigEndian.AppendUint16(msg, uint16(len(value)))
+		return append(msg, value...)
+	}
+	msg := []byte{dhcpv6MsgAdvertise, byte(xid >> 16), byte(xid >> 8), byte(xid)}
+	msg = option(msg, dhcpv6OptServerID, testDHCPv6Server)
+	msg = option(msg, dhcpv6OptDNSServers, net.ParseIP("fd99::53"))
+	iana := append(append([]byte{}, iaid...), 0, 0, 0, 0, 0, 0, 0, 0)
+	if status == dhcpv6StatusSuccess {
+		iaaddr := append(net.ParseIP("fd99::50").To16(), 0, 0, 0x0e, 0x10, 0, 0, 0x1c, 0x20)
+		iana = option(iana, dhcpv6OptIAAddr, iaaddr)
+	} else {
+		iana = option(iana, dhcpv6OptStatusCode, append(binary.BigEndian.AppendUint16(nil, status), "no addresses available"...))
+	}
+	return option(msg, dhcpv6OptIANA, iana)
+}
+
+func TestDHCPv4Messages(t *testing.T) {
+	discover := dhcpv4Discover(0x12345678, testDHCPMAC)
+	assert.Len(t, discover, minDHCPv4Message)
+	assert.Equal(t, []byte{bootRequest, 1, 6, 0, 0x12, 0x34, 0x56, 0x78, 0, 0, 0x80, 0}, discover[0:12])
+	assert.Equal(t, []byte(testDHCPMAC), discover[28:34])
+	assert.Equal(t, []byte{0x63, 0x82, 0x53, 0x63, dhcpOptMessageType, 1, dhcpMsgDiscover}, discover[236:243])
+
+	offer, err := parseDHCPv4Offer(dhcpv4TestOffer(0x12345678, testDHCPMAC), 0x12345678)
+	assert.NoError(t, err)
+	assert.Equal(t, DHCPOffer{
+		Version:   4,
+		Server:    "10.99.0.1",
+		IP:        "10.99.0.50/24",
+		Gateway:   "10.99.0.1",
+		DNS:       []string{"10.99.0.53", "10.99.0.54"},
+		LeaseTime: time.Hour,
+	}, offer)
+	assert.Equal(t, "DHCPv4 server 10.99.0.1 offers 10.99.0.50/24, gateway 10.99.0.1, DNS 10.99.0.53 10.99.0.54, lease 1h0m0s", offer.String())
+
+	_, err = parseDHCPv4Offer(dhcpv4TestOffer(0x12345679, testDHCPMAC), 0x12345678)
+	assert.EqualError(t, err, "not an answer to the probe")
+	_, err = parseDHCPv4Offer(discover, 0x12345678)
+	assert.Error(t, err)
+	truncated := dhcpv4TestOffer(0x12345678, testDHCPMAC)
+	_, err = parseDHCPv4Offer(truncated[:len(truncated)-3], 0x12345678)
+	assert.EqualError(t, err, "option 51 truncated")
+}
+
+func TestDHCPv6Messages(t *testing.T) {
+	solicit := dhcpv6Solicit(0xabcdef, testDHCPMAC)
+	assert.Equal(t, []byte{dhcpv6MsgSolicit, 0xab, 0xcd, 0xef}, solicit[0:4])
+	options, err := dhcpv6Options(solicit[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{0, 3, 0, 1, 0x52, 0x54, 0x00, 0xaa, 0xbb, 0x01}}, options[dhcpv6OptClientID])
+	assert.Equal(t, [][]byte{{0, dhcpv6OptDNSServers}}, options[dhcpv6OptORO])
+	assert.Equal(t, [][]byte{{0x00, 0xaa, 0xbb, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}}, options[dhcpv6OptIANA])
+
+	offer, err := parseDHCPv6Advertise(dhcpv6TestAdvertise(0xabcdef, []byte{0x00, 0xaa, 0xbb, 0x01}, dhcpv6StatusSuccess), 0xabcdef)
+	assert.NoError(t, err)
+	assert.Equal(t, DHCPOffer{
+		Version:   6,
+		Server:    "00:03:00:01:52:54:00:99:99:99",
+		IP:        "fd99::50",
+		DNS:       []string{"fd99::53"},
+		LeaseTime: 2 * time.Hour,
+	}, offer)
+
+	offer, err = parseDHCPv6Advertise(dhcpv6TestAdvertise(0xabcdef, []byte{0x00, 0xaa, 0xbb, 0x01}, 2), 0xabcdef)
+	assert.NoError(t, err)
+	assert.Equal(t, "DHCPv6 server 00:03:00:01:52:54:00:99:99:99 offers no address (no addresses available), DNS fd99::53", offer.String())
+
+	_, err = parseDHCPv6Advertise(dhcpv6TestAdvertise(0xabcdee, nil, dhcpv6StatusSuccess), 0xabcdef)
+	assert.EqualError(t, err, "not an answer to the probe")
+	_, err = parseDHCPv6Advertise(solicit, 0xabcdef)
+	assert.Error(t, err)
+}
+
+// TestDHCPStandIn is the DHCP stand-in run by TestProbeDHCP in the
+// network namespace, answering the probes received on the interface
+// until it's killed
+func TestDHCPStandIn(t *testing.T) {
+	ifaceName := os.Getenv("AGENT_TUI_DHCP_STAND_IN")
+	if ifaceName == "" {
+		t.Skip("run by TestProbeDHCP")
+	}
+	iface, err := net.InterfaceByName(ifaceName)
+	assert.NoError(t, err)
+	v4, err := net.ListenUDP("udp4", &net.UDPAddr{Port: dhcpv4ServerPort})
+	assert.NoError(t, err)
+	raw, err := v4.SyscallConn()
+	assert.NoError(t, err)
+	raw.Control(func(fd uintptr) {
+		assert.NoError(t, syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1))
+		assert.NoError(t, syscall.BindToDevice(int(fd), ifaceName))
+	})
+	v6, err := net.ListenMulticastUDP("udp6", iface, &net.UDPAddr{IP: dhcpv6Servers, Port: dhcpv6ServerPort})
+	assert.NoError(t, err)
+	fmt.Println("ready")
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, _, err := v4.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if n < sizeofBOOTP || buf[0] != bootRequest {
+				continue
+			}
+			offer := dhcpv4TestOffer(binary.BigEndian.Uint32(buf[4:8]), buf[28:34])
+			v4.WriteToUDP(offer, &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4ClientPort})
+		}
+	}()
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := v6.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n < 4 || buf[0] != dhcpv6MsgSolicit {
+			continue
+		}
+		options, err := dhcpv6Options(buf[4:n])
+		if err != nil || len(options[dhcpv6OptIANA]) == 0 {
+			continue
+		}
+		xid := uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3])
+		v6.WriteToUDP(dhcpv6TestAdvertise(xid, options[dhcpv6OptIANA][0][0:4], dhcpv6StatusSuccess), from)
+	}
+}
+
+// startDHCPStandIn creates a veth pair, with the DHCP stand-in answering
+// on the peer in a network namespace, and returns the interface probed
+func startDHCPStandIn(t *testing.T) string {
+	if os.Geteuid() != 0 {
+		t.Skip("creating a network namespace needs root")
+	}
+	if _, err := exec.LookPath("ip"); err != nil {
+		t.Skip("the ip command is missing")
+	}
+
+	ns, client, server := fmt.Sprintf("agent-tui-dhcp-%d", os.Getpid()), "atuidhcp0", "atuidhcp1"
+	ip := func(args ...string) {
+		if output, err := exec.Command("ip", args...).CombinedOutput(); err != nil {
+			t.Fatalf("ip %v: %v: %s", args, err, output)
+		}
+	}
+	ip("netns", "add", ns)
+	t.Cleanup(func() {
+		exec.Command("ip", "link", "del", client).Run()
+		exec.Command("ip", "netns", "del", ns).Run()
+	})
+	ip("link", "add", client, "type", "veth", "peer", "name", server)
+	ip("link", "set", server, "netns", ns)
+	// the link-local addresses are usable right away without DAD
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("/proc/sys/net/ipv6/conf/%s/accept_dad", client), []byte("0"), 0644))
+	ip("netns", "exec", ns, "sysctl", "-qw", fmt.Sprintf("net.ipv6.conf.%s.accept_dad=0", server))
+	ip("-n", ns, "addr", "add", "10.99.0.1/24", "dev", server)
+	ip("-n", ns, "link", "set", server, "up")
+	ip("link", "set", client, "up")
+
+	standIn := exec.Command("ip", "netns", "exec", ns, os.Args[0], "-test.run=^TestDHCPStandIn$")
+	standIn.Env = append(os.Environ(), "AGENT_TUI_DHCP_STAND_IN="+server)
+	stdout, err := standIn.StdoutPipe()
+	assert.NoError(t, err)
+	assert.NoError(t, standIn.Start())
+	t.Cleanup(func() {
+		standIn.Process.Kill()
+		standIn.Wait()
+	})
+	ready := make([]byte, 5)
+	if _, err := io.ReadFull(stdout, ready); err != nil || string(ready) != "ready" {
+		t.Fatalf("the DHCP stand-in didn't start: %v", err)
+	}
+	// the link-local address may not be listed right away
+	assert.Eventually(t, func() bool {
+		iface, err := net.InterfaceByName(client)
+		if err != nil {
+			return false
+		}
+		_, err = linkLocalAddress(iface)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return client
+}
+
+func expectedTestOffers(iface string) []DHCPOffer {
+	return []DHCPOffer{
+		{Iface: iface, Version: 4, Server: "10.99.0.1", IP: "10.99.0.50/24", Gateway: "10.99.0.1", DNS: []string{"10.99.0.53", "10.99.0.54"}, LeaseTime: time.Hour},
+		{Iface: iface, Version: 6, Server: "00:03:00:01:52:54:00:99:99:99", IP: "fd99::50", DNS: []string{"fd99::53"}, LeaseTime: 2 * time.Hour},
+	}
+}
+
+func TestProbeDHCP(t *testing.T) {
+	client := startDHCPStandIn(t)
+
+	offers, err := ProbeDHCP(client, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTestOffers(client), offers)
+
+	// no address was configured by the probe
+	iface, err := net.InterfaceByName(client)
+	assert.NoError(t, err)
+	addrs, err := iface.Addrs()
+	assert.NoError(t, err)
+	for _, addr := range addrs {
+		assert.True(t, addr.(*net.IPNet).IP.IsLinkLocalUnicast(), addr.String())
+	}
+}
+
+// The probe must work along with the DHCP client of the host, which
+// keeps receiving the answers sent to its port
+func TestProbeDHCPWithClient(t *testing.T) {
+	client := startDHCPStandIn(t)
+
+	received := map[int]chan struct{}{}
+	for _, sock := range []struct {
+		family int
+		port   int
+		sa     syscall.Sockaddr
+	}{
+		{syscall.AF_INET, dhcpv4ClientPort, &syscall.SockaddrInet4{Port: dhcpv4ClientPort}},
+		{syscall.AF_INET6, dhcpv6ClientPort, &syscall.SockaddrInet6{Port: dhcpv6ClientPort}},
+	} {
+		// bound without SO_REUSEADDR, like the DHCP clients
+		fd, err := syscall.Socket(sock.family, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, syscall.IPPROTO_UDP)
+		assert.NoError(t, err)
+		t.Cleanup(func() { syscall.Close(fd) })
+		assert.NoError(t, syscall.BindToDevice(fd, client))
+		assert.NoError(t, syscall.Bind(fd, sock.sa))
+		tv := syscall.NsecToTimeval((3 * time.Second).Nanoseconds())
+		assert.NoError(t, syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv))
+		done := make(chan struct{})
+		received[sock.port] = done
+		go func() {
+			if n, _, err := syscall.Recvfrom(fd, make([]byte, 1500), 0); err == nil && n > 0 {
+				close(done)
+			}
+		}()
+	}
+
+	offers, err := ProbeDHCP(client, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTestOffers(client), offers)
+	for port, done := range received {
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+			t.Errorf("the client bound to port %d didn't receive the answer", port)
+		}
+	}
+}
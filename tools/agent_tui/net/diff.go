@@ -0,0 +1,190 @@
+package net
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a single difference between two network states. Path
+// identifies the changed item, for example interfaces/eth0/mtu or
+// dns/servers/192.168.111.1, and Old and New hold its values when
+// modified.
+type Change struct {
+	Kind ChangeKind
+	Path string
+	Old  string
+	New  string
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s", c.Path)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s", c.Path)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, c.Old, c.New)
+	}
+}
+
+// IfacePath returns the change path of an interface
+func IfacePath(name string) string {
+	return "interfaces/" + name
+}
+
+// RoutePath returns the change path of a route
+func RoutePath(route Route) string {
+	return fmt.Sprintf("routes/%s via %s dev %s", route.Destination, route.NextHopAddr, route.NextHopIface)
+}
+
+// Diff returns the semantic differences between two network states:
+// interfaces added or removed, their state, MTU, MAC address,
+// controller, bond and VLAN settings, IP settings and addresses, the
+// routes, the route rules and the DNS configuration
+func Diff(before, after NetState) []Change {
+	changes := []Change{}
+	modified := func(path, old, new string) {
+		if old != new {
+			changes = append(changes, Change{Kind: ChangeModified, Path: path, Old: old, New: new})
+		}
+	}
+
+	modified("hostname", before.Hostname.Running, after.Hostname.Running)
+
+	beforeIfaces := map[string]Iface{}
+	for _, iface := range before.Ifaces {
+		beforeIfaces[iface.Name] = iface
+	}
+	afterIfaces := map[string]Iface{}
+	for _, iface := range after.Ifaces {
+		afterIfaces[iface.Name] = iface
+	}
+	for _, name := range sortedKeys(beforeIfaces, afterIfaces) {
+		old, inBefore := beforeIfaces[name]
+		new, inAfter := afterIfaces[name]
+		path := IfacePath(name)
+		switch {
+		case !inBefore:
+			changes = append(changes, Change{Kind: ChangeAdded, Path: path})
+		case !inAfter:
+			changes = append(changes, Change{Kind: ChangeRemoved, Path: path})
+		default:
+			modified(path+"/type", old.Type, new.Type)
+			modified(path+"/state", old.State, new.State)
+			modified(path+"/mtu", strconv.Itoa(old.MTU), strconv.Itoa(new.MTU))
+			modified(path+"/mac-address", old.MAC, new.MAC)
+			modified(path+"/controller", old.Controller, new.Controller)
+			oldBond, newBond := LinkAggregation{}, LinkAggregation{}
+			if old.LinkAggregation != nil {
+				oldBond = *old.LinkAggregation
+			}
+			if new.LinkAggregation != nil {
+				newBond = *new.LinkAggregation
+			}
+			modified(path+"/link-aggregation/mode", oldBond.Mode, newBond.Mode)
+			changes = append(changes, diffSets(path+"/link-aggregation/port/", oldBond.Ports, newBond.Ports)...)
+			oldVLAN, newVLAN := VLAN{}, VLAN{}
+			if old.VLAN != nil {
+				oldVLAN = *old.VLAN
+			}
+			if new.VLAN != nil {
+				newVLAN = *new.VLAN
+			}
+			modified(path+"/vlan/base-iface", oldVLAN.BaseIface, newVLAN.BaseIface)
+			modified(path+"/vlan/id", strconv.Itoa(oldVLAN.ID), strconv.Itoa(newVLAN.ID))
+			for _, family := range []struct {
+				name     string
+				old, new IPConfig
+			}{
+				{"ipv4", old.IPv4, new.IPv4},
+				{"ipv6", old.IPv6, new.IPv6},
+			} {
+				familyPath := path + "/" + family.name
+				modified(familyPath+"/enabled", strconv.FormatBool(family.old.Enabled), strconv.FormatBool(family.new.Enabled))
+				modified(familyPath+"/dhcp", strconv.FormatBool(family.old.DHCP), strconv.FormatBool(family.new.DHCP))
+				modified(familyPath+"/autoconf", strconv.FormatBool(family.old.Autoconf), strconv.FormatBool(family.new.Autoconf))
+				changes = append(changes, diffSets(familyPath+"/address/", addressStrings(family.old.Addresses), addressStrings(family.new.Addresses))...)
+			}
+		}
+	}
+
+	oldRoutes := []string{}
+	for _, route := range before.Routes.Running {
+		oldRoutes = append(oldRoutes, RoutePath(route))
+	}
+	newRoutes := []string{}
+	for _, route := range after.Routes.Running {
+		newRoutes = append(newRoutes, RoutePath(route))
+	}
+	changes = append(changes, diffSets("", oldRoutes, newRoutes)...)
+	changes = append(changes, diffSets("route-rules/", ruleStrings(before.Rules()), ruleStrings(after.Rules()))...)
+	changes = append(changes, diffSets("dns/servers/", before.DNS.Running.Servers, after.DNS.Running.Servers)...)
+	changes = append(changes, diffSets("dns/search/", before.DNS.Running.SearchDomains, after.DNS.Running.SearchDomains)...)
+	return changes
+}
+
+// diffSets returns the items added and removed, with their paths
+// made of the prefix followed by the item
+func diffSets(prefix string, old, new []string) []Change {
+	changes := []Change{}
+	oldItems := map[string]bool{}
+	for _, item := range old {
+		oldItems[item] = true
+	}
+	newItems := map[string]bool{}
+	for _, item := range new {
+		newItems[item] = true
+	}
+	for _, item := range old {
+		if !newItems[item] {
+			changes = append(changes, Change{Kind: ChangeRemoved, Path: prefix + item})
+		}
+	}
+	for _, item := range new {
+		if !oldItems[item] {
+			changes = append(changes, Change{Kind: ChangeAdded, Path: prefix + item})
+		}
+	}
+	return changes
+}
+
+func ruleStrings(rules []RouteRule) []string {
+	items := []string{}
+	for _, rule := range rules {
+		items = append(items, rule.String())
+	}
+	return items
+}
+
+func addressStrings(addresses []net.IPNet) []string {
+	items := []string{}
+	for _, address := range addresses {
+		items = append(items, address.String())
+	}
+	return items
+}
+
+func sortedKeys(maps ...map[string]Iface) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
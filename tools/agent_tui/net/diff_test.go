@@ -0,0 +1,51 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := NetState{
+		Hostname: Hostname{Running: "master-0"},
+		DNS:      DNSResolver{Running: DNSConfig{Servers: []string{"192.168.111.1"}}},
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1"},
+		}},
+		Ifaces: []Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MTU: 1500, IPv4: IPConfig{Enabled: true, DHCP: true, Addresses: mustParseAddresses(t, "192.168.111.80/24")}},
+			{Name: "eth1", Type: "ethernet", State: "up", MTU: 1500},
+		},
+	}
+	after := NetState{
+		Hostname: Hostname{Running: "master-0"},
+		DNS:      DNSResolver{Running: DNSConfig{Servers: []string{"192.168.111.1", "192.168.111.2"}, SearchDomains: []string{"example.com"}}},
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.254"},
+		}},
+		Ifaces: []Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MTU: 9000, IPv4: IPConfig{Enabled: true, Addresses: mustParseAddresses(t, "192.168.111.81/24")}},
+			{Name: "eth0.10", Type: "vlan", State: "up", MTU: 1500},
+		},
+	}
+
+	changes := []string{}
+	for _, change := range Diff(before, after) {
+		changes = append(changes, change.String())
+	}
+	assert.Equal(t, []string{
+		"~ interfaces/eth0/mtu: 1500 -> 9000",
+		"~ interfaces/eth0/ipv4/dhcp: true -> false",
+		"- interfaces/eth0/ipv4/address/192.168.111.80/24",
+		"+ interfaces/eth0/ipv4/address/192.168.111.81/24",
+		"+ interfaces/eth0.10",
+		"- interfaces/eth1",
+		"- routes/0.0.0.0/0 via 192.168.111.1 dev eth0",
+		"+ routes/0.0.0.0/0 via 192.168.111.254 dev eth0",
+		"+ dns/servers/192.168.111.2",
+		"+ dns/search/example.com",
+	}, changes)
+
+	assert.Empty(t, Diff(before, before))
+}
@@ -0,0 +1,165 @@
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultMTU = 1500
+
+	// ExportFileName is the default name of the exported settings
+	ExportFileName = "agent-config-network.yaml"
+)
+
+// HostInterface is an item of the interfaces list of an
+// agent-config.yaml host, mapping a name to its MAC address
+type HostInterface struct {
+	Name       string `json:"name"`
+	MACAddress string `json:"macAddress"`
+}
+
+// ExportedHost contains the network settings of an agent-config.yaml
+// host, equivalent to the current network configuration
+type ExportedHost struct {
+	Interfaces    []HostInterface `json:"interfaces,omitempty"`
+	NetworkConfig DesiredState    `json:"networkConfig"`
+}
+
+// Export converts the network state to the settings of an
+// agent-config.yaml host. Only the named interfaces are exported or,
+// when no name is specified, the interfaces up with IP enabled, along
+// with the ports of the bonds and the base interfaces of the VLANs. The
+// families configured through DHCP are exported as such, while the
+// static ones include their addresses, default routes and the DNS
+// servers.
+func Export(ns NetState, names []string) (ExportedHost, error) {
+	host := ExportedHost{}
+	ifaces, err := exportedIfaces(ns, names)
+	if err != nil {
+		return host, err
+	}
+
+	routes := []DesiredRoute{}
+	static := false
+	for _, iface := range ifaces {
+		settings := NewIfaceSettings(ns, iface)
+		desired := DesiredIface{
+			Name:            iface.Name,
+			Type:            iface.Type,
+			State:           "up",
+			IPv4:            desiredIPConfig(settings.IPv4, false),
+			IPv6:            desiredIPConfig(settings.IPv6, true),
+			LinkAggregation: iface.LinkAggregation,
+			VLAN:            iface.VLAN,
+		}
+		if iface.MTU != defaultMTU {
+			desired.MTU = iface.MTU
+		}
+		host.NetworkConfig.Interfaces = append(host.NetworkConfig.Interfaces, desired)
+
+		for _, family := range []struct {
+			settings    IPSettings
+			destination string
+		}{
+			{settings.IPv4, ipv4DefaultDestination},
+			{settings.IPv6, ipv6DefaultDestination},
+		} {
+			if !family.settings.Enabled || family.settings.DHCP {
+				continue
+			}
+			static = true
+			if family.settings.Gateway != nil {
+				routes = append(routes, DesiredRoute{
+					Destination:  family.destination,
+					NextHopIface: iface.Name,
+					NextHopAddr:  family.settings.Gateway.String(),
+				})
+			}
+		}
+
+		if iface.Type == "ethernet" && iface.MAC != "" {
+			host.Interfaces = append(host.Interfaces, HostInterface{
+				Name:       iface.Name,
+				MACAddress: strings.ToLower(iface.MAC),
+			})
+		}
+	}
+
+	if len(routes) > 0 {
+		host.NetworkConfig.Routes = &DesiredRoutes{Config: routes}
+	}
+	// with DHCP the DNS servers are provided by the DHCP server too
+	if static && len(ns.DNS.Running.Servers) > 0 {
+		host.NetworkConfig.DNS = &DesiredDNS{Config: ns.DNS.Running}
+	}
+	return host, nil
+}
+
+func exportedIfaces(ns NetState, names []string) ([]Iface, error) {
+	ifaces := []Iface{}
+	if len(names) == 0 {
+		for _, iface := range ns.Ifaces {
+			if iface.Type != "loopback" && iface.State == "up" && (iface.IPv4.Enabled || iface.IPv6.Enabled) {
+				ifaces = append(ifaces, iface)
+			}
+		}
+		if len(ifaces) == 0 {
+			return nil, fmt.Errorf("no interface is up with IP enabled")
+		}
+		return withLowerIfaces(ns, ifaces)
+	}
+
+	for _, name := range names {
+		iface := ns.getIfaceByName(name)
+		if iface == nil {
+			return nil, fmt.Errorf("interface %s not found", name)
+		}
+		ifaces = append(ifaces, *iface)
+	}
+	return withLowerIfaces(ns, ifaces)
+}
+
+// withLowerIfaces appends the ports of the bonds and the base interfaces
+// of the VLANs, which usually have no IP enabled, since the exported
+// configuration is not valid without them
+func withLowerIfaces(ns NetState, ifaces []Iface) ([]Iface, error) {
+	exported := map[string]bool{}
+	for _, iface := range ifaces {
+		exported[iface.Name] = true
+	}
+	// the appended interfaces can be controllers too, e.g. a bond under
+	// a VLAN
+	for i := 0; i < len(ifaces); i++ {
+		lower := []string{}
+		if ifaces[i].LinkAggregation != nil {
+			lower = append(lower, ifaces[i].LinkAggregation.Ports...)
+		}
+		if ifaces[i].VLAN != nil {
+			lower = append(lower, ifaces[i].VLAN.BaseIface)
+		}
+		for _, name := range lower {
+			if exported[name] {
+				continue
+			}
+			iface := ns.getIfaceByName(name)
+			if iface == nil {
+				return nil, fmt.Errorf("interface %s of %s not found", name, ifaces[i].Name)
+			}
+			exported[name] = true
+			ifaces = append(ifaces, *iface)
+		}
+	}
+	return ifaces, nil
+}
+
+// YAML returns the host settings as a YAML snippet, ready to be
+// pasted in a host of agent-config.yaml
+func (h ExportedHost) YAML() ([]byte, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return JSONToYAML(data)
+}
@@ -0,0 +1,156 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	ns := NetState{
+		DNS: DNSResolver{Running: DNSConfig{Servers: []string{"192.168.111.1"}}},
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1"},
+			{Destination: "192.168.111.0/24", NextHopIface: "eth0"},
+		}},
+		Ifaces: []Iface{
+			{Name: "lo", Type: "loopback", State: "up", MTU: 65536, IPv4: IPConfig{Enabled: true, Addresses: mustParseAddresses(t, "127.0.0.1/8")}},
+			{Name: "eth0", Type: "ethernet", State: "up", MAC: "52:54:00:AA:BB:01", MTU: 1500,
+				IPv4: IPConfig{Enabled: true, Addresses: mustParseAddresses(t, "192.168.111.80/24")},
+				IPv6: IPConfig{Enabled: true, Addresses: mustParseAddresses(t, "fe80::1/64")}},
+			{Name: "eth1", Type: "ethernet", State: "up", MAC: "52:54:00:aa:bb:02", MTU: 9000,
+				IPv4: IPConfig{Enabled: true, DHCP: true, Addresses: mustParseAddresses(t, "10.0.0.5/24")}},
+			{Name: "eth2", Type: "ethernet", State: "down", MAC: "52:54:00:aa:bb:03", MTU: 1500},
+		},
+	}
+
+	host, err := Export(ns, nil)
+	assert.NoError(t, err)
+	data, err := host.YAML()
+	assert.NoError(t, err)
+	assert.Equal(t, `interfaces:
+    - name: eth0
+      macAddress: 52:54:00:aa:bb:01
+    - name: eth1
+      macAddress: 52:54:00:aa:bb:02
+networkConfig:
+    interfaces:
+        - name: eth0
+          type: ethernet
+          state: up
+          ipv4:
+            enabled: true
+            dhcp: false
+            address:
+                - ip: 192.168.111.80
+                  prefix-length: 24
+          ipv6:
+            enabled: true
+            dhcp: false
+            autoconf: false
+        - name: eth1
+          type: ethernet
+          state: up
+          mtu: 9000
+          ipv4:
+            enabled: true
+            dhcp: true
+          ipv6:
+            enabled: false
+    routes:
+        config:
+            - destination: 0.0.0.0/0
+              next-hop-interface: eth0
+              next-hop-address: 192.168.111.1
+    dns-resolver:
+        config:
+            server:
+                - 192.168.111.1
+`, string(data))
+
+	host, err = Export(ns, []string{"eth1"})
+	assert.NoError(t, err)
+	assert.Len(t, host.NetworkConfig.Interfaces, 1)
+	assert.Nil(t, host.NetworkConfig.Routes)
+	assert.Nil(t, host.NetworkConfig.DNS)
+
+	_, err = Export(ns, []string{"eth5"})
+	assert.EqualError(t, err, "interface eth5 not found")
+}
+
+func TestExportBondVLAN(t *testing.T) {
+	ns := NetState{
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "bond0.100", NextHopAddr: "192.168.111.1"},
+		}},
+		Ifaces: []Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MAC: "52:54:00:aa:bb:01", MTU: 1500, Controller: "bond0"},
+			{Name: "eth1", Type: "ethernet", State: "up", MAC: "52:54:00:aa:bb:02", MTU: 1500, Controller: "bond0"},
+			{Name: "bond0", Type: "bond", State: "up", MAC: "52:54:00:aa:bb:01", MTU: 1500,
+				LinkAggregation: &LinkAggregation{Mode: "active-backup", Ports: []string{"eth0", "eth1"}}},
+			{Name: "bond0.100", Type: "vlan", State: "up", MTU: 1500,
+				IPv4: IPConfig{Enabled: true, Addresses: mustParseAddresses(t, "192.168.111.80/24")},
+				VLAN: &VLAN{BaseIface: "bond0", ID: 100}},
+		},
+	}
+
+	for _, names := range [][]string{nil, {"bond0.100"}} {
+		host, err := Export(ns, names)
+		assert.NoError(t, err)
+		data, err := host.YAML()
+		assert.NoError(t, err)
+		assert.Equal(t, `interfaces:
+    - name: eth0
+      macAddress: 52:54:00:aa:bb:01
+    - name: eth1
+      macAddress: 52:54:00:aa:bb:02
+networkConfig:
+    interfaces:
+        - name: bond0.100
+          type: vlan
+          state: up
+          ipv4:
+            enabled: true
+            dhcp: false
+            address:
+                - ip: 192.168.111.80
+                  prefix-length: 24
+          ipv6:
+            enabled: false
+          vlan:
+            base-iface: bond0
+            id: 100
+        - name: bond0
+          type: bond
+          state: up
+          ipv4:
+            enabled: false
+          ipv6:
+            enabled: false
+          link-aggregation:
+            mode: active-backup
+            port:
+                - eth0
+                - eth1
+        - name: eth0
+          type: ethernet
+          state: up
+          ipv4:
+            enabled: false
+          ipv6:
+            enabled: false
+        - name: eth1
+          type: ethernet
+          state: up
+          ipv4:
+            enabled: false
+          ipv6:
+            enabled: false
+    routes:
+        config:
+            - destination: 0.0.0.0/0
+              next-hop-interface: bond0.100
+              next-hop-address: 192.168.111.1
+`, string(data))
+	}
+}
@@ -0,0 +1,36 @@
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FixtureProvider reads the network state from a file, in the YAML or
+// JSON format of the nmstate output, for example saved with
+// "agent-tui netstate --output yaml". The file is read at each
+// retrieval, so it can be edited while agent-tui is running. It's
+// meant for the tests and the demos, so no changes can be applied.
+type FixtureProvider struct {
+	Path string
+}
+
+func (FixtureProvider) Name() string {
+	return ProviderFixture
+}
+
+func (p FixtureProvider) RetrieveRaw() ([]byte, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the network state fixture: %w", err)
+	}
+
+	// JSON is a subset of YAML
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid network state fixture %s: %w", p.Path, err)
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,261 @@
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The files looked up when importing a nmstate document, and the
+// depth of the directories searched
+var (
+	ImportFileExtensions = []string{".yaml", ".yml", ".json"}
+	ImportSearchDepth    = 2
+)
+
+// The top level keys of a nmstate document
+var nmstateKeys = map[string]bool{
+	"interfaces":   true,
+	"routes":       true,
+	"route-rules":  true,
+	"dns-resolver": true,
+	"hostname":     true,
+	"ovs-db":       true,
+	"ovn":          true,
+}
+
+// ImportedState is a nmstate document to be applied as is
+type ImportedState struct {
+	// JSON is the document converted to JSON, as accepted by nmstate
+	JSON json.RawMessage
+	doc  importedDoc
+}
+
+// The following types describe the subset of the imported document
+// used to predict its effect on the network state
+
+type importedDoc struct {
+	Interfaces []importedIface `json:"interfaces"`
+	Routes     *DesiredRoutes  `json:"routes"`
+	DNS        *DesiredDNS     `json:"dns-resolver"`
+}
+
+type importedIface struct {
+	Name            string           `json:"name"`
+	Type            string           `json:"type"`
+	State           string           `json:"state"`
+	MTU             int              `json:"mtu"`
+	IPv4            *IPConfig        `json:"ipv4"`
+	IPv6            *IPConfig        `json:"ipv6"`
+	LinkAggregation *LinkAggregation `json:"link-aggregation"`
+	VLAN            *VLAN            `json:"vlan"`
+}
+
+// ParseNMState parses and validates a nmstate document in YAML or
+// JSON format. The networkConfig of an agent-config.yaml host, as
+// generated by Export, is accepted too.
+func ParseNMState(data []byte) (ImportedState, error) {
+	imported := ImportedState{}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return imported, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if networkConfig, found := doc["networkConfig"]; found {
+		var ok bool
+		if doc, ok = networkConfig.(map[string]interface{}); !ok {
+			return imported, fmt.Errorf("networkConfig must be a mapping")
+		}
+	}
+	if len(doc) == 0 {
+		return imported, fmt.Errorf("the document is empty")
+	}
+	unknown := []string{}
+	for key := range doc {
+		if !nmstateKeys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return imported, fmt.Errorf("unknown nmstate keys: %s", strings.Join(unknown, ", "))
+	}
+
+	var err error
+	imported.JSON, err = json.Marshal(doc)
+	if err != nil {
+		return imported, err
+	}
+	if err := json.Unmarshal(imported.JSON, &imported.doc); err != nil {
+		return imported, fmt.Errorf("invalid nmstate document: %w", err)
+	}
+	return imported, imported.validate()
+}
+
+func (s ImportedState) validate() error {
+	errs := []string{}
+	for i, iface := range s.doc.Interfaces {
+		if iface.Name == "" {
+			errs = append(errs, fmt.Sprintf("interface %d has no name", i+1))
+		}
+	}
+	if s.doc.Routes != nil {
+		for _, route := range s.doc.Routes.Config {
+			if route.Destination == "" && route.State == "absent" {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(route.Destination); err != nil {
+				errs = append(errs, fmt.Sprintf("route destination %q is not a valid subnet", route.Destination))
+			}
+			if route.NextHopAddr != "" && net.ParseIP(route.NextHopAddr) == nil {
+				errs = append(errs, fmt.Sprintf("route next hop %q is not a valid IP address", route.NextHopAddr))
+			}
+		}
+	}
+	if s.doc.DNS != nil {
+		for _, server := range s.doc.DNS.Config.Servers {
+			if net.ParseIP(server) == nil {
+				errs = append(errs, fmt.Sprintf("DNS server %q is not a valid IP address", server))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// Predict returns the expected network state once the document is
+// applied to the current one. Since the DHCP leases can't be known
+// in advance, the current addresses are kept for the families using
+// DHCP.
+func (s ImportedState) Predict(current NetState) NetState {
+	predicted := current
+	predicted.Ifaces = append([]Iface{}, current.Ifaces...)
+
+	for _, imported := range s.doc.Interfaces {
+		index := -1
+		for i := range predicted.Ifaces {
+			if predicted.Ifaces[i].Name == imported.Name {
+				index = i
+			}
+		}
+		if imported.State == "absent" {
+			if index >= 0 {
+				predicted.Ifaces = append(predicted.Ifaces[:index], predicted.Ifaces[index+1:]...)
+			}
+			continue
+		}
+		if index < 0 {
+			predicted.Ifaces = append(predicted.Ifaces, Iface{Name: imported.Name, State: "up"})
+			index = len(predicted.Ifaces) - 1
+		}
+
+		iface := &predicted.Ifaces[index]
+		if imported.Type != "" {
+			iface.Type = imported.Type
+		}
+		if imported.State != "" {
+			iface.State = imported.State
+		}
+		if imported.MTU != 0 {
+			iface.MTU = imported.MTU
+		}
+		if imported.IPv4 != nil {
+			iface.IPv4 = predictIPConfig(iface.IPv4, *imported.IPv4)
+		}
+		if imported.IPv6 != nil {
+			iface.IPv6 = predictIPConfig(iface.IPv6, *imported.IPv6)
+		}
+		if imported.LinkAggregation != nil {
+			iface.LinkAggregation = imported.LinkAggregation
+		}
+		if imported.VLAN != nil {
+			iface.VLAN = imported.VLAN
+		}
+	}
+
+	// the ports of the bonds are controlled by them
+	for _, imported := range s.doc.Interfaces {
+		if imported.LinkAggregation == nil {
+			continue
+		}
+		for i := range predicted.Ifaces {
+			iface := &predicted.Ifaces[i]
+			isPort := false
+			for _, port := range imported.LinkAggregation.Ports {
+				isPort = isPort || port == iface.Name
+			}
+			if isPort {
+				iface.Controller = imported.Name
+			} else if iface.Controller == imported.Name {
+				iface.Controller = ""
+			}
+		}
+	}
+
+	if s.doc.Routes != nil {
+		routes := []Route{}
+		for _, route := range current.Routes.Running {
+			if !s.removesRoute(route) {
+				routes = append(routes, route)
+			}
+		}
+		for _, route := range s.doc.Routes.Config {
+			added := Route{
+				Destination:  route.Destination,
+				NextHopIface: route.NextHopIface,
+				NextHopAddr:  route.NextHopAddr,
+				Metric:       route.Metric,
+				TableID:      route.TableID,
+			}
+			if route.State != "absent" && !containsRoute(routes, added) {
+				routes = append(routes, added)
+			}
+		}
+		predicted.Routes.Running = routes
+	}
+
+	if s.doc.DNS != nil {
+		predicted.DNS.Running = s.doc.DNS.Config
+	}
+	return predicted
+}
+
+func predictIPConfig(current, imported IPConfig) IPConfig {
+	if imported.Enabled && (imported.DHCP || imported.Autoconf) {
+		imported.Addresses = current.Addresses
+	}
+	return imported
+}
+
+// removesRoute checks whether an absent route of the document matches
+// the route. The fields not specified match any value.
+func (s ImportedState) removesRoute(route Route) bool {
+	for _, absent := range s.doc.Routes.Config {
+		if absent.State != "absent" {
+			continue
+		}
+		if (absent.Destination == "" || absent.Destination == route.Destination) &&
+			(absent.NextHopIface == "" || absent.NextHopIface == route.NextHopIface) &&
+			(absent.NextHopAddr == "" || absent.NextHopAddr == route.NextHopAddr) &&
+			(absent.Metric == 0 || absent.Metric == route.Metric) &&
+			(absent.TableID == 0 || absent.TableID == route.TableID) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRoute(routes []Route, route Route) bool {
+	for _, r := range routes {
+		if r == route {
+			return true
+		}
+	}
+	return false
+}
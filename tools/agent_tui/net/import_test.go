@@ -0,0 +1,146 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNMState(t *testing.T) {
+	cases := []struct {
+		name          string
+		data          string
+		expectedJSON  string
+		expectedError string
+	}{
+		{
+			name: "nmstate document",
+			data: `interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+  mtu: 9000
+`,
+			expectedJSON: `{"interfaces":[{"name":"eth0","type":"ethernet","state":"up","mtu":9000}]}`,
+		},
+		{
+			name: "agent-config.yaml host",
+			data: `interfaces:
+- name: eth0
+  macAddress: 52:54:00:aa:bb:01
+networkConfig:
+  dns-resolver:
+    config:
+      server:
+      - 192.168.111.1
+`,
+			expectedJSON: `{"dns-resolver":{"config":{"server":["192.168.111.1"]}}}`,
+		},
+		{
+			name:          "empty document",
+			data:          "",
+			expectedError: "the document is empty",
+		},
+		{
+			name:          "unknown keys",
+			data:          "hosts: []\nfoo: bar\ninterfaces: []\n",
+			expectedError: "unknown nmstate keys: foo, hosts",
+		},
+		{
+			name:          "invalid YAML",
+			data:          "interfaces: [",
+			expectedError: "invalid YAML",
+		},
+		{
+			name: "invalid values",
+			data: `interfaces:
+- type: ethernet
+routes:
+  config:
+  - destination: 0.0.0.0
+    next-hop-address: 192.168.111.1
+  - state: absent
+    next-hop-interface: eth0
+dns-resolver:
+  config:
+    server:
+    - dns.example.com
+`,
+			expectedError: "interface 1 has no name\n" +
+				"route destination \"0.0.0.0\" is not a valid subnet\n" +
+				"DNS server \"dns.example.com\" is not a valid IP address",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			imported, err := ParseNMState([]byte(tc.data))
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expectedJSON, string(imported.JSON))
+		})
+	}
+}
+
+func TestPredict(t *testing.T) {
+	current := NetState{
+		DNS: DNSResolver{Running: DNSConfig{Servers: []string{"192.168.111.1"}}},
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1"},
+		}},
+		Ifaces: []Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MTU: 1500, IPv4: IPConfig{Enabled: true, DHCP: true, Addresses: mustParseAddresses(t, "192.168.111.80/24")}},
+			{Name: "eth1", Type: "ethernet", State: "up", MTU: 1500, IPv4: IPConfig{Enabled: true, DHCP: true, Addresses: mustParseAddresses(t, "10.0.0.5/24")}},
+		},
+	}
+	imported, err := ParseNMState([]byte(`interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: 192.168.111.81
+      prefix-length: 24
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 9000
+  ipv4:
+    enabled: true
+    dhcp: true
+- name: eth2
+  state: absent
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    state: absent
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth0
+    next-hop-address: 192.168.111.254
+dns-resolver:
+  config:
+    server:
+    - 192.168.111.2
+`))
+	assert.NoError(t, err)
+
+	changes := []string{}
+	for _, change := range Diff(current, imported.Predict(current)) {
+		changes = append(changes, change.String())
+	}
+	assert.Equal(t, []string{
+		"~ interfaces/eth0/ipv4/dhcp: true -> false",
+		"- interfaces/eth0/ipv4/address/192.168.111.80/24",
+		"+ interfaces/eth0/ipv4/address/192.168.111.81/24",
+		"~ interfaces/eth1/mtu: 1500 -> 9000",
+		"- routes/0.0.0.0/0 via 192.168.111.1 dev eth0",
+		"+ routes/0.0.0.0/0 via 192.168.111.254 dev eth0",
+		"- dns/servers/192.168.111.1",
+		"+ dns/servers/192.168.111.2",
+	}, changes)
+	assert.Equal(t, 1500, current.Ifaces[1].MTU, "the current state must not be modified")
+}
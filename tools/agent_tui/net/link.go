@@ -0,0 +1,111 @@
+package net
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The sysfs directory of the network interfaces, replaced by the tests
+var sysClassNetPath = "/sys/class/net"
+
+// NIC is a physical network interface
+type NIC struct {
+	Name    string
+	MAC     string
+	Carrier bool
+}
+
+// PhysicalNICs returns the ethernet interfaces backed by a device, that
+// can be used as bond ports, with their link status
+func PhysicalNICs(ns NetState) []NIC {
+	nics := []NIC{}
+	for _, iface := range ns.Ifaces {
+		if iface.Type != "ethernet" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(sysClassNetPath, iface.Name, "device")); err != nil {
+			continue
+		}
+		carrier, _ := LinkCarrier(iface.Name)
+		nics = append(nics, NIC{
+			Name:    iface.Name,
+			MAC:     strings.ToLower(iface.MAC),
+			Carrier: carrier,
+		})
+	}
+	return nics
+}
+
+// The interface types created by software, rather than backed by a
+// device. Bonds, teams and VLANs are not included, since they carry
+// the traffic of the host.
+var virtualIfaceTypes = map[string]bool{
+	"dummy":         true,
+	"ipvlan":        true,
+	"linux-bridge":  true,
+	"loopback":      true,
+	"macsec":        true,
+	"mac-vlan":      true,
+	"mac-vtap":      true,
+	"ovs-bridge":    true,
+	"ovs-interface": true,
+	"tun":           true,
+	"veth":          true,
+	"vrf":           true,
+	"vxlan":         true,
+}
+
+// IsVirtual checks whether the interface is created by software, like
+// bridges, veths or tunnels, or is a SR-IOV virtual function. The
+// ethernet interfaces without a device, like the veths reported by the
+// older nmstate versions, are virtual too.
+func IsVirtual(iface Iface) bool {
+	if virtualIfaceTypes[iface.Type] {
+		return true
+	}
+	if iface.Type != "ethernet" {
+		return false
+	}
+	device := filepath.Join(sysClassNetPath, iface.Name, "device")
+	if _, err := os.Stat(device); err != nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(device, "physfn"))
+	return err == nil
+}
+
+// LinkCarrier checks whether the link of the interface is up. An
+// interface administratively down has no carrier.
+func LinkCarrier(name string) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(sysClassNetPath, name, "carrier"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, fmt.Errorf("interface %s not found", name)
+		}
+		// reading the carrier of an interface down fails with EINVAL
+		return false, nil
+	}
+	return strings.TrimSpace(string(data)) == "1", nil
+}
+
+// WaitForCarrier polls the link of the interface until it's up or the
+// timeout expires
+func WaitForCarrier(name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		carrier, err := LinkCarrier(name)
+		if carrier {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("interface %s has no carrier after %s", name, timeout)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
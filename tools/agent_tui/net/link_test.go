@@ -0,0 +1,56 @@
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhysicalNICs(t *testing.T) {
+	sysClassNetPath = t.TempDir()
+	defer func() { sysClassNetPath = "/sys/class/net" }()
+
+	for name, carrier := range map[string]string{"eth0": "1\n", "eth1": "0\n", "bond0": "1\n"} {
+		dir := filepath.Join(sysClassNetPath, name)
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "carrier"), []byte(carrier), 0644))
+		if name != "bond0" {
+			assert.NoError(t, os.MkdirAll(filepath.Join(dir, "device"), 0755))
+		}
+	}
+	ns := NetState{Ifaces: []Iface{
+		{Name: "lo", Type: "loopback"},
+		{Name: "eth0", Type: "ethernet", MAC: "52:54:00:AA:BB:01"},
+		{Name: "eth1", Type: "ethernet", MAC: "52:54:00:AA:BB:02"},
+		{Name: "bond0", Type: "bond"},
+		{Name: "veth0", Type: "ethernet"},
+	}}
+
+	assert.Equal(t, []NIC{
+		{Name: "eth0", MAC: "52:54:00:aa:bb:01", Carrier: true},
+		{Name: "eth1", MAC: "52:54:00:aa:bb:02", Carrier: false},
+	}, PhysicalNICs(ns))
+
+	assert.NoError(t, WaitForCarrier("bond0", time.Second))
+	assert.EqualError(t, WaitForCarrier("eth1", 0), "interface eth1 has no carrier after 0s")
+	assert.EqualError(t, WaitForCarrier("eth5", 0), "interface eth5 not found")
+}
+
+func TestIsVirtual(t *testing.T) {
+	sysClassNetPath = t.TempDir()
+	defer func() { sysClassNetPath = "/sys/class/net" }()
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(sysClassNetPath, "eth0", "device"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(sysClassNetPath, "eth0v0", "device", "physfn"), 0755))
+
+	assert.False(t, IsVirtual(Iface{Name: "eth0", Type: "ethernet"}))
+	assert.False(t, IsVirtual(Iface{Name: "bond0", Type: "bond"}))
+	assert.False(t, IsVirtual(Iface{Name: "bond0.10", Type: "vlan"}))
+	assert.True(t, IsVirtual(Iface{Name: "eth0v0", Type: "ethernet"}), "SR-IOV virtual function")
+	assert.True(t, IsVirtual(Iface{Name: "veth1234", Type: "ethernet"}), "ethernet without a device")
+	assert.True(t, IsVirtual(Iface{Name: "br0", Type: "linux-bridge"}))
+	assert.True(t, IsVirtual(Iface{Name: "lo", Type: "loopback"}))
+}
@@ -0,0 +1,337 @@
+package net
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// The resolver configuration compared with the nmstate DNS settings,
+// replaced by the tests
+var resolvConfPath = "/etc/resolv.conf"
+
+// resolvConfMaxServers is the number of name servers used by the glibc
+// resolver, MAXNS, which ignores the following ones
+const resolvConfMaxServers = 3
+
+// Severity tells how likely a finding is to break the installation
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Finding is a problem detected in the network state. Path identifies
+// the item the finding refers to, with the same format of the change
+// paths.
+type Finding struct {
+	Severity Severity
+	Path     string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Severity, f.Message)
+}
+
+// HasErrors returns true if any of the findings is an error
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint analyzes the network state looking for the common causes of a
+// failed installation: a missing default route, DNS servers not
+// reachable by any route, interfaces up without carrier, addresses
+// assigned to several interfaces, a localhost hostname, bond ports and
+// VLANs with an MTU different from their controller or base interface,
+// and a resolver configuration not matching the nmstate one
+func Lint(ns NetState) []Finding {
+	findings := []Finding{}
+	findings = append(findings, lintDefaultRoute(ns)...)
+	findings = append(findings, lintDNSReachable(ns)...)
+	findings = append(findings, lintCarrier(ns)...)
+	findings = append(findings, lintDuplicateAddresses(ns)...)
+	findings = append(findings, lintHostname(ns)...)
+	findings = append(findings, lintMTU(ns)...)
+	findings = append(findings, lintResolvConf(ns)...)
+	return findings
+}
+
+func lintDefaultRoute(ns NetState) []Finding {
+	for _, family := range Families {
+		if ns.PrimaryDefaultRoute(family) != nil {
+			return nil
+		}
+	}
+	return []Finding{{
+		Severity: SeverityError,
+		Path:     "routes",
+		Message:  "No default route, only the directly connected networks are reachable",
+	}}
+}
+
+func lintDNSReachable(ns NetState) []Finding {
+	findings := []Finding{}
+	for _, server := range ns.DNS.Running.Servers {
+		// a link-local server carries the interface it's reachable from
+		if strings.Contains(server, "%") {
+			continue
+		}
+		ip := net.ParseIP(server)
+		if ip == nil || ns.reachable(ip) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Path:     "dns/servers/" + server,
+			Message:  fmt.Sprintf("DNS server %s is not reachable by any route", server),
+		})
+	}
+	return findings
+}
+
+// reachable checks whether ip belongs to the network of an address of
+// an interface up, or to the destination of a route of the main table
+func (ns *NetState) reachable(ip net.IP) bool {
+	for _, iface := range ns.Ifaces {
+		if iface.State != "up" {
+			continue
+		}
+		for _, config := range []IPConfig{iface.IPv4, iface.IPv6} {
+			for _, address := range config.Addresses {
+				if address.Contains(ip) {
+					return true
+				}
+			}
+		}
+	}
+	for _, route := range ns.Routes.Running {
+		if !route.InMainTable() {
+			continue
+		}
+		_, destination, err := net.ParseCIDR(route.Destination)
+		if err == nil && destination.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnLinkIface returns the interface up with an address in the same
+// network of ip, if any
+func (ns *NetState) OnLinkIface(ip net.IP) string {
+	for _, iface := range ns.Ifaces {
+		if iface.State != "up" || iface.Type == "loopback" {
+			continue
+		}
+		for _, config := range []IPConfig{iface.IPv4, iface.IPv6} {
+			for _, address := range config.Addresses {
+				if address.Contains(ip) {
+					return iface.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func lintCarrier(ns NetState) []Finding {
+	findings := []Finding{}
+	for _, iface := range ns.Ifaces {
+		if iface.State != "up" || iface.Type == "loopback" {
+			continue
+		}
+		// the interfaces not found in sysfs are skipped
+		carrier, err := LinkCarrier(iface.Name)
+		if err != nil || carrier {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Path:     IfacePath(iface.Name) + "/state",
+			Message:  fmt.Sprintf("Interface %s is up but has no carrier, check the cable or the switch port", iface.Name),
+		})
+	}
+	return findings
+}
+
+func lintDuplicateAddresses(ns NetState) []Finding {
+	type assignment struct {
+		path  string
+		iface string
+	}
+	assignments := map[string][]assignment{}
+	for _, iface := range ns.Ifaces {
+		for _, family := range []struct {
+			name   string
+			config IPConfig
+		}{
+			{"ipv4", iface.IPv4},
+			{"ipv6", iface.IPv6},
+		} {
+			for _, address := range family.config.Addresses {
+				// the ports of a bond share the same link-local address
+				if address.IP.IsLinkLocalUnicast() || address.IP.IsLoopback() {
+					continue
+				}
+				assignments[address.IP.String()] = append(assignments[address.IP.String()], assignment{
+					path:  fmt.Sprintf("%s/%s/address/%s", IfacePath(iface.Name), family.name, address.String()),
+					iface: iface.Name,
+				})
+			}
+		}
+	}
+
+	ips := []string{}
+	for ip, a := range assignments {
+		if len(a) > 1 {
+			ips = append(ips, ip)
+		}
+	}
+	sort.Strings(ips)
+
+	findings := []Finding{}
+	for _, ip := range ips {
+		for i, a := range assignments[ip] {
+			others := []string{}
+			for j, other := range assignments[ip] {
+				if i != j {
+					others = append(others, other.iface)
+				}
+			}
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Path:     a.path,
+				Message:  fmt.Sprintf("Address %s is also assigned to %s", ip, strings.Join(others, ", ")),
+			})
+		}
+	}
+	return findings
+}
+
+func lintHostname(ns NetState) []Finding {
+	switch ns.Hostname.Running {
+	case "", "localhost", "localhost.localdomain":
+		return []Finding{{
+			Severity: SeverityWarning,
+			Path:     "hostname",
+			Message:  fmt.Sprintf("Hostname %q is not unique, the host can't be told apart from the others", ns.Hostname.Running),
+		}}
+	}
+	return nil
+}
+
+func lintMTU(ns NetState) []Finding {
+	findings := []Finding{}
+	for _, iface := range ns.Ifaces {
+		if iface.LinkAggregation != nil {
+			for _, port := range iface.LinkAggregation.Ports {
+				p := ns.getIfaceByName(port)
+				if p == nil || p.MTU == iface.MTU {
+					continue
+				}
+				findings = append(findings, Finding{
+					Severity: SeverityWarning,
+					Path:     IfacePath(port) + "/mtu",
+					Message:  fmt.Sprintf("MTU %d of bond port %s differs from the MTU %d of %s", p.MTU, port, iface.MTU, iface.Name),
+				})
+			}
+		}
+		if iface.VLAN != nil {
+			base := ns.getIfaceByName(iface.VLAN.BaseIface)
+			if base == nil || iface.MTU <= base.MTU {
+				continue
+			}
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Path:     IfacePath(iface.Name) + "/mtu",
+				Message:  fmt.Sprintf("MTU %d of VLAN %s exceeds the MTU %d of its base interface %s", iface.MTU, iface.Name, base.MTU, base.Name),
+			})
+		}
+	}
+	return findings
+}
+
+func lintResolvConf(ns NetState) []Finding {
+	resolvConf, err := readResolvConf(resolvConfPath)
+	if err != nil {
+		return nil
+	}
+	resolvServers := resolvConf.Servers
+	// a local stub resolver, like systemd-resolved, forwards the queries
+	// to the servers it's configured with
+	for _, server := range resolvServers {
+		if ip := net.ParseIP(server); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+	if len(resolvServers) > resolvConfMaxServers {
+		resolvServers = resolvServers[:resolvConfMaxServers]
+	}
+
+	configured := ns.DNS.Running.Servers
+	if len(configured) > resolvConfMaxServers {
+		configured = configured[:resolvConfMaxServers]
+	}
+	servers := map[string]bool{}
+	for _, server := range configured {
+		servers[server] = true
+	}
+	match := len(servers) == len(resolvServers)
+	for _, server := range resolvServers {
+		match = match && servers[server]
+	}
+	if match {
+		return nil
+	}
+
+	listed := strings.Join(resolvServers, ", ")
+	if listed == "" {
+		listed = "no servers"
+	}
+	return []Finding{{
+		Severity: SeverityWarning,
+		Path:     "dns",
+		Message:  fmt.Sprintf("%s lists %s, not the DNS servers configured in NetworkManager", resolvConfPath, listed),
+	}}
+}
+
+// readResolvConf returns the name servers and the search domains of a
+// resolv.conf file, without duplicates
+func readResolvConf(path string) (DNSConfig, error) {
+	config := DNSConfig{}
+	f, err := os.Open(path)
+	if err != nil {
+		return config, err
+	}
+	defer f.Close()
+
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			if !seen[fields[1]] {
+				seen[fields[1]] = true
+				config.Servers = append(config.Servers, fields[1])
+			}
+		case "search":
+			// the last search line wins
+			config.SearchDomains = fields[1:]
+		}
+	}
+	return config, scanner.Err()
+}
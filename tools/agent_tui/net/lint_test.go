@@ -0,0 +1,114 @@
+package net
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	sysClassNetPath = t.TempDir()
+	resolvConfPath = filepath.Join(t.TempDir(), "resolv.conf")
+	defer func() {
+		sysClassNetPath = "/sys/class/net"
+		resolvConfPath = "/etc/resolv.conf"
+	}()
+	for name, carrier := range map[string]string{"eth0": "1\n", "eth1": "0\n", "eth2": "1\n"} {
+		dir := filepath.Join(sysClassNetPath, name)
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "carrier"), []byte(carrier), 0644))
+	}
+
+	address := func(cidr string) net.IPNet {
+		ip, network, err := net.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		return net.IPNet{IP: ip, Mask: network.Mask}
+	}
+	healthy := NetState{
+		Hostname: Hostname{Running: "master-0"},
+		DNS:      DNSResolver{Running: DNSConfig{Servers: []string{"192.168.111.1", "10.0.0.53", "fe80::1%eth0"}}},
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1", Metric: 100, TableID: 254},
+			{Destination: "10.0.0.0/24", NextHopIface: "eth0", NextHopAddr: "192.168.111.254", Metric: 100, TableID: 254},
+		}},
+		Ifaces: []Iface{
+			{Name: "lo", Type: "loopback", State: "up", MTU: 65536, IPv4: IPConfig{Addresses: []net.IPNet{address("127.0.0.1/8")}}},
+			{Name: "eth0", Type: "ethernet", State: "up", MTU: 1500, IPv4: IPConfig{Addresses: []net.IPNet{address("192.168.111.20/24")}},
+				IPv6: IPConfig{Addresses: []net.IPNet{address("fe80::5054:ff:fe00:1/64")}}},
+			{Name: "eth3", Type: "ethernet", State: "up", MTU: 1500, IPv6: IPConfig{Addresses: []net.IPNet{address("fe80::5054:ff:fe00:1/64")}}},
+		},
+	}
+	assert.NoError(t, os.WriteFile(resolvConfPath, []byte("# Generated by NetworkManager\nsearch example.com\nnameserver 192.168.111.1\nnameserver 10.0.0.53\nnameserver fe80::1%eth0\n"), 0644))
+	assert.Empty(t, Lint(healthy))
+
+	broken := NetState{
+		Hostname: Hostname{Running: "localhost"},
+		DNS:      DNSResolver{Running: DNSConfig{Servers: []string{"192.168.111.1", "8.8.8.8"}}},
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth2", NextHopAddr: "172.16.0.1", TableID: 100},
+		}},
+		Ifaces: []Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MTU: 9000, Controller: "bond0"},
+			{Name: "eth1", Type: "ethernet", State: "up", MTU: 1500, Controller: "bond0"},
+			{Name: "bond0", Type: "bond", State: "up", MTU: 1500, LinkAggregation: &LinkAggregation{Mode: "active-backup", Ports: []string{"eth0", "eth1"}}},
+			{Name: "bond0.10", Type: "vlan", State: "up", MTU: 9000, VLAN: &VLAN{BaseIface: "bond0", ID: 10},
+				IPv4: IPConfig{Addresses: []net.IPNet{address("192.168.111.20/24")}}},
+			{Name: "eth2", Type: "ethernet", State: "up", MTU: 1500, IPv4: IPConfig{Addresses: []net.IPNet{address("192.168.111.20/24")}}},
+		},
+	}
+	assert.NoError(t, os.WriteFile(resolvConfPath, []byte("nameserver 127.0.0.53\n"), 0644))
+	assert.Equal(t, []Finding{
+		{Severity: SeverityError, Path: "routes", Message: "No default route, only the directly connected networks are reachable"},
+		{Severity: SeverityError, Path: "dns/servers/8.8.8.8", Message: "DNS server 8.8.8.8 is not reachable by any route"},
+		{Severity: SeverityWarning, Path: "interfaces/eth1/state", Message: "Interface eth1 is up but has no carrier, check the cable or the switch port"},
+		{Severity: SeverityError, Path: "interfaces/bond0.10/ipv4/address/192.168.111.20/24", Message: "Address 192.168.111.20 is also assigned to eth2"},
+		{Severity: SeverityError, Path: "interfaces/eth2/ipv4/address/192.168.111.20/24", Message: "Address 192.168.111.20 is also assigned to bond0.10"},
+		{Severity: SeverityWarning, Path: "hostname", Message: `Hostname "localhost" is not unique, the host can't be told apart from the others`},
+		{Severity: SeverityWarning, Path: "interfaces/eth0/mtu", Message: "MTU 9000 of bond port eth0 differs from the MTU 1500 of bond0"},
+		{Severity: SeverityWarning, Path: "interfaces/bond0.10/mtu", Message: "MTU 9000 of VLAN bond0.10 exceeds the MTU 1500 of its base interface bond0"},
+	}, Lint(broken))
+	assert.True(t, HasErrors(Lint(broken)))
+	assert.False(t, HasErrors([]Finding{{Severity: SeverityWarning, Path: "hostname"}}))
+}
+
+func TestLintResolvConf(t *testing.T) {
+	resolvConfPath = filepath.Join(t.TempDir(), "resolv.conf")
+	defer func() {
+		resolvConfPath = "/etc/resolv.conf"
+	}()
+	ns := NetState{DNS: DNSResolver{Running: DNSConfig{Servers: []string{"192.168.111.1", "10.0.0.53", "10.0.0.54", "10.0.0.55"}}}}
+
+	for _, tc := range []struct {
+		name       string
+		resolvConf string
+		findings   []Finding
+	}{
+		{
+			name:       "only the first servers are used",
+			resolvConf: "nameserver 192.168.111.1\nnameserver 10.0.0.53\nnameserver 10.0.0.54\n",
+		},
+		{
+			name:       "the servers after the first ones are ignored",
+			resolvConf: "nameserver 192.168.111.1\nnameserver 10.0.0.53\nnameserver 10.0.0.54\nnameserver 10.0.0.99\n",
+		},
+		{
+			name:       "stub resolver",
+			resolvConf: "nameserver 127.0.0.53\noptions edns0 trust-ad\n",
+		},
+		{
+			name:       "different servers",
+			resolvConf: "nameserver 192.168.111.1\nnameserver 10.0.0.53\n",
+			findings: []Finding{
+				{Severity: SeverityWarning, Path: "dns", Message: resolvConfPath + " lists 192.168.111.1, 10.0.0.53, not the DNS servers configured in NetworkManager"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, os.WriteFile(resolvConfPath, []byte(tc.resolvConf), 0644))
+			assert.Equal(t, tc.findings, lintResolvConf(ns))
+		})
+	}
+}
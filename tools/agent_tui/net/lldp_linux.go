@@ -0,0 +1,297 @@
+package net
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	ethPLLDP = 0x88cc
+
+	lldpTLVEnd             = 0
+	lldpTLVChassisID       = 1
+	lldpTLVPortID          = 2
+	lldpTLVTTL             = 3
+	lldpTLVPortDescription = 4
+	lldpTLVSystemName      = 5
+	lldpTLVOrgSpecific     = 127
+
+	// the subtypes of the chassis and port IDs holding a MAC address or
+	// a network address, the other ones are names
+	lldpChassisMAC     = 4
+	lldpChassisAddress = 5
+	lldpPortMAC        = 3
+	lldpPortAddress    = 4
+
+	// the IANA address families of the network addresses
+	ianaIPv4 = 1
+	ianaIPv6 = 2
+
+	// the organizationally specific TLVs of IEEE 802.1 and 802.3
+	lldp8021PortVLANID  = 1
+	lldp8021VLANName    = 3
+	lldp8023MaxFrameLen = 4
+
+	// the ethernet header and the FCS, included in the maximum frame
+	// size advertised by the switch but not in the MTU
+	ethFrameOverhead = 18
+
+	// the default interval between the LLDP frames sent by the switches
+	// is 30 seconds
+	DefaultLLDPWindow = 31 * time.Second
+)
+
+var (
+	// the nearest bridge group address, not forwarded by the switches
+	lldpMulticast = net.HardwareAddr{0x01, 0x80, 0xc2, 0x00, 0x00, 0x0e}
+
+	oui8021 = [3]byte{0x00, 0x80, 0xc2}
+	oui8023 = [3]byte{0x00, 0x12, 0x0f}
+)
+
+// LLDPVLAN is a VLAN advertised by the switch on the port
+type LLDPVLAN struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// LLDPNeighbor is the switch port an interface is connected to, as
+// advertised by the switch
+type LLDPNeighbor struct {
+	Iface           string `json:"iface"`
+	ChassisID       string `json:"chassisID"`
+	PortID          string `json:"portID"`
+	PortDescription string `json:"portDescription,omitempty"`
+	SystemName      string `json:"systemName,omitempty"`
+	// PortVLANID is the VLAN of the untagged frames, 0 if not advertised
+	PortVLANID int        `json:"portVLANID,omitempty"`
+	VLANs      []LLDPVLAN `json:"vlans,omitempty"`
+	// MTU is derived from the maximum frame size, 0 if not advertised
+	MTU int           `json:"mtu,omitempty"`
+	TTL time.Duration `json:"-"`
+}
+
+// Switch returns the name of the switch, or its chassis ID
+func (n LLDPNeighbor) Switch() string {
+	if n.SystemName != "" {
+		return n.SystemName
+	}
+	return n.ChassisID
+}
+
+// Port returns the port ID, with its description when different
+func (n LLDPNeighbor) Port() string {
+	if n.PortDescription != "" && n.PortDescription != n.PortID {
+		return fmt.Sprintf("%s (%s)", n.PortID, n.PortDescription)
+	}
+	return n.PortID
+}
+
+// VLANList returns the VLANs advertised, the port VLAN ID first
+func (n LLDPNeighbor) VLANList() string {
+	vlans := []string{}
+	if n.PortVLANID != 0 {
+		vlans = append(vlans, fmt.Sprintf("%d (untagged)", n.PortVLANID))
+	}
+	for _, vlan := range n.VLANs {
+		if vlan.ID == n.PortVLANID {
+			continue
+		}
+		text := strconv.Itoa(vlan.ID)
+		if vlan.Name != "" {
+			text += fmt.Sprintf(" (%s)", vlan.Name)
+		}
+		vlans = append(vlans, text)
+	}
+	return strings.Join(vlans, ", ")
+}
+
+func (n LLDPNeighbor) String() string {
+	text := fmt.Sprintf("%s: switch %s, port %s", n.Iface, n.Switch(), n.Port())
+	if vlans := n.VLANList(); vlans != "" {
+		text += ", VLAN " + vlans
+	}
+	if n.MTU != 0 {
+		text += fmt.Sprintf(", MTU %d", n.MTU)
+	}
+	return text
+}
+
+// parseLLDP parses the TLVs of an ethernet frame carrying a LLDPDU
+func parseLLDP(frame []byte) (LLDPNeighbor, error) {
+	if len(frame) < sizeofEthHeader || binary.BigEndian.Uint16(frame[12:14]) != ethPLLDP {
+		return LLDPNeighbor{}, errors.New("not a LLDP frame")
+	}
+	n := LLDPNeighbor{}
+	for data := frame[sizeofEthHeader:]; len(data) > 0; {
+		if len(data) < 2 {
+			return LLDPNeighbor{}, errors.New("TLV truncated")
+		}
+		header := binary.BigEndian.Uint16(data[0:2])
+		tlvType, length := int(header>>9), int(header&0x1ff)
+		if len(data) < 2+length {
+			return LLDPNeighbor{}, fmt.Errorf("TLV %d truncated", tlvType)
+		}
+		value := data[2 : 2+length]
+		data = data[2+length:]
+
+		switch tlvType {
+		case lldpTLVEnd:
+			data = nil
+		case lldpTLVChassisID:
+			if length > 1 {
+				n.ChassisID = lldpID(value[0], value[1:], lldpChassisMAC, lldpChassisAddress)
+			}
+		case lldpTLVPortID:
+			if length > 1 {
+				n.PortID = lldpID(value[0], value[1:], lldpPortMAC, lldpPortAddress)
+			}
+		case lldpTLVTTL:
+			if length == 2 {
+				n.TTL = time.Duration(binary.BigEndian.Uint16(value)) * time.Second
+			}
+		case lldpTLVPortDescription:
+			n.PortDescription = lldpString(value)
+		case lldpTLVSystemName:
+			n.SystemName = lldpString(value)
+		case lldpTLVOrgSpecific:
+			if length < 4 {
+				continue
+			}
+			oui, subtype, info := [3]byte(value[0:3]), value[3], value[4:]
+			switch {
+			case oui == oui8021 && subtype == lldp8021PortVLANID && len(info) == 2:
+				n.PortVLANID = int(binary.BigEndian.Uint16(info))
+			case oui == oui8021 && subtype == lldp8021VLANName && len(info) >= 3 && len(info) >= 3+int(info[2]):
+				n.VLANs = append(n.VLANs, LLDPVLAN{
+					ID:   int(binary.BigEndian.Uint16(info[0:2])),
+					Name: lldpString(info[3 : 3+int(info[2])]),
+				})
+			case oui == oui8023 && subtype == lldp8023MaxFrameLen && len(info) == 2:
+				n.MTU = int(binary.BigEndian.Uint16(info)) - ethFrameOverhead
+			}
+		}
+	}
+	// the first three TLVs are mandatory
+	if n.ChassisID == "" || n.PortID == "" {
+		return LLDPNeighbor{}, errors.New("chassis or port ID missing")
+	}
+	return n, nil
+}
+
+// lldpID formats a chassis or port ID according to its subtype
+func lldpID(subtype byte, id []byte, macSubtype, addressSubtype byte) string {
+	switch {
+	case subtype == macSubtype && len(id) == 6:
+		return net.HardwareAddr(id).String()
+	case subtype == addressSubtype && len(id) == 1+net.IPv4len && id[0] == ianaIPv4,
+		subtype == addressSubtype && len(id) == 1+net.IPv6len && id[0] == ianaIPv6:
+		return net.IP(id[1:]).String()
+	default:
+		return lldpString(id)
+	}
+}
+
+// lldpString returns the text of a TLV, some switches terminating it
+// with a NUL character
+func lldpString(value []byte) string {
+	return strings.TrimRight(string(value), "\x00")
+}
+
+// ListenLLDP listens for the LLDP frames received by the interfaces
+// until the window expires or stop is closed, calling neighbor for each
+// new or changed neighbor. The calls are serialized. It needs the
+// CAP_NET_RAW capability. The interfaces that can't be listened to are
+// reported in the error, while the other ones are still listened to.
+func ListenLLDP(ifaceNames []string, window time.Duration, neighbor func(LLDPNeighbor), stop <-chan struct{}) error {
+	neighbors := make(chan LLDPNeighbor)
+	errs := make(chan error, len(ifaceNames))
+	for _, name := range ifaceNames {
+		go func(name string) {
+			errs <- listenLLDP(name, window, neighbors, stop)
+		}(name)
+	}
+
+	seen := map[string]LLDPNeighbor{}
+	errList := []error{}
+	for pending := len(ifaceNames); pending > 0; {
+		select {
+		case n := <-neighbors:
+			key := n.Iface + " " + n.ChassisID + " " + n.PortID
+			if old, ok := seen[key]; ok && old.String() == n.String() {
+				continue
+			}
+			seen[key] = n
+			neighbor(n)
+		case err := <-errs:
+			pending--
+			if err != nil {
+				errList = append(errList, err)
+			}
+		}
+	}
+	sort.Slice(errList, func(i, j int) bool { return errList[i].Error() < errList[j].Error() })
+	return errors.Join(errList...)
+}
+
+func listenLLDP(ifaceName string, window time.Duration, neighbors chan<- LLDPNeighbor, stop <-chan struct{}) error {
+	iface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return fmt.Errorf("cannot listen for LLDP on %s: %w", ifaceName, err)
+	}
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, int(htons(ethPLLDP)))
+	if err != nil {
+		return fmt.Errorf("cannot open the LLDP socket: %w", err)
+	}
+	defer syscall.Close(fd)
+	if err := syscall.Bind(fd, &syscall.SockaddrLinklayer{Protocol: htons(ethPLLDP), Ifindex: iface.Index}); err != nil {
+		return fmt.Errorf("cannot bind the LLDP socket to %s: %w", ifaceName, err)
+	}
+	// the NIC must accept the frames sent to the LLDP multicast address,
+	// as described by struct packet_mreq
+	mreq := make([]byte, 16)
+	binary.NativeEndian.PutUint32(mreq[0:4], uint32(iface.Index))
+	binary.NativeEndian.PutUint16(mreq[4:6], syscall.PACKET_MR_MULTICAST)
+	binary.NativeEndian.PutUint16(mreq[6:8], uint16(len(lldpMulticast)))
+	copy(mreq[8:], lldpMulticast)
+	if err := syscall.SetsockoptString(fd, syscall.SOL_PACKET, syscall.PACKET_ADD_MEMBERSHIP, string(mreq)); err != nil {
+		return fmt.Errorf("cannot receive the LLDP frames on %s: %w", ifaceName, err)
+	}
+	// the reads are interrupted periodically to check stop
+	tv := syscall.NsecToTimeval((100 * time.Millisecond).Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		return err
+	}
+
+	// the frames can be as large as the MTU plus the ethernet header,
+	// and the LLDPDU alone can be as large as the standard MTU
+	buf := make([]byte, max(iface.MTU, defaultMTU)+sizeofEthHeader)
+	for start := time.Now(); time.Since(start) < window; {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		neighbor, err := parseLLDP(buf[:n])
+		if err != nil {
+			continue
+		}
+		neighbor.Iface = ifaceName
+		neighbors <- neighbor
+	}
+	return nil
+}
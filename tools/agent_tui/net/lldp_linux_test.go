@@ -0,0 +1,178 @@
+package net
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	// a frame of an access switch port, with the VLAN and MTU TLVs
+	testLLDPAccessFrame = `
+		0180 c200 000e 2c6b f511 2233 88cc 0207
+		042c 6bf5 1122 0004 0a05 6765 2d30 2f30
+		2f31 3206 0200 7808 0e73 6572 7665 722d
+		3037 2065 7468 300a 0873 772d 7261 636b
+		33fe 0600 80c2 0100 64fe 0b00 80c2 0300
+		6404 7072 6f76 fe10 0080 c203 00c8 0962
+		6172 656d 6574 616c fe06 0012 0f04 2412
+		0000`
+	// a frame identifying the chassis by its management address and the
+	// port by its MAC address, with an unknown OUI and the padding
+	testLLDPManagementFrame = `
+		0180 c200 000e 0011 2233 4455 88cc 0206
+		0501 0a00 0001 0407 0300 1122 3344 5506
+		0200 780a 066c 6561 6631 00fe 0500 0ce7
+		0102 0000 0000 0000 0000 0000`
+)
+
+func testLLDPFrame(t *testing.T, dump string) []byte {
+	frame, err := hex.DecodeString(strings.Join(strings.Fields(dump), ""))
+	assert.NoError(t, err)
+	return frame
+}
+
+// testLLDPMaxSizeFrame returns the access frame padded with system
+// description TLVs, ignored by the parser, to the maximum frame size
+func testLLDPMaxSizeFrame(t *testing.T) []byte {
+	frame := testLLDPFrame(t, testLLDPAccessFrame)
+	// without the end TLV
+	frame = frame[:len(frame)-2]
+	maxSize := sizeofEthHeader + defaultMTU
+	for len(frame) < maxSize-2 {
+		length := min(maxSize-2-len(frame)-2, 0x1ff)
+		frame = append(frame, byte(6<<1|length>>8), byte(length))
+		frame = append(frame, []byte(strings.Repeat("x", length))...)
+	}
+	frame = append(frame, 0, 0)
+	assert.Len(t, frame, maxSize)
+	return frame
+}
+
+func TestParseLLDP(t *testing.T) {
+	cases := []struct {
+		name     string
+		frame    string
+		expected LLDPNeighbor
+		text     string
+		err      string
+	}{
+		{
+			name:  "access port",
+			frame: testLLDPAccessFrame,
+			expected: LLDPNeighbor{
+				ChassisID:       "2c:6b:f5:11:22:00",
+				PortID:          "ge-0/0/12",
+				PortDescription: "server-07 eth0",
+				SystemName:      "sw-rack3",
+				PortVLANID:      100,
+				VLANs:           []LLDPVLAN{{ID: 100, Name: "prov"}, {ID: 200, Name: "baremetal"}},
+				MTU:             9216,
+				TTL:             2 * time.Minute,
+			},
+			text: "eth0: switch sw-rack3, port ge-0/0/12 (server-07 eth0), VLAN 100 (untagged), 200 (baremetal), MTU 9216",
+		},
+		{
+			name:  "management address",
+			frame: testLLDPManagementFrame,
+			expected: LLDPNeighbor{
+				ChassisID:  "10.0.0.1",
+				PortID:     "00:11:22:33:44:55",
+				SystemName: "leaf1",
+				TTL:        2 * time.Minute,
+			},
+			text: "eth0: switch leaf1, port 00:11:22:33:44:55",
+		},
+		{
+			name:  "other ethertype",
+			frame: "0180 c200 000e 0011 2233 4455 0800 0207",
+			err:   "not a LLDP frame",
+		},
+		{
+			name:  "truncated",
+			frame: "0180 c200 000e 0011 2233 4455 88cc 0207 0400 1122",
+			err:   "TLV 1 truncated",
+		},
+		{
+			name:  "port ID missing",
+			frame: "0180 c200 000e 0011 2233 4455 88cc 0207 0400 1122 3344 5506 0200 7800 00",
+			err:   "chassis or port ID missing",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			neighbor, err := parseLLDP(testLLDPFrame(t, tc.frame))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, neighbor)
+			neighbor.Iface = "eth0"
+			assert.Equal(t, tc.text, neighbor.String())
+		})
+	}
+
+	neighbor, err := parseLLDP(testLLDPMaxSizeFrame(t))
+	assert.NoError(t, err)
+	assert.Equal(t, "ge-0/0/12", neighbor.PortID)
+}
+
+func TestListenLLDP(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating a veth pair needs root")
+	}
+	if _, err := exec.LookPath("ip"); err != nil {
+		t.Skip("the ip command is missing")
+	}
+
+	nic, switchPort := "atuilldp0", "atuilldp1"
+	ip := func(args ...string) {
+		if output, err := exec.Command("ip", args...).CombinedOutput(); err != nil {
+			t.Fatalf("ip %v: %v: %s", args, err, output)
+		}
+	}
+	ip("link", "add", nic, "type", "veth", "peer", "name", switchPort)
+	t.Cleanup(func() {
+		exec.Command("ip", "link", "del", nic).Run()
+	})
+	ip("link", "set", nic, "up")
+	ip("link", "set", switchPort, "up")
+
+	// the switch sends a frame of the maximum size until it is received
+	port, err := net.InterfaceByName(switchPort)
+	assert.NoError(t, err)
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, 0)
+	assert.NoError(t, err)
+	defer syscall.Close(fd)
+	frame := testLLDPMaxSizeFrame(t)
+	addr := &syscall.SockaddrLinklayer{Protocol: htons(ethPLLDP), Ifindex: port.Index, Halen: 6}
+	copy(addr.Addr[:], lldpMulticast)
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(100 * time.Millisecond):
+				syscall.Sendto(fd, frame, 0, addr)
+			}
+		}
+	}()
+
+	neighbors := []string{}
+	err = ListenLLDP([]string{nic, "atuilldp9"}, 5*time.Second, func(n LLDPNeighbor) {
+		neighbors = append(neighbors, n.String())
+		close(stop)
+	}, stop)
+	assert.EqualError(t, err, "cannot listen for LLDP on atuilldp9: route ip+net: no such network interface")
+	assert.Equal(t, []string{fmt.Sprintf("%s: switch sw-rack3, port ge-0/0/12 (server-07 eth0), VLAN 100 (untagged), 200 (baremetal), MTU 9216", nic)}, neighbors)
+}
@@ -0,0 +1,104 @@
+package net
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+)
+
+// The neighbor states, as defined in linux/neighbour.h
+const (
+	nudIncomplete = 0x01
+	nudReachable  = 0x02
+	nudStale      = 0x04
+	nudDelay      = 0x08
+	nudProbe      = 0x10
+	nudFailed     = 0x20
+	nudNoARP      = 0x40
+	nudPermanent  = 0x80
+
+	ndaDst    = 1
+	ndaLLAddr = 2
+
+	sizeofNdMsg = 12
+)
+
+var neighborStates = map[uint16]string{
+	nudIncomplete: "incomplete",
+	nudReachable:  "reachable",
+	nudStale:      "stale",
+	nudDelay:      "delay",
+	nudProbe:      "probe",
+	nudFailed:     "failed",
+	nudNoARP:      "noarp",
+	nudPermanent:  "permanent",
+}
+
+// Neighbor is an entry of the kernel neighbor table, filled by ARP for
+// IPv4 and NDP for IPv6
+type Neighbor struct {
+	IP    net.IP
+	Iface string
+	MAC   string
+	State string
+}
+
+// Resolved returns true if the link layer address of the neighbor is
+// known, even if not confirmed recently
+func (n Neighbor) Resolved() bool {
+	switch n.State {
+	case "reachable", "stale", "delay", "probe", "permanent", "noarp":
+		return true
+	}
+	return false
+}
+
+// Confirmed returns true if the neighbor answered recently, or is
+// configured statically
+func (n Neighbor) Confirmed() bool {
+	switch n.State {
+	case "reachable", "permanent", "noarp":
+		return true
+	}
+	return false
+}
+
+// LookupNeighbor returns the entry of the neighbor table for ip, or nil
+// if the kernel never tried to resolve it
+func LookupNeighbor(ip net.IP) (*Neighbor, error) {
+	msgs, err := netlinkDump(syscall.RTM_GETNEIGH)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range msgs {
+		neighbor, ok := parseNeighbor(&msgs[i])
+		if ok && neighbor.IP.Equal(ip) {
+			return neighbor, nil
+		}
+	}
+	return nil, nil
+}
+
+func parseNeighbor(m *syscall.NetlinkMessage) (*Neighbor, bool) {
+	if m.Header.Type != syscall.RTM_NEWNEIGH || len(m.Data) < sizeofNdMsg {
+		return nil, false
+	}
+	// ParseNetlinkRouteAttr doesn't support the neighbor messages
+	attrs := parseNestedAttrs(m.Data[sizeofNdMsg:])
+	if len(attrs[ndaDst]) != net.IPv4len && len(attrs[ndaDst]) != net.IPv6len {
+		return nil, false
+	}
+
+	neighbor := &Neighbor{
+		IP:    net.IP(attrs[ndaDst]),
+		State: neighborStates[binary.NativeEndian.Uint16(m.Data[8:10])],
+	}
+	if mac := attrs[ndaLLAddr]; len(mac) == 6 {
+		neighbor.MAC = net.HardwareAddr(mac).String()
+	}
+	if iface, err := net.InterfaceByIndex(int(int32(binary.NativeEndian.Uint32(m.Data[4:8])))); err == nil {
+		neighbor.Iface = iface.Name
+	}
+	return neighbor, true
+}
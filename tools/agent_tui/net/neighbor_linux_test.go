@@ -0,0 +1,37 @@
+package net
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNeighbor(t *testing.T) {
+	// ndmsg on the loopback, followed by NDA_DST and NDA_LLADDR
+	data := make([]byte, sizeofNdMsg)
+	data[0] = syscall.AF_INET
+	binary.NativeEndian.PutUint32(data[4:8], 1)
+	binary.NativeEndian.PutUint16(data[8:10], nudStale)
+	data = append(data,
+		8, 0, ndaDst, 0, 192, 168, 111, 1,
+		10, 0, ndaLLAddr, 0, 0x52, 0x54, 0, 0x12, 0x34, 0x56, 0, 0,
+	)
+
+	neighbor, ok := parseNeighbor(&syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH}, Data: data})
+	assert.True(t, ok)
+	assert.True(t, neighbor.IP.Equal(net.ParseIP("192.168.111.1")))
+	assert.Equal(t, "52:54:00:12:34:56", neighbor.MAC)
+	assert.Equal(t, "stale", neighbor.State)
+	assert.True(t, neighbor.Resolved())
+	assert.False(t, neighbor.Confirmed())
+	if lo, err := net.InterfaceByIndex(1); err == nil {
+		assert.Equal(t, lo.Name, neighbor.Iface)
+	}
+
+	_, ok = parseNeighbor(&syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWLINK}, Data: data})
+	assert.False(t, ok)
+	assert.False(t, Neighbor{State: "failed"}.Resolved())
+}
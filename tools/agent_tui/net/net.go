@@ -27,29 +27,69 @@ type Route struct {
 	Destination  string `json:"destination"`
 	NextHopIface string `json:"next-hop-interface"`
 	NextHopAddr  string `json:"next-hop-address"`
+	Metric       int    `json:"metric,omitempty"`
+	TableID      int    `json:"table-id,omitempty"`
 }
 
 type IPConfig struct {
 	Enabled   bool        `json:"enabled,omitempty"`
+	DHCP      bool        `json:"dhcp,omitempty"`
+	Autoconf  bool        `json:"autoconf,omitempty"` // IPv6 only
 	Addresses []net.IPNet `json:"address,omitempty"`
+	// The following flags are reported only when DHCP or autoconf are
+	// enabled, and tell which settings are taken from the server
+	AutoDNS     *bool `json:"auto-dns,omitempty"`
+	AutoGateway *bool `json:"auto-gateway,omitempty"`
+	AutoRoutes  *bool `json:"auto-routes,omitempty"`
 }
 
-func (ipc *IPConfig) UnmarshalJSON(data []byte) error {
-	type _Address struct {
-		IP        string `json:"ip"`
-		Prefixlen int    `json:"prefix-length"`
+type _Address struct {
+	IP        string `json:"ip"`
+	Prefixlen int    `json:"prefix-length"`
+}
+
+type _ipConfig struct {
+	Enabled     bool       `json:"enabled,omitempty"`
+	DHCP        bool       `json:"dhcp,omitempty"`
+	Autoconf    bool       `json:"autoconf,omitempty"`
+	Addresses   []_Address `json:"address,omitempty"`
+	AutoDNS     *bool      `json:"auto-dns,omitempty"`
+	AutoGateway *bool      `json:"auto-gateway,omitempty"`
+	AutoRoutes  *bool      `json:"auto-routes,omitempty"`
+}
+
+// MarshalJSON uses the same address format of nmstate
+func (ipc IPConfig) MarshalJSON() ([]byte, error) {
+	tempIpConfig := _ipConfig{
+		Enabled:     ipc.Enabled,
+		DHCP:        ipc.DHCP,
+		Autoconf:    ipc.Autoconf,
+		AutoDNS:     ipc.AutoDNS,
+		AutoGateway: ipc.AutoGateway,
+		AutoRoutes:  ipc.AutoRoutes,
 	}
-	type _ipConfig struct {
-		Enabled   bool       `json:"enabled,omitempty"`
-		Addresses []_Address `json:"address,omitempty"`
+	for _, address := range ipc.Addresses {
+		prefixlen, _ := address.Mask.Size()
+		tempIpConfig.Addresses = append(tempIpConfig.Addresses, _Address{
+			IP:        address.IP.String(),
+			Prefixlen: prefixlen,
+		})
 	}
+	return json.Marshal(tempIpConfig)
+}
 
+func (ipc *IPConfig) UnmarshalJSON(data []byte) error {
 	tempIpConfig := _ipConfig{}
 	if err := json.Unmarshal(data, &tempIpConfig); err != nil {
 		return err
 	}
 
 	ipc.Enabled = tempIpConfig.Enabled
+	ipc.DHCP = tempIpConfig.DHCP
+	ipc.Autoconf = tempIpConfig.Autoconf
+	ipc.AutoDNS = tempIpConfig.AutoDNS
+	ipc.AutoGateway = tempIpConfig.AutoGateway
+	ipc.AutoRoutes = tempIpConfig.AutoRoutes
 	for _, address := range tempIpConfig.Addresses {
 		ip, netCIDR, err := net.ParseCIDR(fmt.Sprintf("%s/%d", address.IP, address.Prefixlen))
 		if err != nil {
@@ -63,20 +103,48 @@ func (ipc *IPConfig) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// LinkAggregation describes a bond and its ports
+type LinkAggregation struct {
+	Mode    string                 `json:"mode"`
+	Ports   []string               `json:"port"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// VLAN describes a VLAN on top of its base interface
+type VLAN struct {
+	BaseIface string `json:"base-iface"`
+	ID        int    `json:"id"`
+}
+
+// Ethernet describes the link of an ethernet interface. The speed, in
+// Mb/s, and the duplex are unknown when the link is down.
+type Ethernet struct {
+	AutoNegotiation bool   `json:"auto-negotiation,omitempty"`
+	Speed           int    `json:"speed,omitempty"`
+	Duplex          string `json:"duplex,omitempty"`
+}
+
 type Iface struct {
 	Name  string   `json:"name"`
 	Type  string   `json:"type"`
 	State string   `json:"state"`
+	MAC   string   `json:"mac-address,omitempty"`
 	MTU   int      `json:"mtu"`
 	IPv4  IPConfig `json:"ipv4,omitempty"`
 	IPv6  IPConfig `json:"ipv6,omitempty"`
+	// Controller is the bond or bridge the interface is a port of
+	Controller      string           `json:"controller,omitempty"`
+	LinkAggregation *LinkAggregation `json:"link-aggregation,omitempty"`
+	VLAN            *VLAN            `json:"vlan,omitempty"`
+	Ethernet        *Ethernet        `json:"ethernet,omitempty"`
 }
 
 type NetState struct {
-	Hostname Hostname    `json:"hostname,omitempty"`
-	DNS      DNSResolver `json:"dns-resolver,omitempty"`
-	Routes   RoutesRC    `json:"routes,omitempty"`
-	Ifaces   []Iface     `json:"interfaces"`
+	Hostname   Hostname    `json:"hostname,omitempty"`
+	DNS        DNSResolver `json:"dns-resolver,omitempty"`
+	Routes     RoutesRC    `json:"routes,omitempty"`
+	RouteRules *RouteRules `json:"route-rules,omitempty"`
+	Ifaces     []Iface     `json:"interfaces"`
 }
 
 func IsIPv4DefaultRoute(destination string) (isDefaultRoute bool) {
@@ -99,22 +167,21 @@ func IsIPv6DefaultRoute(destination string) (isDefaultRoute bool) {
 	return
 }
 
-func (ns *NetState) getIfaceByName(ifaceName string) (r *Iface) {
-	for i := range ns.Ifaces {
-		if ns.Ifaces[i].Name == ifaceName {
-			r = &ns.Ifaces[i]
+// Ports returns the names of the interfaces controlled by the named one
+func (ns *NetState) Ports(controller string) []string {
+	ports := []string{}
+	for _, iface := range ns.Ifaces {
+		if iface.Controller == controller {
+			ports = append(ports, iface.Name)
 		}
 	}
-	return
+	return ports
 }
 
-func (ns *NetState) GetDefaultNextHopIface() (r *Iface, err error) {
-	for _, route := range ns.Routes.Running {
-		if IsIPv4DefaultRoute(route.Destination) || IsIPv6DefaultRoute(route.Destination) {
-			if r != nil {
-				return nil, fmt.Errorf("support for multiple default routes not yet implemented in agent-tui")
-			}
-			r = ns.getIfaceByName(route.NextHopIface)
+func (ns *NetState) getIfaceByName(ifaceName string) (r *Iface) {
+	for i := range ns.Ifaces {
+		if ns.Ifaces[i].Name == ifaceName {
+			r = &ns.Ifaces[i]
 		}
 	}
 	return
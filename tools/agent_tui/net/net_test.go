@@ -0,0 +1,55 @@
+package net
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalNetState(t *testing.T) {
+	data := `{
+		"interfaces": [
+			{"name": "eth0", "type": "ethernet", "state": "up", "mac-address": "52:54:00:AA:BB:01", "mtu": 1500,
+			 "controller": "bond0", "ethernet": {"auto-negotiation": true, "speed": 10000, "duplex": "full"},
+			 "ipv4": {"enabled": false}, "ipv6": {"enabled": false}},
+			{"name": "bond0", "type": "bond", "state": "up", "mtu": 1500,
+			 "link-aggregation": {"mode": "802.3ad", "options": {"miimon": 100}, "port": ["eth0"]},
+			 "ipv4": {"enabled": false}, "ipv6": {"enabled": false}},
+			{"name": "bond0.100", "type": "vlan", "state": "up", "mtu": 1500,
+			 "vlan": {"base-iface": "bond0", "id": 100},
+			 "ipv4": {"enabled": true, "dhcp": true, "auto-dns": true, "auto-gateway": true, "auto-routes": false,
+			          "address": [{"ip": "192.168.100.80", "prefix-length": 24}]}}
+		],
+		"routes": {"running": [
+			{"destination": "0.0.0.0/0", "next-hop-interface": "bond0.100", "next-hop-address": "192.168.100.1",
+			 "metric": 400, "table-id": 254}
+		]}
+	}`
+	ns := NetState{}
+	assert.NoError(t, json.Unmarshal([]byte(data), &ns))
+
+	eth0 := ns.getIfaceByName("eth0")
+	assert.Equal(t, "bond0", eth0.Controller)
+	assert.Equal(t, &Ethernet{AutoNegotiation: true, Speed: 10000, Duplex: "full"}, eth0.Ethernet)
+	assert.Equal(t, []string{"eth0"}, ns.Ports("bond0"))
+
+	bond := ns.getIfaceByName("bond0")
+	assert.Equal(t, "802.3ad", bond.LinkAggregation.Mode)
+	assert.Equal(t, []string{"eth0"}, bond.LinkAggregation.Ports)
+
+	vlan := ns.getIfaceByName("bond0.100")
+	assert.Equal(t, &VLAN{BaseIface: "bond0", ID: 100}, vlan.VLAN)
+	assert.True(t, *vlan.IPv4.AutoDNS)
+	assert.False(t, *vlan.IPv4.AutoRoutes)
+	assert.Nil(t, vlan.IPv6.AutoDNS)
+
+	assert.Equal(t, 400, ns.Routes.Running[0].Metric)
+	assert.Equal(t, 254, ns.Routes.Running[0].TableID)
+
+	// the DHCP flags are preserved when the state is serialized again
+	out, err := json.Marshal(vlan.IPv4)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"enabled": true, "dhcp": true, "auto-dns": true, "auto-gateway": true, "auto-routes": false,
+		"address": [{"ip": "192.168.100.80", "prefix-length": 24}]}`, string(out))
+}
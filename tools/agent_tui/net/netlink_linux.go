@@ -0,0 +1,110 @@
+package net
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// LinkEvent is the kind of a netlink notification
+type LinkEvent string
+
+const (
+	LinkEventLink    LinkEvent = "link"
+	LinkEventAddress LinkEvent = "address"
+	LinkEventRoute   LinkEvent = "route"
+)
+
+// The netlink groups of the link, address and route notifications, as
+// defined in linux/rtnetlink.h, not all of them being in syscall
+const (
+	rtmgrpLink       = 0x1
+	rtmgrpIPv4Ifaddr = 0x10
+	rtmgrpIPv4Route  = 0x40
+	rtmgrpIPv6Ifaddr = 0x100
+	rtmgrpIPv6Route  = 0x400
+
+	netlinkGroups = rtmgrpLink | rtmgrpIPv4Ifaddr | rtmgrpIPv4Route | rtmgrpIPv6Ifaddr | rtmgrpIPv6Route
+)
+
+// netlinkReadTimeout bounds the reads, so that the watcher notices
+// when it's stopped
+var netlinkReadTimeout = syscall.Timeval{Sec: 1}
+
+// WatchNetlink subscribes to the link, address and route notifications
+// of the kernel, and sends their kinds on the returned channel. The
+// channel is closed once stop is closed.
+func WatchNetlink(stop <-chan struct{}) (<-chan LinkEvent, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the netlink socket: %w", err)
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: netlinkGroups}); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("failed to subscribe to the netlink notifications: %w", err)
+	}
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &netlinkReadTimeout); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	events := make(chan LinkEvent, 16)
+	go func() {
+		defer close(events)
+		defer syscall.Close(fd)
+		buf := make([]byte, syscall.Getpagesize()*4)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			n, _, err := syscall.Recvfrom(fd, buf, 0)
+			switch err {
+			case nil:
+			case syscall.EAGAIN, syscall.EINTR:
+				// the read timed out or was interrupted
+				continue
+			case syscall.ENOBUFS:
+				// some notifications were lost, a single one is
+				// enough to refresh the state
+				select {
+				case events <- LinkEventLink:
+				case <-stop:
+					return
+				}
+				continue
+			default:
+				return
+			}
+			for _, event := range parseNetlinkEvents(buf[:n]) {
+				select {
+				case events <- event:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// parseNetlinkEvents returns the kinds of the notifications of a
+// netlink message
+func parseNetlinkEvents(data []byte) []LinkEvent {
+	msgs, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return nil
+	}
+	events := []LinkEvent{}
+	for _, msg := range msgs {
+		switch msg.Header.Type {
+		case syscall.RTM_NEWLINK, syscall.RTM_DELLINK:
+			events = append(events, LinkEventLink)
+		case syscall.RTM_NEWADDR, syscall.RTM_DELADDR:
+			events = append(events, LinkEventAddress)
+		case syscall.RTM_NEWROUTE, syscall.RTM_DELROUTE:
+			events = append(events, LinkEventRoute)
+		}
+	}
+	return events
+}
@@ -0,0 +1,38 @@
+package net
+
+import (
+	"encoding/binary"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// netlinkMessage encodes a netlink message with an empty payload
+func netlinkMessage(msgType uint16) []byte {
+	msg := make([]byte, syscall.NLMSG_HDRLEN)
+	binary.LittleEndian.PutUint32(msg[0:4], syscall.NLMSG_HDRLEN)
+	binary.LittleEndian.PutUint16(msg[4:6], msgType)
+	return msg
+}
+
+func TestParseNetlinkEvents(t *testing.T) {
+	data := []byte{}
+	for _, msgType := range []uint16{syscall.RTM_NEWLINK, syscall.RTM_DELADDR, syscall.NLMSG_NOOP, syscall.RTM_NEWROUTE} {
+		data = append(data, netlinkMessage(msgType)...)
+	}
+	assert.Equal(t, []LinkEvent{LinkEventLink, LinkEventAddress, LinkEventRoute}, parseNetlinkEvents(data))
+	assert.Empty(t, parseNetlinkEvents([]byte{1, 2}))
+}
+
+func TestWatchNetlink(t *testing.T) {
+	stop := make(chan struct{})
+	events, err := WatchNetlink(stop)
+	if err != nil {
+		t.Skipf("netlink not available: %v", err)
+	}
+	close(stop)
+	// the channel is closed within the read timeout
+	for range events {
+	}
+}
@@ -0,0 +1,388 @@
+package net
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// The rtnetlink attributes, as defined in linux/if_link.h and
+// linux/rtnetlink.h, not all of them being in syscall
+const (
+	iflaInfoKind = 1
+	iflaInfoData = 2
+	iflaVLANID   = 1
+	iflaBondMode = 1
+
+	nlaTypeMask = 0x3fff
+
+	// the valid lifetime of the addresses that don't expire
+	infinityLifeTime = 0xffffffff
+)
+
+// The interface types reported by nmstate for the kinds of the links
+var netlinkKinds = map[string]string{
+	"bond":        "bond",
+	"bridge":      "linux-bridge",
+	"dummy":       "dummy",
+	"ipvlan":      "ipvlan",
+	"macsec":      "macsec",
+	"macvlan":     "mac-vlan",
+	"macvtap":     "mac-vtap",
+	"openvswitch": "ovs-interface",
+	"team":        "team",
+	"tun":         "tun",
+	"veth":        "veth",
+	"vlan":        "vlan",
+	"vrf":         "vrf",
+	"vxlan":       "vxlan",
+}
+
+// The bond modes, by their kernel value
+var netlinkBondModes = []string{
+	"balance-rr",
+	"active-backup",
+	"balance-xor",
+	"broadcast",
+	"802.3ad",
+	"balance-tlb",
+	"balance-alb",
+}
+
+// NetlinkProvider reads the network state from the kernel through
+// rtnetlink, for the hosts where libnmstate is not available. The
+// route rules are not reported, and the DHCP and autoconf settings
+// are inferred from the addresses that expire.
+type NetlinkProvider struct{}
+
+func (NetlinkProvider) Name() string {
+	return ProviderNetlink
+}
+
+func (NetlinkProvider) RetrieveRaw() ([]byte, error) {
+	ns, err := netlinkNetState()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ns)
+}
+
+func netlinkNetState() (NetState, error) {
+	ns := NetState{}
+
+	ifaces, err := netlinkLinks()
+	if err != nil {
+		return ns, err
+	}
+	if err := netlinkAddresses(ifaces); err != nil {
+		return ns, err
+	}
+	routes, err := netlinkRoutes(ifaces)
+	if err != nil {
+		return ns, err
+	}
+
+	indexes := []int{}
+	for index := range ifaces {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		ns.Ifaces = append(ns.Ifaces, *ifaces[index])
+	}
+	// the ports of the bonds are known once all the links are read
+	for i, iface := range ns.Ifaces {
+		if iface.LinkAggregation != nil {
+			ns.Ifaces[i].LinkAggregation.Ports = ns.Ports(iface.Name)
+		}
+	}
+	ns.Routes.Running = routes
+
+	if hostname, err := os.Hostname(); err == nil {
+		ns.Hostname.Running = hostname
+	}
+	if dns, err := readResolvConf(resolvConfPath); err == nil {
+		ns.DNS.Running = dns
+	}
+	return ns, nil
+}
+
+// netlinkDump returns the messages of a rtnetlink dump request
+func netlinkDump(request int) ([]syscall.NetlinkMessage, error) {
+	data, err := syscall.NetlinkRIB(request, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, fmt.Errorf("netlink request %d failed: %w", request, err)
+	}
+	return syscall.ParseNetlinkMessage(data)
+}
+
+// parseNestedAttrs parses the attributes nested in the value of another
+func parseNestedAttrs(data []byte) map[int][]byte {
+	attrs := map[int][]byte{}
+	for len(data) >= syscall.SizeofRtAttr {
+		length := int(binary.NativeEndian.Uint16(data[0:2]))
+		if length < syscall.SizeofRtAttr || length > len(data) {
+			break
+		}
+		attrs[int(binary.NativeEndian.Uint16(data[2:4])&nlaTypeMask)] = data[syscall.SizeofRtAttr:length]
+		data = data[min(rtaAlign(length), len(data)):]
+	}
+	return attrs
+}
+
+func rtaAlign(length int) int {
+	return (length + syscall.RTA_ALIGNTO - 1) & ^(syscall.RTA_ALIGNTO - 1)
+}
+
+func routeAttrs(m *syscall.NetlinkMessage) (map[int][]byte, error) {
+	parsed, err := syscall.ParseNetlinkRouteAttr(m)
+	if err != nil {
+		return nil, err
+	}
+	attrs := map[int][]byte{}
+	for _, attr := range parsed {
+		attrs[int(attr.Attr.Type&nlaTypeMask)] = attr.Value
+	}
+	return attrs, nil
+}
+
+func attrUint32(attrs map[int][]byte, t int) (uint32, bool) {
+	value, ok := attrs[t]
+	if !ok || len(value) < 4 {
+		return 0, false
+	}
+	return binary.NativeEndian.Uint32(value), true
+}
+
+func attrString(attrs map[int][]byte, t int) string {
+	return strings.TrimRight(string(attrs[t]), "\x00")
+}
+
+// netlinkLinks returns the interfaces by index
+func netlinkLinks() (map[int]*Iface, error) {
+	msgs, err := netlinkDump(syscall.RTM_GETLINK)
+	if err != nil {
+		return nil, err
+	}
+
+	ifaces := map[int]*Iface{}
+	controllers := map[int]int{}
+	baseIfaces := map[int]int{}
+	for i := range msgs {
+		m := &msgs[i]
+		if m.Header.Type != syscall.RTM_NEWLINK || len(m.Data) < syscall.SizeofIfInfomsg {
+			continue
+		}
+		linkType := binary.NativeEndian.Uint16(m.Data[2:4])
+		index := int(int32(binary.NativeEndian.Uint32(m.Data[4:8])))
+		flags := binary.NativeEndian.Uint32(m.Data[8:12])
+		attrs, err := routeAttrs(m)
+		if err != nil {
+			return nil, err
+		}
+
+		iface := &Iface{Name: attrString(attrs, syscall.IFLA_IFNAME), State: "down"}
+		if flags&syscall.IFF_UP != 0 {
+			iface.State = "up"
+		}
+		if mtu, ok := attrUint32(attrs, syscall.IFLA_MTU); ok {
+			iface.MTU = int(mtu)
+		}
+		if mac := attrs[syscall.IFLA_ADDRESS]; len(mac) == 6 {
+			iface.MAC = strings.ToUpper(net.HardwareAddr(mac).String())
+		}
+		if controller, ok := attrUint32(attrs, syscall.IFLA_MASTER); ok {
+			controllers[index] = int(controller)
+		}
+
+		info := parseNestedAttrs(attrs[syscall.IFLA_LINKINFO])
+		kind := attrString(info, iflaInfoKind)
+		data := parseNestedAttrs(info[iflaInfoData])
+		switch {
+		case kind != "":
+			iface.Type = netlinkKinds[kind]
+			if iface.Type == "" {
+				iface.Type = kind
+			}
+		case linkType == syscall.ARPHRD_LOOPBACK:
+			iface.Type = "loopback"
+		case linkType == syscall.ARPHRD_ETHER:
+			iface.Type = "ethernet"
+			iface.Ethernet = sysfsEthernet(iface.Name)
+		default:
+			iface.Type = "unknown"
+		}
+		switch kind {
+		case "vlan":
+			iface.VLAN = &VLAN{}
+			if id := data[iflaVLANID]; len(id) >= 2 {
+				iface.VLAN.ID = int(binary.NativeEndian.Uint16(id))
+			}
+			if base, ok := attrUint32(attrs, syscall.IFLA_LINK); ok {
+				baseIfaces[index] = int(base)
+			}
+		case "bond":
+			iface.LinkAggregation = &LinkAggregation{}
+			if mode := data[iflaBondMode]; len(mode) >= 1 && int(mode[0]) < len(netlinkBondModes) {
+				iface.LinkAggregation.Mode = netlinkBondModes[mode[0]]
+			}
+		}
+		ifaces[index] = iface
+	}
+
+	for index, controller := range controllers {
+		if c, ok := ifaces[controller]; ok {
+			ifaces[index].Controller = c.Name
+		}
+	}
+	for index, base := range baseIfaces {
+		if b, ok := ifaces[base]; ok {
+			ifaces[index].VLAN.BaseIface = b.Name
+		}
+	}
+	return ifaces, nil
+}
+
+// sysfsEthernet returns the speed and duplex of an ethernet link, or
+// nil when they are unknown, for example when the link is down
+func sysfsEthernet(name string) *Ethernet {
+	data, err := os.ReadFile(filepath.Join(sysClassNetPath, name, "speed"))
+	if err != nil {
+		return nil
+	}
+	speed, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || speed <= 0 {
+		return nil
+	}
+	ethernet := &Ethernet{Speed: speed}
+	if data, err := os.ReadFile(filepath.Join(sysClassNetPath, name, "duplex")); err == nil {
+		ethernet.Duplex = strings.TrimSpace(string(data))
+	}
+	return ethernet
+}
+
+// netlinkAddresses adds the addresses to the interfaces. The IPv6
+// link-local addresses are skipped, like nmstate does.
+func netlinkAddresses(ifaces map[int]*Iface) error {
+	msgs, err := netlinkDump(syscall.RTM_GETADDR)
+	if err != nil {
+		return err
+	}
+
+	for i := range msgs {
+		m := &msgs[i]
+		if m.Header.Type != syscall.RTM_NEWADDR || len(m.Data) < syscall.SizeofIfAddrmsg {
+			continue
+		}
+		family := m.Data[0]
+		prefixLen := int(m.Data[1])
+		iface, ok := ifaces[int(binary.NativeEndian.Uint32(m.Data[4:8]))]
+		if !ok {
+			continue
+		}
+		attrs, err := routeAttrs(m)
+		if err != nil {
+			return err
+		}
+
+		// IFA_LOCAL is the address of the interface on point to point links
+		ip := net.IP(attrs[syscall.IFA_LOCAL])
+		if ip == nil {
+			ip = net.IP(attrs[syscall.IFA_ADDRESS])
+		}
+		dynamic := false
+		if cacheInfo := attrs[syscall.IFA_CACHEINFO]; len(cacheInfo) >= 8 {
+			dynamic = binary.NativeEndian.Uint32(cacheInfo[4:8]) != infinityLifeTime
+		}
+
+		config := &iface.IPv4
+		bits := 32
+		if family == syscall.AF_INET6 {
+			if ip.IsLinkLocalUnicast() {
+				continue
+			}
+			config = &iface.IPv6
+			bits = 128
+		}
+		config.Enabled = true
+		config.Addresses = append(config.Addresses, net.IPNet{IP: ip, Mask: net.CIDRMask(prefixLen, bits)})
+		switch {
+		case !dynamic:
+		case family == syscall.AF_INET || prefixLen == 128:
+			// DHCPv6 assigns single addresses
+			config.DHCP = true
+		default:
+			config.Autoconf = true
+		}
+	}
+	return nil
+}
+
+// netlinkRoutes returns the unicast routes, except the ones of the
+// local table and the IPv6 link-local ones
+func netlinkRoutes(ifaces map[int]*Iface) ([]Route, error) {
+	msgs, err := netlinkDump(syscall.RTM_GETROUTE)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []Route{}
+	for i := range msgs {
+		m := &msgs[i]
+		if m.Header.Type != syscall.RTM_NEWROUTE || len(m.Data) < syscall.SizeofRtMsg {
+			continue
+		}
+		family := m.Data[0]
+		dstLen := int(m.Data[1])
+		table := int(m.Data[4])
+		if m.Data[7] != syscall.RTN_UNICAST {
+			continue
+		}
+		attrs, err := routeAttrs(m)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := attrUint32(attrs, syscall.RTA_TABLE); ok {
+			table = int(t)
+		}
+		if table == syscall.RT_TABLE_LOCAL {
+			continue
+		}
+
+		bits := 32
+		dst := net.IP(make([]byte, 4))
+		if family == syscall.AF_INET6 {
+			bits = 128
+			dst = net.IP(make([]byte, 16))
+		}
+		if value, ok := attrs[syscall.RTA_DST]; ok {
+			dst = net.IP(value)
+		}
+		destination := net.IPNet{IP: dst, Mask: net.CIDRMask(dstLen, bits)}
+		if family == syscall.AF_INET6 && dst.IsLinkLocalUnicast() {
+			continue
+		}
+
+		route := Route{Destination: destination.String(), TableID: table}
+		if gateway, ok := attrs[syscall.RTA_GATEWAY]; ok {
+			route.NextHopAddr = net.IP(gateway).String()
+		}
+		if oif, ok := attrUint32(attrs, syscall.RTA_OIF); ok {
+			if iface, ok := ifaces[int(oif)]; ok {
+				route.NextHopIface = iface.Name
+			}
+		}
+		if metric, ok := attrUint32(attrs, syscall.RTA_PRIORITY); ok {
+			route.Metric = int(metric)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
@@ -0,0 +1,39 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetlinkProvider(t *testing.T) {
+	defer SetProvider(CurrentProvider())
+	SetProvider(NetlinkProvider{})
+
+	ns, err := RetrieveNetState()
+	assert.NoError(t, err)
+	lo := ns.getIfaceByName("lo")
+	if assert.NotNil(t, lo) {
+		assert.Equal(t, "loopback", lo.Type)
+		assert.Equal(t, "up", lo.State)
+		assert.Equal(t, "127.0.0.1/8", lo.IPv4.Addresses[0].String())
+		assert.False(t, lo.IPv4.DHCP)
+	}
+	for _, route := range ns.Routes.Running {
+		assert.NotEqual(t, 255, route.TableID, "the local table is skipped")
+	}
+}
+
+func TestParseNestedAttrs(t *testing.T) {
+	// IFLA_INFO_KIND "vlan" followed by IFLA_INFO_DATA with IFLA_VLAN_ID 100
+	data := []byte{
+		9, 0, 1, 0, 'v', 'l', 'a', 'n', 0, 0, 0, 0,
+		12, 0, 2, 0x80, 6, 0, 1, 0, 100, 0, 0, 0,
+	}
+	attrs := parseNestedAttrs(data)
+	assert.Equal(t, "vlan", attrString(attrs, iflaInfoKind))
+	vlan := parseNestedAttrs(attrs[iflaInfoData])
+	assert.Equal(t, []byte{100, 0}, vlan[iflaVLANID])
+
+	assert.Empty(t, parseNestedAttrs([]byte{2, 0}))
+}
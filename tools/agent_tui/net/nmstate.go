@@ -0,0 +1,52 @@
+//go:build cgo
+
+package net
+
+import (
+	"time"
+
+	"github.com/nmstate/nmstate/rust/src/go/nmstate/v2"
+)
+
+// NMStateProvider retrieves and applies the network state through
+// libnmstate
+type NMStateProvider struct{}
+
+func defaultProvider() Provider {
+	return NMStateProvider{}
+}
+
+func newNMStateProvider() (Provider, error) {
+	return NMStateProvider{}, nil
+}
+
+func (NMStateProvider) Name() string {
+	return ProviderNMState
+}
+
+func (NMStateProvider) RetrieveRaw() ([]byte, error) {
+	nm := nmstate.New()
+	state, err := nm.RetrieveNetState()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+func (NMStateProvider) ApplyWithCheckpoint(data []byte, timeout time.Duration) error {
+	nm := nmstate.New(nmstate.WithTimeout(timeout), nmstate.WithNoCommit())
+	_, err := nm.ApplyNetState(string(data))
+	return err
+}
+
+func (NMStateProvider) CommitCheckpoint() error {
+	nm := nmstate.New()
+	_, err := nm.CommitCheckpoint("")
+	return err
+}
+
+func (NMStateProvider) RollbackCheckpoint() error {
+	nm := nmstate.New()
+	_, err := nm.RollbackCheckpoint("")
+	return err
+}
@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package net
+
+import "fmt"
+
+// Without cgo libnmstate can't be linked, so the network state is
+// retrieved through netlink and can't be changed
+func defaultProvider() Provider {
+	return NetlinkProvider{}
+}
+
+func newNMStateProvider() (Provider, error) {
+	return nil, fmt.Errorf("the %s provider is not available, agent-tui was built without cgo", ProviderNMState)
+}
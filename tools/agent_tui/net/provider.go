@@ -0,0 +1,143 @@
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// The providers of the network state, selected by configuration
+const (
+	// ProviderAuto uses nmstate, or netlink in the binaries built
+	// without cgo. The choice is made at build time, there is no
+	// fallback when nmstate fails at runtime.
+	ProviderAuto    = "auto"
+	ProviderNMState = "nmstate"
+	ProviderNetlink = "netlink"
+	ProviderFixture = "fixture"
+)
+
+// Providers lists the names accepted by NewProvider
+var Providers = []string{ProviderAuto, ProviderNMState, ProviderNetlink, ProviderFixture}
+
+// Provider retrieves the network state of the host
+type Provider interface {
+	// Name returns the name used to select the provider
+	Name() string
+	// RetrieveRaw returns the network state as a nmstate JSON document
+	RetrieveRaw() ([]byte, error)
+}
+
+// Applier is implemented by the providers able to change the network
+// configuration through a checkpoint
+type Applier interface {
+	ApplyWithCheckpoint(data []byte, timeout time.Duration) error
+	CommitCheckpoint() error
+	RollbackCheckpoint() error
+}
+
+// The provider used by the package functions, replaced by SetProvider
+var provider = defaultProvider()
+
+// NewProvider returns the named provider. The fixture provider reads
+// the network state from fixturePath.
+func NewProvider(name string, fixturePath string) (Provider, error) {
+	switch name {
+	case ProviderAuto, "":
+		return defaultProvider(), nil
+	case ProviderNMState:
+		return newNMStateProvider()
+	case ProviderNetlink:
+		return NetlinkProvider{}, nil
+	case ProviderFixture:
+		if fixturePath == "" {
+			return nil, fmt.Errorf("the %s provider requires a fixture file", ProviderFixture)
+		}
+		return FixtureProvider{Path: fixturePath}, nil
+	}
+	return nil, fmt.Errorf("unknown network state provider %q", name)
+}
+
+// SetProvider replaces the provider of the network state. It must be
+// called before the state is retrieved for the first time.
+func SetProvider(p Provider) {
+	provider = p
+}
+
+// CurrentProvider returns the provider of the network state
+func CurrentProvider() Provider {
+	return provider
+}
+
+// RetrieveNetState returns the current network state, as reported by
+// the configured provider
+func RetrieveNetState() (NetState, error) {
+	var netState NetState
+
+	state, err := RetrieveRawNetState()
+	if err != nil {
+		return netState, err
+	}
+
+	if err := json.Unmarshal(state, &netState); err != nil {
+		return netState, err
+	}
+	return netState, nil
+}
+
+// RetrieveRawNetState returns the JSON document reported by the
+// provider, including the settings not decoded by NetState
+func RetrieveRawNetState() ([]byte, error) {
+	return provider.RetrieveRaw()
+}
+
+// applier returns the provider as an Applier, if it can apply changes
+func applier() (Applier, error) {
+	a, ok := provider.(Applier)
+	if !ok {
+		return nil, fmt.Errorf("the %s network state provider can't apply changes", provider.Name())
+	}
+	return a, nil
+}
+
+// ApplyWithCheckpoint applies the desired state, a DesiredState or a
+// document already in JSON format, without committing it. The changes
+// are rolled back automatically once the timeout expires, unless
+// confirmed with CommitCheckpoint.
+func ApplyWithCheckpoint(state interface{}, timeout time.Duration) error {
+	a, err := applier()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return a.ApplyWithCheckpoint(data, timeout)
+}
+
+// CreateCheckpoint creates a checkpoint without changing the network
+// configuration, so that the changes made meanwhile by another tool,
+// such as nmtui, are rolled back automatically once the timeout
+// expires, unless confirmed with CommitCheckpoint.
+func CreateCheckpoint(timeout time.Duration) error {
+	return ApplyWithCheckpoint(DesiredState{}, timeout)
+}
+
+// CommitCheckpoint confirms the changes applied by ApplyWithCheckpoint
+func CommitCheckpoint() error {
+	a, err := applier()
+	if err != nil {
+		return err
+	}
+	return a.CommitCheckpoint()
+}
+
+// RollbackCheckpoint restores the configuration preceding ApplyWithCheckpoint
+func RollbackCheckpoint() error {
+	a, err := applier()
+	if err != nil {
+		return err
+	}
+	return a.RollbackCheckpoint()
+}
@@ -0,0 +1,51 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProvider(t *testing.T) {
+	p, err := NewProvider(ProviderAuto, "")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultProvider(), p)
+
+	p, err = NewProvider(ProviderNetlink, "")
+	assert.NoError(t, err)
+	assert.Equal(t, ProviderNetlink, p.Name())
+
+	p, err = NewProvider(ProviderFixture, "testdata/netstate.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, FixtureProvider{Path: "testdata/netstate.yaml"}, p)
+
+	_, err = NewProvider(ProviderFixture, "")
+	assert.EqualError(t, err, "the fixture provider requires a fixture file")
+	_, err = NewProvider("networkd", "")
+	assert.EqualError(t, err, `unknown network state provider "networkd"`)
+}
+
+func TestFixtureProvider(t *testing.T) {
+	defer SetProvider(CurrentProvider())
+	SetProvider(FixtureProvider{Path: "testdata/netstate.yaml"})
+
+	ns, err := RetrieveNetState()
+	assert.NoError(t, err)
+	assert.Equal(t, "master-0", ns.Hostname.Running)
+	assert.Equal(t, []string{"eth0", "eth1"}, ns.Ports("bond0"))
+	assert.Equal(t, "bond0.100", ns.PrimaryDefaultRoute(FamilyIPv4).NextHopIface)
+	assert.Equal(t, "192.168.111.80/24", ns.getIfaceByName("bond0.100").IPv4.Addresses[0].String())
+
+	raw, err := RetrieveRawNetState()
+	assert.NoError(t, err)
+	assert.Contains(t, string(raw), `"search":["example.com"]`)
+
+	// the fixtures are read only
+	assert.EqualError(t, ApplyWithCheckpoint(DesiredState{}, time.Minute), "the fixture network state provider can't apply changes")
+	assert.EqualError(t, RollbackCheckpoint(), "the fixture network state provider can't apply changes")
+
+	SetProvider(FixtureProvider{Path: "testdata/missing.yaml"})
+	_, err = RetrieveNetState()
+	assert.ErrorContains(t, err, "failed to read the network state fixture")
+}
@@ -0,0 +1,153 @@
+package net
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The routing tables looked up by default
+const (
+	MainRouteTable = 254
+	// nmstate reports 0 for the routes of the main table in some versions
+	unspecifiedRouteTable = 0
+)
+
+type Family string
+
+const (
+	FamilyIPv4 Family = "ipv4"
+	FamilyIPv6 Family = "ipv6"
+)
+
+var Families = []Family{FamilyIPv4, FamilyIPv6}
+
+func (f Family) String() string {
+	if f == FamilyIPv6 {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
+type RouteRules struct {
+	Config []RouteRule `json:"config,omitempty"`
+}
+
+// RouteRule is a policy routing rule, selecting the routing table of
+// the packets matching its conditions
+type RouteRule struct {
+	Family     string `json:"family,omitempty"`
+	IPFrom     string `json:"ip-from,omitempty"`
+	IPTo       string `json:"ip-to,omitempty"`
+	Iif        string `json:"iif,omitempty"`
+	FWMark     int    `json:"fwmark,omitempty"`
+	Priority   int    `json:"priority,omitempty"`
+	RouteTable int    `json:"route-table,omitempty"`
+	Action     string `json:"action,omitempty"`
+}
+
+// String returns the rule in a format similar to ip rule
+func (r RouteRule) String() string {
+	items := []string{}
+	if r.Priority != 0 {
+		items = append(items, fmt.Sprintf("priority %d", r.Priority))
+	}
+	if r.IPFrom != "" {
+		items = append(items, "from "+r.IPFrom)
+	}
+	if r.IPTo != "" {
+		items = append(items, "to "+r.IPTo)
+	}
+	if r.Iif != "" {
+		items = append(items, "iif "+r.Iif)
+	}
+	if r.FWMark != 0 {
+		items = append(items, fmt.Sprintf("fwmark %#x", r.FWMark))
+	}
+	switch {
+	case r.Action != "":
+		items = append(items, r.Action)
+	case r.RouteTable != 0:
+		items = append(items, fmt.Sprintf("lookup %d", r.RouteTable))
+	default:
+		items = append(items, "lookup main")
+	}
+	return strings.Join(items, " ")
+}
+
+// Family returns the address family of the route
+func (r Route) Family() Family {
+	if strings.Contains(r.Destination, ":") {
+		return FamilyIPv6
+	}
+	return FamilyIPv4
+}
+
+// IsDefault checks whether the route is a default route
+func (r Route) IsDefault() bool {
+	return IsIPv4DefaultRoute(r.Destination) || IsIPv6DefaultRoute(r.Destination)
+}
+
+// InMainTable checks whether the route is used without policy routing
+func (r Route) InMainTable() bool {
+	return r.TableID == MainRouteTable || r.TableID == unspecifiedRouteTable
+}
+
+// DefaultRoutes returns the default routes of the family, those of the
+// main table first, ordered by metric
+func (ns *NetState) DefaultRoutes(family Family) []Route {
+	routes := []Route{}
+	for _, route := range ns.Routes.Running {
+		if route.IsDefault() && route.Family() == family {
+			routes = append(routes, route)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].InMainTable() != routes[j].InMainTable() {
+			return routes[i].InMainTable()
+		}
+		return routes[i].Metric < routes[j].Metric
+	})
+	return routes
+}
+
+// PrimaryDefaultRoute returns the default route of the family used by
+// the traffic not matching any route rule, nil if there is none
+func (ns *NetState) PrimaryDefaultRoute(family Family) *Route {
+	routes := ns.DefaultRoutes(family)
+	if len(routes) == 0 || !routes[0].InMainTable() {
+		return nil
+	}
+	return &routes[0]
+}
+
+// PrimaryIfaces maps the name of the interface of each primary default
+// route to its families
+func (ns *NetState) PrimaryIfaces() map[string][]Family {
+	ifaces := map[string][]Family{}
+	for _, family := range Families {
+		if route := ns.PrimaryDefaultRoute(family); route != nil && ns.getIfaceByName(route.NextHopIface) != nil {
+			ifaces[route.NextHopIface] = append(ifaces[route.NextHopIface], family)
+		}
+	}
+	return ifaces
+}
+
+// Rules returns the route rules, if any
+func (ns *NetState) Rules() []RouteRule {
+	if ns.RouteRules == nil {
+		return nil
+	}
+	return ns.RouteRules.Config
+}
+
+// RulesForTable returns the route rules looking up the table
+func (ns *NetState) RulesForTable(table int) []RouteRule {
+	rules := []RouteRule{}
+	for _, rule := range ns.Rules() {
+		if rule.RouteTable == table {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
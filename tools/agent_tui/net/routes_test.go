@@ -0,0 +1,50 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRoutes(t *testing.T) {
+	ns := NetState{
+		Routes: RoutesRC{Running: []Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth1", NextHopAddr: "10.0.0.1", Metric: 200, TableID: 254},
+			{Destination: "0.0.0.0/0", NextHopIface: "eth2", NextHopAddr: "172.16.0.1", Metric: 50, TableID: 100},
+			{Destination: "192.168.111.0/24", NextHopIface: "eth0", Metric: 100, TableID: 254},
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1", Metric: 100, TableID: 254},
+			{Destination: "::/0", NextHopIface: "eth1", NextHopAddr: "fd00::1", Metric: 1024},
+		}},
+		RouteRules: &RouteRules{Config: []RouteRule{
+			{IPFrom: "172.16.0.0/24", Priority: 100, RouteTable: 100},
+			{IPTo: "10.1.0.0/16", Priority: 200},
+		}},
+		Ifaces: []Iface{
+			{Name: "eth0", Type: "ethernet"},
+			{Name: "eth1", Type: "ethernet"},
+			{Name: "eth2", Type: "ethernet"},
+		},
+	}
+
+	routes := ns.DefaultRoutes(FamilyIPv4)
+	assert.Equal(t, []string{"eth0", "eth1", "eth2"}, []string{routes[0].NextHopIface, routes[1].NextHopIface, routes[2].NextHopIface})
+	assert.Equal(t, "192.168.111.1", ns.PrimaryDefaultRoute(FamilyIPv4).NextHopAddr)
+	assert.Equal(t, "fd00::1", ns.PrimaryDefaultRoute(FamilyIPv6).NextHopAddr)
+	assert.Equal(t, map[string][]Family{"eth0": {FamilyIPv4}, "eth1": {FamilyIPv6}}, ns.PrimaryIfaces())
+
+	assert.Equal(t, []RouteRule{ns.RouteRules.Config[0]}, ns.RulesForTable(100))
+	assert.Equal(t, "priority 100 from 172.16.0.0/24 lookup 100", ns.RouteRules.Config[0].String())
+	assert.Equal(t, "priority 200 to 10.1.0.0/16 lookup main", ns.RouteRules.Config[1].String())
+
+	// the gateway is taken from the routes of the main table only
+	settings := NewIfaceSettings(ns, ns.Ifaces[1])
+	assert.Equal(t, "10.0.0.1", settings.IPv4.Gateway.String())
+	assert.Equal(t, "fd00::1", settings.IPv6.Gateway.String())
+	settings = NewIfaceSettings(ns, ns.Ifaces[2])
+	assert.Nil(t, settings.IPv4.Gateway)
+
+	// only policy routes
+	ns.Routes.Running = ns.Routes.Running[1:2]
+	assert.Nil(t, ns.PrimaryDefaultRoute(FamilyIPv4))
+	assert.Empty(t, ns.PrimaryIfaces())
+}
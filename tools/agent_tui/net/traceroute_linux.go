@@ -0,0 +1,334 @@
+package net
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// TracerouteProtocol is the kind of the probes sent by Traceroute
+type TracerouteProtocol string
+
+const (
+	TracerouteUDP  TracerouteProtocol = "udp"
+	TracerouteICMP TracerouteProtocol = "icmp"
+)
+
+const (
+	// the origins of the extended errors, as defined in
+	// linux/errqueue.h
+	soEEOriginICMP  = 2
+	soEEOriginICMP6 = 3
+
+	sizeofSockExtendedErr = 16
+
+	icmpEchoReply         = 0
+	icmpEchoRequest       = 8
+	icmpDestUnreachable   = 3
+	icmpTimeExceeded      = 11
+	icmpPortUnreachable   = 3
+	icmpv6EchoRequest     = 128
+	icmpv6EchoReply       = 129
+	icmpv6DestUnreachable = 1
+	icmpv6TimeExceeded    = 3
+	icmpv6PortUnreachable = 4
+
+	// the first destination port of the UDP probes, as in traceroute
+	tracerouteBasePort = 33434
+)
+
+// TracerouteOptions are the parameters of a traceroute
+type TracerouteOptions struct {
+	Protocol TracerouteProtocol
+	MaxHops  int
+	Probes   int
+	Timeout  time.Duration
+}
+
+// DefaultTracerouteOptions are the options of the traceroute command
+var DefaultTracerouteOptions = TracerouteOptions{
+	Protocol: TracerouteICMP,
+	MaxHops:  30,
+	Probes:   3,
+	Timeout:  time.Second,
+}
+
+// Hop is a router along the path, or the destination itself
+type Hop struct {
+	TTL int
+	// Addr is empty if no probe was answered
+	Addr string
+	// RTTs has an item for each probe, 0 for the unanswered ones
+	RTTs []time.Duration
+	// Reached is set when the destination answered
+	Reached bool
+	// Unreachable is set when a router reported that the destination
+	// can't be reached
+	Unreachable bool
+}
+
+func (h Hop) String() string {
+	// the address is omitted when no probe was answered, as in traceroute
+	fields := []string{fmt.Sprintf("%2d", h.TTL)}
+	if h.Addr != "" {
+		fields = append(fields, h.Addr)
+	}
+	for _, rtt := range h.RTTs {
+		if rtt == 0 {
+			fields = append(fields, "*")
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%.3f ms", float64(rtt.Microseconds())/1000))
+	}
+	if h.Unreachable {
+		fields = append(fields, "!unreachable")
+	}
+	return strings.Join(fields, "  ")
+}
+
+// probeResult is the answer to a single probe
+type probeResult struct {
+	addr        net.IP
+	rtt         time.Duration
+	reached     bool
+	unreachable bool
+}
+
+// Traceroute sends probes toward dst with an increasing TTL, calling
+// hop for each TTL, until the destination answers, a router reports it
+// unreachable, the maximum number of hops is reached or stop is closed.
+// The probes use datagram sockets, the ICMP ones need the group of the
+// process to be allowed by net.ipv4.ping_group_range, or else the
+// CAP_NET_RAW capability.
+func Traceroute(dst net.IP, opts TracerouteOptions, hop func(Hop), stop <-chan struct{}) error {
+	for ttl := 1; ttl <= opts.MaxHops; ttl++ {
+		h := Hop{TTL: ttl}
+		for i := 0; i < opts.Probes; i++ {
+			select {
+			case <-stop:
+				return nil
+			default:
+			}
+			result, err := sendProbe(dst, opts.Protocol, ttl, tracerouteBasePort+(ttl-1)*opts.Probes+i, opts.Timeout)
+			if err != nil {
+				return err
+			}
+			h.RTTs = append(h.RTTs, result.rtt)
+			if result.addr != nil {
+				h.Addr = result.addr.String()
+			}
+			h.Reached = h.Reached || result.reached
+			h.Unreachable = h.Unreachable || result.unreachable
+		}
+		hop(h)
+		if h.Reached || h.Unreachable {
+			return nil
+		}
+	}
+	return nil
+}
+
+// sendProbe sends a probe with the given TTL from a new socket, so that
+// the errors received can only be about it
+func sendProbe(dst net.IP, protocol TracerouteProtocol, ttl int, port int, timeout time.Duration) (probeResult, error) {
+	v4 := dst.To4() != nil
+	family, level, ttlOpt, recvErrOpt := syscall.AF_INET, syscall.IPPROTO_IP, syscall.IP_TTL, syscall.IP_RECVERR
+	proto := syscall.IPPROTO_ICMP
+	if !v4 {
+		family, level, ttlOpt, recvErrOpt = syscall.AF_INET6, syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, syscall.IPV6_RECVERR
+		proto = syscall.IPPROTO_ICMPV6
+	}
+	if protocol == TracerouteUDP {
+		proto = syscall.IPPROTO_UDP
+	}
+
+	raw := false
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, proto)
+	if err != nil && protocol == TracerouteICMP && (errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EPERM)) {
+		// the ping sockets are not allowed to the group of the process,
+		// the raw ones need the CAP_NET_RAW capability
+		raw = true
+		fd, err = syscall.Socket(family, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, proto)
+		if err != nil {
+			return probeResult{}, fmt.Errorf("cannot open the ICMP socket, check net.ipv4.ping_group_range: %w", err)
+		}
+	}
+	if err != nil {
+		return probeResult{}, fmt.Errorf("cannot open the probe socket: %w", err)
+	}
+	defer syscall.Close(fd)
+	if err := syscall.SetsockoptInt(fd, level, ttlOpt, ttl); err != nil {
+		return probeResult{}, err
+	}
+	if err := syscall.SetsockoptInt(fd, level, recvErrOpt, 1); err != nil {
+		return probeResult{}, err
+	}
+
+	// the port of the raw sockets must be zero
+	dstPort := port
+	if protocol == TracerouteICMP {
+		dstPort = 0
+	}
+	var to syscall.Sockaddr
+	if v4 {
+		sa := &syscall.SockaddrInet4{Port: dstPort}
+		copy(sa.Addr[:], dst.To4())
+		to = sa
+	} else {
+		sa := &syscall.SockaddrInet6{Port: dstPort}
+		copy(sa.Addr[:], dst.To16())
+		to = sa
+	}
+	payload := make([]byte, 32)
+	id := uint16(os.Getpid())
+	if protocol == TracerouteICMP {
+		// the kernel sets the identifier of the ping sockets, and the
+		// checksum of the ICMPv6 messages
+		payload[0] = icmpEchoRequest
+		if !v4 {
+			payload[0] = icmpv6EchoRequest
+		}
+		binary.BigEndian.PutUint16(payload[4:6], id)
+		binary.BigEndian.PutUint16(payload[6:8], uint16(port))
+		if raw && v4 {
+			binary.BigEndian.PutUint16(payload[2:4], inetChecksum(payload))
+		}
+	}
+
+	// the reads are interrupted periodically to check the timeout
+	tv := syscall.NsecToTimeval((100 * time.Millisecond).Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		return probeResult{}, err
+	}
+
+	start := time.Now()
+	if err := syscall.Sendto(fd, payload, 0, to); err != nil {
+		// an error reported by a router before the send completed
+		if result, ok := readProbeError(fd, start); ok {
+			return result, nil
+		}
+		return probeResult{}, err
+	}
+
+	buf := make([]byte, 512)
+	for time.Since(start) < timeout {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			// the ICMP errors queued are reported by the reads, and
+			// checked again when they time out
+			if result, ok := readProbeError(fd, start); ok {
+				return result, nil
+			}
+			continue
+		}
+		// only the echo replies are received on the ping sockets, while
+		// the raw ones receive all the ICMP messages
+		if !raw || isEchoReply(buf[:n], v4, id, uint16(port)) {
+			return probeResult{addr: dst, rtt: time.Since(start), reached: true}, nil
+		}
+	}
+	return probeResult{}, nil
+}
+
+// isEchoReply checks whether a message received on a raw ICMP socket is
+// the reply to the echo request sent. The IPv4 messages include the IP
+// header.
+func isEchoReply(msg []byte, v4 bool, id uint16, seq uint16) bool {
+	replyType := byte(icmpEchoReply)
+	if v4 {
+		if len(msg) < 20 {
+			return false
+		}
+		msg = msg[int(msg[0]&0x0f)*4:]
+	} else {
+		replyType = icmpv6EchoReply
+	}
+	return len(msg) >= 8 && msg[0] == replyType &&
+		binary.BigEndian.Uint16(msg[4:6]) == id && binary.BigEndian.Uint16(msg[6:8]) == seq
+}
+
+// inetChecksum returns the internet checksum of an ICMP message, an
+// IPv4 header or an UDP datagram with its pseudo-header
+func inetChecksum(msg []byte) uint16 {
+	sum := uint32(0)
+	for i := 0; i+1 < len(msg); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(msg[i : i+2]))
+	}
+	if len(msg)%2 == 1 {
+		sum += uint32(msg[len(msg)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+// readProbeError reads the ICMP error queued on the socket, if any
+func readProbeError(fd int, start time.Time) (probeResult, bool) {
+	buf := make([]byte, 512)
+	oob := make([]byte, 512)
+	_, oobn, _, _, err := syscall.Recvmsg(fd, buf, oob, syscall.MSG_ERRQUEUE|syscall.MSG_DONTWAIT)
+	if err != nil {
+		return probeResult{}, false
+	}
+	rtt := time.Since(start)
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return probeResult{}, false
+	}
+	for _, msg := range msgs {
+		if (msg.Header.Level != syscall.IPPROTO_IP || msg.Header.Type != syscall.IP_RECVERR) &&
+			(msg.Header.Level != syscall.IPPROTO_IPV6 || msg.Header.Type != syscall.IPV6_RECVERR) {
+			continue
+		}
+		result, ok := parseRecvErr(msg.Data)
+		if ok {
+			result.rtt = rtt
+			return result, true
+		}
+	}
+	return probeResult{}, false
+}
+
+// parseRecvErr parses a sock_extended_err followed by the address of
+// the host that sent the ICMP error
+func parseRecvErr(data []byte) (probeResult, bool) {
+	if len(data) < sizeofSockExtendedErr+8 {
+		return probeResult{}, false
+	}
+	origin, icmpType, code := data[4], data[5], data[6]
+	offender := data[sizeofSockExtendedErr:]
+
+	result := probeResult{}
+	switch binary.NativeEndian.Uint16(offender[0:2]) {
+	case syscall.AF_INET:
+		result.addr = net.IP(append([]byte{}, offender[4:8]...))
+	case syscall.AF_INET6:
+		if len(offender) < 24 {
+			return probeResult{}, false
+		}
+		result.addr = net.IP(append([]byte{}, offender[8:24]...))
+	default:
+		return probeResult{}, false
+	}
+
+	switch {
+	case origin == soEEOriginICMP && icmpType == icmpTimeExceeded,
+		origin == soEEOriginICMP6 && icmpType == icmpv6TimeExceeded:
+	case origin == soEEOriginICMP && icmpType == icmpDestUnreachable && code == icmpPortUnreachable,
+		origin == soEEOriginICMP6 && icmpType == icmpv6DestUnreachable && code == icmpv6PortUnreachable:
+		// the UDP probes are answered by the destination this way
+		result.reached = true
+	case origin == soEEOriginICMP && icmpType == icmpDestUnreachable,
+		origin == soEEOriginICMP6 && icmpType == icmpv6DestUnreachable:
+		result.unreachable = true
+	default:
+		return probeResult{}, false
+	}
+	return result, true
+}
@@ -0,0 +1,118 @@
+package net
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracerouteLoopback(t *testing.T) {
+	for _, protocol := range []TracerouteProtocol{TracerouteUDP, TracerouteICMP} {
+		for _, dst := range []string{"127.0.0.1", "::1"} {
+			hops := []Hop{}
+			opts := TracerouteOptions{Protocol: protocol, MaxHops: 3, Probes: 2, Timeout: time.Second}
+			err := Traceroute(net.ParseIP(dst), opts, func(h Hop) { hops = append(hops, h) }, nil)
+			if err != nil {
+				// the ICMP sockets may not be allowed
+				t.Logf("%s traceroute to %s skipped: %v", protocol, dst, err)
+				continue
+			}
+			if assert.Len(t, hops, 1, "%s %s", protocol, dst) {
+				assert.Equal(t, dst, hops[0].Addr)
+				assert.True(t, hops[0].Reached)
+				assert.Len(t, hops[0].RTTs, 2)
+			}
+		}
+	}
+}
+
+// The probe sockets may be numbered above FD_SETSIZE in a process with
+// many files open
+func TestTracerouteHighFD(t *testing.T) {
+	for {
+		f, err := os.Open(os.DevNull)
+		if err != nil {
+			t.Skipf("cannot open enough files: %v", err)
+		}
+		t.Cleanup(func() { f.Close() })
+		if f.Fd() >= 1024 {
+			break
+		}
+	}
+
+	hops := []Hop{}
+	opts := TracerouteOptions{Protocol: TracerouteUDP, MaxHops: 3, Probes: 1, Timeout: time.Second}
+	assert.NoError(t, Traceroute(net.ParseIP("127.0.0.1"), opts, func(h Hop) { hops = append(hops, h) }, nil))
+	if assert.Len(t, hops, 1) {
+		assert.True(t, hops[0].Reached)
+	}
+}
+
+func TestParseRecvErr(t *testing.T) {
+	recvErr := func(origin, icmpType, code byte, offender net.IP) []byte {
+		data := make([]byte, sizeofSockExtendedErr)
+		data[4], data[5], data[6] = origin, icmpType, code
+		if ip4 := offender.To4(); ip4 != nil {
+			sa := make([]byte, 16)
+			binary.NativeEndian.PutUint16(sa[0:2], syscall.AF_INET)
+			copy(sa[4:8], ip4)
+			return append(data, sa...)
+		}
+		sa := make([]byte, 28)
+		binary.NativeEndian.PutUint16(sa[0:2], syscall.AF_INET6)
+		copy(sa[8:24], offender)
+		return append(data, sa...)
+	}
+
+	result, ok := parseRecvErr(recvErr(soEEOriginICMP, icmpTimeExceeded, 0, net.ParseIP("192.168.111.1")))
+	assert.True(t, ok)
+	assert.Equal(t, "192.168.111.1", result.addr.String())
+	assert.False(t, result.reached)
+
+	result, ok = parseRecvErr(recvErr(soEEOriginICMP, icmpDestUnreachable, icmpPortUnreachable, net.ParseIP("192.0.2.10")))
+	assert.True(t, ok)
+	assert.True(t, result.reached)
+
+	result, ok = parseRecvErr(recvErr(soEEOriginICMP6, icmpv6DestUnreachable, 0, net.ParseIP("fd00::1")))
+	assert.True(t, ok)
+	assert.Equal(t, "fd00::1", result.addr.String())
+	assert.True(t, result.unreachable)
+
+	result, ok = parseRecvErr(recvErr(soEEOriginICMP6, icmpv6TimeExceeded, 0, net.ParseIP("fd00::1")))
+	assert.True(t, ok)
+	assert.False(t, result.reached || result.unreachable)
+
+	// a local error, not sent by a router
+	_, ok = parseRecvErr(recvErr(1, 0, 0, net.ParseIP("192.0.2.10")))
+	assert.False(t, ok)
+	_, ok = parseRecvErr(make([]byte, 8))
+	assert.False(t, ok)
+}
+
+func TestICMPEcho(t *testing.T) {
+	request := make([]byte, 16)
+	request[0] = icmpEchoRequest
+	binary.BigEndian.PutUint16(request[4:6], 0x1234)
+	binary.BigEndian.PutUint16(request[6:8], 33434)
+	binary.BigEndian.PutUint16(request[2:4], inetChecksum(request))
+	// the checksum of a message including its checksum is zero
+	assert.Equal(t, uint16(0), inetChecksum(request))
+
+	reply := append([]byte{0x45, 0, 0, 36, 0, 0, 0, 0, 64, 1, 0, 0, 127, 0, 0, 1, 127, 0, 0, 1}, request...)
+	reply[20] = icmpEchoReply
+	assert.True(t, isEchoReply(reply, true, 0x1234, 33434))
+	assert.False(t, isEchoReply(reply, true, 0x1234, 33435))
+	assert.False(t, isEchoReply(append(reply[:20:20], request...), true, 0x1234, 33434))
+}
+
+func TestHopString(t *testing.T) {
+	assert.Equal(t, " 1  192.168.111.1  0.512 ms  *  1.250 ms",
+		Hop{TTL: 1, Addr: "192.168.111.1", RTTs: []time.Duration{512 * time.Microsecond, 0, 1250 * time.Microsecond}}.String())
+	assert.Equal(t, "12  *  *  *", Hop{TTL: 12, RTTs: []time.Duration{0, 0, 0}}.String())
+	assert.Equal(t, " 3  10.0.0.1  2.000 ms  !unreachable", Hop{TTL: 3, Addr: "10.0.0.1", RTTs: []time.Duration{2 * time.Millisecond}, Unreachable: true}.String())
+}
@@ -0,0 +1,22 @@
+package net
+
+import "gopkg.in/yaml.v3"
+
+// JSONToYAML converts a JSON document to YAML, preserving the
+// order of the fields
+func JSONToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// resetStyle switches the nodes parsed from JSON to the block style
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetStyle(n)
+	}
+}
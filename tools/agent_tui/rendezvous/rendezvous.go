@@ -0,0 +1,89 @@
+package rendezvous
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"text/template"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	// HostEnvPath is the default file where the rendezvous IP is stored
+	HostEnvPath = "/etc/assisted/rendezvous-host.env"
+	// TemplateValue is the placeholder found in the env file when the
+	// rendezvous IP was not configured yet
+	TemplateValue = "{{.RendezvousIP}}"
+)
+
+type hostEnvTemplateData struct {
+	RendezvousIP string
+}
+
+// ValidateIP returns an error message if ipAddress is not a valid IP
+// address, or an empty string otherwise
+func ValidateIP(ipAddress string) string {
+	if net.ParseIP(ipAddress) == nil {
+		return fmt.Sprintf("%s is not a valid IP address", ipAddress)
+	}
+	return ""
+}
+
+// CheckConnectivity verifies that the assisted-service API is reachable
+// on the rendezvous IP, returning the curl output
+func CheckConnectivity(ipAddress string, timeout time.Duration) ([]byte, error) {
+	url := fmt.Sprintf("http://%s:8090/api/assisted-install/v2", ipAddress)
+	return exec.Command("curl", fmt.Sprintf("-m %v", timeout.Seconds()), url).CombinedOutput()
+}
+
+// Read returns the rendezvous IP stored in the env file, or an empty
+// string if it's missing or not configured yet
+func Read(path string) string {
+	envMap, err := godotenv.Read(path)
+	if err != nil {
+		return ""
+	}
+	nodeZeroIP := envMap["NODE_ZERO_IP"]
+	if nodeZeroIP == TemplateValue {
+		nodeZeroIP = ""
+	}
+	return nodeZeroIP
+}
+
+// Save stores the rendezvous IP in the env file
+func Save(path string, ipAddress string) error {
+	return TemplateHostEnv(path, &hostEnvTemplateData{
+		RendezvousIP: ipAddress,
+	})
+}
+
+// TemplateHostEnv renders the env file using its template, if present,
+// or the file itself otherwise
+func TemplateHostEnv(path string, templateData interface{}) error {
+	data, err := os.ReadFile(fmt.Sprintf("%s.template", path))
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	tmpl := template.New(path)
+	tmpl, err = tmpl.Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, templateData); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,378 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_BOND_WIZARD string = "bondWizard"
+
+	FIELD_BOND_NAME    string = "Bond name: "
+	FIELD_BOND_MODE    string = "Bond mode: "
+	FIELD_BOND_VLAN_ID string = "VLAN ID: "
+
+	BOND_WIZARD_NEXT_BUTTON string = "<Next>"
+	BOND_WIZARD_BACK_BUTTON string = "<Previous>"
+
+	BOND_WIZARD_DEFAULT_NAME = "bond0"
+
+	BOND_WIZARD_PORTS_TEXT   = "Select the physical interfaces to be bonded. Their current IP settings will be removed."
+	BOND_WIZARD_BOND_TEXT    = "Choose the bond name and mode. Set the VLAN ID to create a tagged VLAN on top of the bond, or leave it empty for untagged traffic."
+	BOND_WIZARD_IP_TEXT      = "Configure the IP settings of %s. Addresses use the <ip>/<prefix length> format, multiple values are comma separated."
+	BOND_WIZARD_SUMMARY_TEXT = "The following NMState configuration will be applied, then the link of %s will be verified."
+	BOND_WIZARD_NO_NICS_TEXT = "No physical network interface has been found."
+	BOND_WIZARD_NO_PORTS     = "Select at least one interface."
+	BOND_WIZARD_VLAN_ID      = "VLAN ID %s is not a number"
+
+	// Time for the link of the new interface to come up, including
+	// the LACP negotiation of 802.3ad bonds
+	bondCarrierTimeout = 30 * time.Second
+)
+
+const (
+	bondWizardPortsStep = iota
+	bondWizardBondStep
+	bondWizardIPStep
+	bondWizardSummaryStep
+)
+
+var bondWizardTitles = []string{"Ports", "Bond", "IP settings", "Summary"}
+
+// bondWizard holds the values entered in the wizard steps
+type bondWizard struct {
+	step     int
+	netState net.NetState
+	nics     []net.NIC
+	ports    map[string]bool
+	name     string
+	mode     string
+	vlanID   string
+	fields   ifaceFields
+	dns      string
+}
+
+func newBondWizard(netState net.NetState, nics []net.NIC) *bondWizard {
+	return &bondWizard{
+		netState: netState,
+		nics:     nics,
+		ports:    map[string]bool{},
+		name:     BOND_WIZARD_DEFAULT_NAME,
+		mode:     net.BondModes[0],
+		fields: ifaceFields{
+			ipv4Mode: IP_MODE_DHCP,
+			ipv6Mode: IP_MODE_DHCP,
+		},
+		dns: strings.Join(netState.DNS.Running.Servers, ", "),
+	}
+}
+
+// selectedPorts returns the ports in the order of the interfaces
+func (w *bondWizard) selectedPorts() []string {
+	ports := []string{}
+	for _, nic := range w.nics {
+		if w.ports[nic.Name] {
+			ports = append(ports, nic.Name)
+		}
+	}
+	return ports
+}
+
+// settings parses and validates the values of all the steps
+func (w *bondWizard) settings() (net.BondSettings, error) {
+	settings := net.BondSettings{
+		Name:  strings.TrimSpace(w.name),
+		Mode:  w.mode,
+		Ports: w.selectedPorts(),
+	}
+	if vlanID := strings.TrimSpace(w.vlanID); vlanID != "" {
+		id, err := strconv.Atoi(vlanID)
+		if err != nil {
+			return settings, fmt.Errorf(BOND_WIZARD_VLAN_ID, vlanID)
+		}
+		settings.VLANID = id
+	}
+
+	edit := &ifaceEdit{name: settings.IPIfaceName(), fields: w.fields}
+	ipSettings, err := edit.settings()
+	if err != nil {
+		return settings, err
+	}
+	settings.MTU = ipSettings.MTU
+	settings.IPv4 = ipSettings.IPv4
+	settings.IPv6 = ipSettings.IPv6
+	// the DNS servers are provided by the DHCP server otherwise
+	if w.fields.ipv4Mode == IP_MODE_STATIC || w.fields.ipv6Mode == IP_MODE_STATIC {
+		settings.DNS = &net.DNSConfig{
+			Servers:       splitList(w.dns),
+			SearchDomains: w.netState.DNS.Running.SearchDomains,
+		}
+	}
+	return settings, settings.Validate(w.netState)
+}
+
+func (u *UI) createBondWizardPage() {
+	u.bondWizardText = tview.NewTextView().
+		SetTextColor(newt.ColorBlack).
+		SetWordWrap(true)
+	u.bondWizardText.SetBackgroundColor(newt.ColorGray)
+
+	u.bondWizardForm = tview.NewForm()
+	u.bondWizardForm.SetItemPadding(0)
+	u.bondWizardForm.SetFieldTextColor(newt.ColorGray)
+
+	u.bondWizardSummary = tview.NewTextView()
+	u.bondWizardSummary.SetBackgroundColor(newt.ColorGray)
+	u.bondWizardSummary.SetTextColor(newt.ColorBlack)
+
+	u.bondWizardButtons = tview.NewForm()
+	u.bondWizardButtons.SetButtonsAlign(tview.AlignCenter)
+	u.bondWizardButtons.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
+		Foreground(newt.ColorGray))
+	u.bondWizardButtons.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
+		Foreground(newt.ColorBlack))
+
+	u.bondWizardFlex = tview.NewFlex().SetDirection(tview.FlexRow)
+	u.bondWizardFlex.SetTitleColor(newt.ColorRed).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	u.bondWizardFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			u.focusedItem++
+			if u.focusedItem > len(u.focusableItems)-1 {
+				u.focusedItem = 0
+			}
+		case tcell.KeyBacktab:
+			u.focusedItem--
+			if u.focusedItem < 0 {
+				u.focusedItem = len(u.focusableItems) - 1
+			}
+		case tcell.KeyESC:
+			if u.focusedItem == 0 {
+				u.bondWizardDone()
+				return nil
+			}
+			return event
+		default:
+			return event
+		}
+		u.app.SetFocus(u.focusableItems[u.focusedItem])
+		return nil
+	})
+
+	width := 80
+	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(u.bondWizardFlex, 20, 0, false).
+		AddItem(nil, 0, 1, false)
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(innerFlex, width, 1, false).
+		AddItem(nil, 0, 1, false)
+
+	u.pages.AddPage(PAGE_BOND_WIZARD, flex, true, false)
+}
+
+// showBondWizard guides the user through the creation of a bond, with
+// an optional VLAN on top of it. The doneFunc callback is used when
+// the wizard is cancelled or the configuration applied.
+func (u *UI) showBondWizard(doneFunc func()) {
+	u.bondWizardDone = func() {
+		u.focusedItem = 0
+		doneFunc()
+	}
+
+	netState, err := u.retrieveNetState()
+	if err != nil {
+		u.logger.Infof("failed to retrieve the network state: %v", err)
+		u.showNetModal(fmt.Sprintf(NET_EDITOR_RETRIEVE_FAILED, err), []string{BACK_BUTTON}, func(string) {
+			u.bondWizardDone()
+		})
+		return
+	}
+	nics := u.physicalNICs(netState)
+	if len(nics) == 0 {
+		u.showNetModal(BOND_WIZARD_NO_NICS_TEXT, []string{BACK_BUTTON}, func(string) {
+			u.bondWizardDone()
+		})
+		return
+	}
+
+	u.bondWizard = newBondWizard(netState, nics)
+	u.showBondWizardStep(bondWizardPortsStep)
+}
+
+// showBondWizardStep rebuilds the page with the fields of the step
+func (u *UI) showBondWizardStep(step int) {
+	w := u.bondWizard
+	w.step = step
+	form := u.bondWizardForm
+	form.Clear(true)
+
+	switch step {
+	case bondWizardPortsStep:
+		u.bondWizardText.SetText(BOND_WIZARD_PORTS_TEXT)
+		for _, nic := range w.nics {
+			name := nic.Name
+			form.AddCheckbox(formatNIC(nic), w.ports[name], func(checked bool) {
+				w.ports[name] = checked
+			})
+		}
+	case bondWizardBondStep:
+		u.bondWizardText.SetText(BOND_WIZARD_BOND_TEXT)
+		mode := 0
+		for i, m := range net.BondModes {
+			if m == w.mode {
+				mode = i
+			}
+		}
+		form.
+			AddInputField(FIELD_BOND_NAME, w.name, 15, nil, func(text string) { w.name = text }).
+			AddDropDown(FIELD_BOND_MODE, net.BondModes, mode, func(option string, index int) { w.mode = option }).
+			AddInputField(FIELD_BOND_VLAN_ID, w.vlanID, 5, tview.InputFieldInteger, func(text string) { w.vlanID = text }).
+			AddInputField(FIELD_MTU, w.fields.mtu, 6, tview.InputFieldInteger, func(text string) { w.fields.mtu = text })
+	case bondWizardIPStep:
+		settings, _ := w.settings()
+		u.bondWizardText.SetText(fmt.Sprintf(BOND_WIZARD_IP_TEXT, settings.IPIfaceName()))
+		f := &w.fields
+		form.
+			AddDropDown(FIELD_IPV4_MODE, ipModeOptions, ipModeIndex(f.ipv4Mode), func(option string, index int) { f.ipv4Mode = option }).
+			AddInputField(FIELD_IPV4_ADDRESSES, f.ipv4Addresses, 40, nil, func(text string) { f.ipv4Addresses = text }).
+			AddInputField(FIELD_IPV4_GATEWAY, f.ipv4Gateway, 40, nil, func(text string) { f.ipv4Gateway = text }).
+			AddDropDown(FIELD_IPV6_MODE, ipModeOptions, ipModeIndex(f.ipv6Mode), func(option string, index int) { f.ipv6Mode = option }).
+			AddInputField(FIELD_IPV6_ADDRESSES, f.ipv6Addresses, 40, nil, func(text string) { f.ipv6Addresses = text }).
+			AddInputField(FIELD_IPV6_GATEWAY, f.ipv6Gateway, 40, nil, func(text string) { f.ipv6Gateway = text }).
+			AddInputField(FIELD_DNS_SERVERS, w.dns, 40, nil, func(text string) { w.dns = text })
+	case bondWizardSummaryStep:
+		settings, _ := w.settings()
+		u.bondWizardText.SetText(fmt.Sprintf(BOND_WIZARD_SUMMARY_TEXT, settings.IPIfaceName()))
+		u.bondWizardSummary.SetText(formatDesiredState(settings.DesiredState())).ScrollToBeginning()
+	}
+
+	u.bondWizardButtons.ClearButtons()
+	if step > bondWizardPortsStep {
+		u.bondWizardButtons.AddButton(BOND_WIZARD_BACK_BUTTON, func() {
+			u.showBondWizardStep(w.step - 1)
+		})
+	}
+	if step < bondWizardSummaryStep {
+		u.bondWizardButtons.AddButton(BOND_WIZARD_NEXT_BUTTON, func() {
+			u.nextBondWizardStep()
+		})
+	} else {
+		u.bondWizardButtons.AddButton(NET_EDITOR_APPLY_BUTTON, func() {
+			u.applyBondWizard()
+		})
+	}
+	u.bondWizardButtons.AddButton(NET_EDITOR_CANCEL_BUTTON, func() {
+		u.bondWizardDone()
+	})
+
+	u.bondWizardFlex.Clear().AddItem(u.bondWizardText, 3, 0, false)
+	if step == bondWizardSummaryStep {
+		u.bondWizardFlex.AddItem(u.bondWizardSummary, 0, 1, false)
+	} else {
+		u.bondWizardFlex.AddItem(form, 0, 1, false)
+	}
+	u.bondWizardFlex.AddItem(u.bondWizardButtons, 3, 0, false)
+	u.bondWizardFlex.SetTitle(fmt.Sprintf("  Bond/VLAN wizard - %d/%d %s  ", step+1, len(bondWizardTitles), bondWizardTitles[step]))
+
+	u.focusableItems = []tview.Primitive{}
+	if step == bondWizardSummaryStep {
+		u.focusableItems = append(u.focusableItems, u.bondWizardSummary)
+	}
+	for i := 0; i < form.GetFormItemCount(); i++ {
+		u.focusableItems = append(u.focusableItems, form.GetFormItem(i))
+	}
+	for i := 0; i < u.bondWizardButtons.GetButtonCount(); i++ {
+		u.focusableItems = append(u.focusableItems, u.bondWizardButtons.GetButton(i))
+	}
+	u.setFocusToBondWizard()
+}
+
+func (u *UI) setFocusToBondWizard() {
+	u.focusedItem = 0
+	u.pages.SwitchToPage(PAGE_BOND_WIZARD)
+	u.app.SetFocus(u.focusableItems[0])
+}
+
+func formatNIC(nic net.NIC) string {
+	carrier := "no carrier"
+	if nic.Carrier {
+		carrier = "carrier"
+	}
+	return fmt.Sprintf("%-15s %-17s %-10s ", nic.Name, nic.MAC, carrier)
+}
+
+func ipModeIndex(mode string) int {
+	for i, option := range ipModeOptions {
+		if option == mode {
+			return i
+		}
+	}
+	return 0
+}
+
+func formatDesiredState(state net.DesiredState) string {
+	data, err := json.Marshal(state)
+	if err == nil {
+		data, err = net.JSONToYAML(data)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// nextBondWizardStep moves to the next step once the values entered
+// so far are valid
+func (u *UI) nextBondWizardStep() {
+	w := u.bondWizard
+	var err error
+	if w.step == bondWizardPortsStep {
+		if len(w.selectedPorts()) == 0 {
+			err = fmt.Errorf(BOND_WIZARD_NO_PORTS)
+		}
+	} else {
+		_, err = w.settings()
+	}
+	if err != nil {
+		u.showNetModal(err.Error(), []string{BACK_BUTTON}, func(string) {
+			u.showBondWizardStep(w.step)
+		})
+		return
+	}
+	u.showBondWizardStep(w.step + 1)
+}
+
+func (u *UI) applyBondWizard() {
+	w := u.bondWizard
+	settings, err := w.settings()
+	if err != nil {
+		u.showNetModal(err.Error(), []string{BACK_BUTTON}, func(string) {
+			u.showBondWizardStep(w.step)
+		})
+		return
+	}
+
+	u.showNetModal(NET_EDITOR_CONFIRM_TEXT, []string{NET_EDITOR_APPLY_BUTTON, NET_EDITOR_CANCEL_BUTTON}, func(label string) {
+		if label != NET_EDITOR_APPLY_BUTTON {
+			u.setFocusToBondWizard()
+			return
+		}
+		u.showNetModal(NET_EDITOR_APPLYING_TEXT, []string{}, nil)
+		go u.applyNetworkState(settings.DesiredState(), func() error {
+			return u.waitForCarrier(settings.IPIfaceName(), bondCarrierTimeout)
+		}, u.setFocusToBondWizard, u.bondWizardDone)
+	})
+}
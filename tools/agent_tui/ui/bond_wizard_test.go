@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBondWizard(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return testNetState(t), nil
+	}
+	ui.physicalNICs = func(ns net.NetState) []net.NIC {
+		return []net.NIC{
+			{Name: "eth0", MAC: "52:54:00:aa:bb:01", Carrier: true},
+			{Name: "eth1", MAC: "52:54:00:aa:bb:02", Carrier: false},
+		}
+	}
+
+	ui.showBondWizard(func() {})
+	page, _ := ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_BOND_WIZARD, page)
+	assert.Equal(t, 2, ui.bondWizardForm.GetFormItemCount())
+	assert.Contains(t, ui.bondWizardForm.GetFormItem(0).GetLabel(), "52:54:00:aa:bb:01 carrier")
+
+	// at least one port is required
+	ui.nextBondWizardStep()
+	page, _ = ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_NET_MODAL, page)
+	assert.Equal(t, bondWizardPortsStep, ui.bondWizard.step)
+
+	// SetChecked doesn't notify the changes
+	ui.bondWizard.ports["eth1"] = true
+	ui.bondWizard.ports["eth0"] = true
+	ui.nextBondWizardStep()
+	assert.Equal(t, bondWizardBondStep, ui.bondWizard.step)
+	assert.Equal(t, BOND_WIZARD_DEFAULT_NAME, ui.bondWizardForm.GetFormItemByLabel(FIELD_BOND_NAME).(*tview.InputField).GetText())
+
+	ui.bondWizardForm.GetFormItemByLabel(FIELD_BOND_VLAN_ID).(*tview.InputField).SetText("100")
+	ui.nextBondWizardStep()
+	assert.Equal(t, bondWizardIPStep, ui.bondWizard.step)
+
+	setDropDownOption(ui.bondWizardForm, FIELD_IPV4_MODE, IP_MODE_STATIC)
+	ui.nextBondWizardStep()
+	assert.Equal(t, bondWizardIPStep, ui.bondWizard.step, "static IPv4 requires an address")
+
+	ui.bondWizardForm.GetFormItemByLabel(FIELD_IPV4_ADDRESSES).(*tview.InputField).SetText("192.168.100.80/24")
+	ui.bondWizardForm.GetFormItemByLabel(FIELD_IPV4_GATEWAY).(*tview.InputField).SetText("192.168.100.1")
+	ui.nextBondWizardStep()
+	assert.Equal(t, bondWizardSummaryStep, ui.bondWizard.step)
+	summary := ui.bondWizardSummary.GetText(true)
+	assert.Contains(t, summary, "name: bond0.100")
+	assert.Contains(t, summary, "base-iface: bond0")
+	assert.Contains(t, summary, "- eth0\n")
+	assert.Contains(t, summary, "- eth1\n")
+	assert.Contains(t, summary, "next-hop-address: 192.168.100.1")
+
+	settings, err := ui.bondWizard.settings()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"eth0", "eth1"}, settings.Ports)
+	assert.Equal(t, "active-backup", settings.Mode)
+	assert.Equal(t, []string{"192.168.111.1"}, settings.DNS.Servers)
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/rivo/tview"
+)
+
+const (
+	// Maximum number of distinct outputs kept for each check
+	maxCheckOutputs = 100
+)
+
+// checkOutput is a distinct output produced by a failed check. Identical
+// outputs are merged together, keeping track of how many times they were
+// reported.
+type checkOutput struct {
+	heading string
+	text    string
+	count   int
+	first   time.Time
+	last    time.Time
+}
+
+// checkDetails keeps the recent failure outputs of each check, so that
+// they could be inspected separately without being interleaved.
+type checkDetails struct {
+	maxOutputs int
+	outputs    map[string][]*checkOutput // from the oldest to the newest
+	discarded  map[string]int
+}
+
+func newCheckDetails(maxOutputs int) *checkDetails {
+	return &checkDetails{
+		maxOutputs: maxOutputs,
+		outputs:    make(map[string][]*checkOutput),
+		discarded:  make(map[string]int),
+	}
+}
+
+// add records the output of a failed check. Returns true if the
+// details for the check were modified.
+func (d *checkDetails) add(heading string, cr checks.CheckResult) bool {
+	if cr.Success {
+		return false
+	}
+
+	outputs := d.outputs[cr.Type]
+	for i, o := range outputs {
+		if o.text == cr.Details {
+			o.count++
+			o.last = cr.Timestamp
+			// move the output at the end, since it's the most recent one
+			d.outputs[cr.Type] = append(append(outputs[:i:i], outputs[i+1:]...), o)
+			return true
+		}
+	}
+
+	outputs = append(outputs, &checkOutput{
+		heading: heading,
+		text:    cr.Details,
+		count:   1,
+		first:   cr.Timestamp,
+		last:    cr.Timestamp,
+	})
+	if len(outputs) > d.maxOutputs {
+		// the number of discarded outputs is always reported
+		// in the details, so that nothing gets lost silently
+		d.discarded[cr.Type] += len(outputs) - d.maxOutputs
+		outputs = outputs[len(outputs)-d.maxOutputs:]
+	}
+	d.outputs[cr.Type] = outputs
+	return true
+}
+
+// render returns the outputs of the specified check, from the most recent
+// one, optionally keeping only the ones containing the filter text.
+func (d *checkDetails) render(checkType string, filter string) string {
+	outputs := d.outputs[checkType]
+	if len(outputs) == 0 {
+		return "No errors reported for this check"
+	}
+
+	filter = strings.ToLower(filter)
+	var sb strings.Builder
+	matches := 0
+	for i := len(outputs) - 1; i >= 0; i-- {
+		o := outputs[i]
+		if filter != "" &&
+			!strings.Contains(strings.ToLower(o.text), filter) &&
+			!strings.Contains(strings.ToLower(o.heading), filter) {
+			continue
+		}
+		matches++
+
+		sb.WriteString(fmt.Sprintf("[red]%s:[-]", tview.Escape(o.heading)))
+		if o.count > 1 {
+			sb.WriteString(fmt.Sprintf(" (x%d, %s - %s)", o.count, o.first.Format("15:04:05"), o.last.Format("15:04:05")))
+		} else {
+			sb.WriteString(fmt.Sprintf(" (%s)", o.last.Format("15:04:05")))
+		}
+		sb.WriteString("\n")
+		sb.WriteString(tview.Escape(strings.TrimRight(o.text, "\n")))
+		sb.WriteString("\n\n")
+	}
+
+	if matches == 0 {
+		return fmt.Sprintf("No errors matching %q", filter)
+	}
+	if n := d.discarded[checkType]; n > 0 {
+		sb.WriteString(fmt.Sprintf("[red]%d older distinct outputs were discarded[-]\n", n))
+	}
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDetails(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	failure := func(checkType string, details string, sec int) checks.CheckResult {
+		return checks.CheckResult{
+			Type:      checkType,
+			Success:   false,
+			Details:   details,
+			Timestamp: start.Add(time.Duration(sec) * time.Second),
+		}
+	}
+
+	cases := []struct {
+		name      string
+		results   []checks.CheckResult
+		checkType string
+		filter    string
+		expected  string
+	}{
+		{
+			name:      "no errors",
+			results:   []checks.CheckResult{{Type: checks.CheckTypeReleaseImageHostDNS, Success: true}},
+			checkType: checks.CheckTypeReleaseImageHostDNS,
+			expected:  "No errors reported for this check",
+		},
+		{
+			name: "outputs of other checks are not displayed",
+			results: []checks.CheckResult{
+				failure(checks.CheckTypeReleaseImageHostDNS, "dns error", 0),
+				failure(checks.CheckTypeReleaseImageHostPing, "ping error", 1),
+			},
+			checkType: checks.CheckTypeReleaseImageHostDNS,
+			expected:  "[red]failure:[-] (10:00:00)\ndns error\n\n",
+		},
+		{
+			name: "repeated outputs are merged, most recent first",
+			results: []checks.CheckResult{
+				failure(checks.CheckTypeReleaseImageHostDNS, "dns error", 0),
+				failure(checks.CheckTypeReleaseImageHostDNS, "timeout", 5),
+				failure(checks.CheckTypeReleaseImageHostDNS, "dns error", 10),
+			},
+			checkType: checks.CheckTypeReleaseImageHostDNS,
+			expected: "[red]failure:[-] (x2, 10:00:00 - 10:00:10)\ndns error\n\n" +
+				"[red]failure:[-] (10:00:05)\ntimeout\n\n",
+		},
+		{
+			name: "filter",
+			results: []checks.CheckResult{
+				failure(checks.CheckTypeReleaseImageHostDNS, "dns error", 0),
+				failure(checks.CheckTypeReleaseImageHostDNS, "Timeout", 5),
+			},
+			checkType: checks.CheckTypeReleaseImageHostDNS,
+			filter:    "timeout",
+			expected:  "[red]failure:[-] (10:00:05)\nTimeout\n\n",
+		},
+		{
+			name: "filter without matches",
+			results: []checks.CheckResult{
+				failure(checks.CheckTypeReleaseImageHostDNS, "dns error", 0),
+			},
+			checkType: checks.CheckTypeReleaseImageHostDNS,
+			filter:    "refused",
+			expected:  "No errors matching \"refused\"",
+		},
+		{
+			name: "discarded outputs are reported",
+			results: []checks.CheckResult{
+				failure(checks.CheckTypeReleaseImageHostDNS, "error 1", 0),
+				failure(checks.CheckTypeReleaseImageHostDNS, "error 2", 1),
+				failure(checks.CheckTypeReleaseImageHostDNS, "error 3", 2),
+				failure(checks.CheckTypeReleaseImageHostDNS, "error 4", 3),
+			},
+			checkType: checks.CheckTypeReleaseImageHostDNS,
+			expected: "[red]failure:[-] (10:00:03)\nerror 4\n\n" +
+				"[red]failure:[-] (10:00:02)\nerror 3\n\n" +
+				"[red]failure:[-] (10:00:01)\nerror 2\n\n" +
+				"[red]1 older distinct outputs were discarded[-]\n",
+		},
+		{
+			name: "output is escaped",
+			results: []checks.CheckResult{
+				failure(checks.CheckTypeReleaseImageHostDNS, "[red]", 0),
+			},
+			checkType: checks.CheckTypeReleaseImageHostDNS,
+			expected:  "[red]failure:[-] (10:00:00)\n[red[]\n\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newCheckDetails(3)
+			for _, cr := range tc.results {
+				d.add("failure", cr)
+			}
+			assert.Equal(t, tc.expected, d.render(tc.checkType, tc.filter))
+		})
+	}
+}
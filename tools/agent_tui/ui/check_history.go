@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+)
+
+const (
+	// Number of most recent results displayed in the latency sparkline
+	sparklineWidth = 12
+)
+
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders the duration of the most recent check results as a
+// small bar chart, scaled on the slowest one. Failed results are
+// displayed in red.
+func sparkline(entries []checks.HistoryEntry, width int) string {
+	if len(entries) > width {
+		entries = entries[len(entries)-width:]
+	}
+
+	var max time.Duration
+	for _, e := range entries {
+		if e.Duration > max {
+			max = e.Duration
+		}
+	}
+
+	var sb strings.Builder
+	// keep the column aligned while the history is still short
+	sb.WriteString(strings.Repeat(" ", width-len(entries)))
+	failed := false
+	for _, e := range entries {
+		level := 0
+		if max > 0 {
+			level = int(int64(e.Duration) * int64(len(sparklineBlocks)-1) / int64(max))
+		}
+		if !e.Success && !failed {
+			sb.WriteString("[red]")
+		} else if e.Success && failed {
+			sb.WriteString("[-]")
+		}
+		failed = !e.Success
+		sb.WriteRune(sparklineBlocks[level])
+	}
+	if failed {
+		sb.WriteString("[-]")
+	}
+	return sb.String()
+}
+
+// formatCheckHistory returns a short summary of the check history,
+// with the pass ratio, the latency sparkline and the time of the last
+// status change.
+func formatCheckHistory(h checks.HistorySummary) string {
+	if len(h.Entries) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%3.0f%% %s since %s",
+		h.PassRatio()*100,
+		sparkline(h.Entries, sparklineWidth),
+		h.LastChange.Format("15:04:05"))
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	entry := func(ms int, success bool) checks.HistoryEntry {
+		return checks.HistoryEntry{
+			Success:  success,
+			Duration: time.Duration(ms) * time.Millisecond,
+		}
+	}
+
+	cases := []struct {
+		name     string
+		entries  []checks.HistoryEntry
+		width    int
+		expected string
+	}{
+		{
+			name:     "empty",
+			width:    3,
+			expected: "   ",
+		},
+		{
+			name:     "scaled on the slowest result",
+			entries:  []checks.HistoryEntry{entry(0, true), entry(50, true), entry(100, true)},
+			width:    3,
+			expected: "▁▄█",
+		},
+		{
+			name:     "only the most recent results are displayed",
+			entries:  []checks.HistoryEntry{entry(100, true), entry(10, true), entry(10, true)},
+			width:    2,
+			expected: "██",
+		},
+		{
+			name:     "failures are highlighted",
+			entries:  []checks.HistoryEntry{entry(100, true), entry(100, false), entry(100, true)},
+			width:    4,
+			expected: " █[red]█[-]█",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sparkline(tc.entries, tc.width))
+		})
+	}
+}
@@ -16,34 +16,111 @@ const (
 	CONFIGURE_NETWORK_BUTTON string = "<[::u]C[::-]onfigure Network>"
 	QUIT_BUTTON              string = "<[::u]Q[::-]uit>"
 	PAGE_CHECKSCREEN         string = "checkScreen"
-
-	mainFlexHeight            = 10
-	mainFlexWithDetailsHeight = 30
+	FIELD_FILTER_DETAILS     string = "Filter: "
+
+	mainFlexHeight            = 11
+	mainFlexWithDetailsHeight = 32
+	// the details pane shrinks as the checks are added, so that the
+	// page still fits in the console
+	maxDetailsHeight = 14
+	minDetailsHeight = 8
 )
 
+var checkDescriptions = map[string]string{
+	checks.CheckTypeReleaseImagePull:     "release image pull",
+	checks.CheckTypeReleaseImageHostDNS:  "nslookup",
+	checks.CheckTypeReleaseImageHostPing: "ping",
+	checks.CheckTypeReleaseImageHttp:     "http GET",
+	checks.CheckTypeNetStateLint:         "network state",
+	checks.CheckTypeGateway:              "default gateway",
+	checks.CheckTypeDuplicateIP:          "duplicate IP addresses",
+	checks.CheckTypePathMTU:              "path MTU",
+}
+
+// The checks whose successful results may still carry warnings in
+// their details
+var warningChecks = map[string]bool{
+	checks.CheckTypeNetStateLint: true,
+	checks.CheckTypePathMTU:      true,
+}
+
 func (u *UI) SetPullCheck(cr checks.CheckResult) {
-	u.setCheck(u.primaryCheck, cr, 1, "release image pull error")
+	// the release image URL may be long, so the history
+	// is displayed in the row below
+	u.setCheck(u.primaryCheck, cr, 1, "release image pull error", 2, 1)
 }
 
 func (u *UI) SetDNSCheck(cr checks.CheckResult) {
-	u.setCheck(u.checks, cr, 0, "nslookup failure")
+	u.setCheck(u.checks, cr, 0, "nslookup failure", 0, 2)
 }
 
 func (u *UI) SetPingCheck(cr checks.CheckResult) {
-	u.setCheck(u.checks, cr, 1, "ping failure")
+	u.setCheck(u.checks, cr, 1, "ping failure", 1, 2)
 }
 
 func (u *UI) SetHttpGetCheck(cr checks.CheckResult) {
-	u.setCheck(u.checks, cr, 2, "http server not responding")
+	u.setCheck(u.checks, cr, 2, "http server not responding", 2, 2)
+}
+
+// SetNetStateLintCheck displays the problems found in the network
+// state. The warnings don't fail the check, but they are displayed in
+// the details too.
+func (u *UI) SetNetStateLintCheck(cr checks.CheckResult) {
+	u.setCheck(u.checks, cr, 3, "network state problems", 3, 2)
+}
+
+// SetGatewayCheck displays the diagnosis of the path to the default
+// gateway and the DNS servers
+func (u *UI) SetGatewayCheck(cr checks.CheckResult) {
+	u.setCheck(u.checks, cr, 4, "default gateway or DNS servers unreachable", 4, 2)
+}
+
+// SetDuplicateIPCheck displays the addresses of the host used by other
+// hosts too
+func (u *UI) SetDuplicateIPCheck(cr checks.CheckResult) {
+	u.setCheck(u.checks, cr, 5, "duplicate IP addresses", 5, 2)
+}
+
+// SetPathMTUCheck displays the path MTU toward the registry and the
+// rendezvous node. A path MTU reported by a router is only a warning.
+func (u *UI) SetPathMTUCheck(cr checks.CheckResult) {
+	u.setCheck(u.checks, cr, 6, "path MTU mismatch", 6, 2)
+}
+
+func (u *UI) SetEndpointCheck(cr checks.CheckResult) {
+	for row, checkType := range u.checkRows {
+		if checkType == cr.Type {
+			u.setCheck(u.checks, cr, row, "endpoint not responding", row, 2)
+			return
+		}
+	}
+}
+
+// checkDescription returns a short description of the check, used
+// in the details pane title
+func checkDescription(checkType string) string {
+	if url, isEndpoint := checks.EndpointFromCheckType(checkType); isEndpoint {
+		return fmt.Sprintf("http GET %s", url)
+	}
+	return checkDescriptions[checkType]
 }
 
-func (u *UI) setCheck(table *tview.Table, cr checks.CheckResult, row int, msg string) {
+func (u *UI) setCheck(table *tview.Table, cr checks.CheckResult, row int, msg string, historyRow int, historyCol int) {
 	u.app.QueueUpdateDraw(func() {
-		if cr.Success {
+		details := cr
+		switch {
+		case cr.Success && warningChecks[cr.Type] && cr.Details != "":
+			u.markCheckWarning(table, row, 0)
+			// the warnings are kept like the failures
+			details.Success = false
+		case cr.Success:
 			u.markCheckSuccess(table, row, 0)
-		} else {
+		default:
 			u.markCheckFail(table, row, 0)
-			u.appendNewErrorToDetails(msg, cr.Details)
+		}
+		u.setCheckHistory(table, historyRow, historyCol, cr.History)
+		if u.checkDetails.add(msg, details) && cr.Type == u.selectedCheck {
+			u.refreshDetails()
 		}
 	})
 }
@@ -62,6 +139,13 @@ func (u *UI) markCheckFail(table *tview.Table, row int, col int) {
 		BackgroundColor: newt.ColorGray})
 }
 
+func (u *UI) markCheckWarning(table *tview.Table, row int, col int) {
+	table.SetCell(row, col, &tview.TableCell{
+		Text:            " !",
+		Color:           colorWarning,
+		BackgroundColor: newt.ColorGray})
+}
+
 func (u *UI) markCheckUnknown(table *tview.Table, row int, col int) {
 	table.SetCell(row, col, &tview.TableCell{
 		Text:            " ?",
@@ -69,6 +153,13 @@ func (u *UI) markCheckUnknown(table *tview.Table, row int, col int) {
 		BackgroundColor: newt.ColorGray})
 }
 
+func (u *UI) setCheckHistory(table *tview.Table, row int, col int, history checks.HistorySummary) {
+	table.SetCell(row, col, &tview.TableCell{
+		Text:            formatCheckHistory(history),
+		Color:           newt.ColorBlack,
+		BackgroundColor: newt.ColorGray})
+}
+
 func (u *UI) setCheckDescription(table *tview.Table, row int, col int, description string) {
 	table.SetCell(row, col, &tview.TableCell{
 		Text:            description,
@@ -76,17 +167,17 @@ func (u *UI) setCheckDescription(table *tview.Table, row int, col int, descripti
 		BackgroundColor: newt.ColorGray})
 }
 
-func (u *UI) appendNewErrorToDetails(heading string, errorString string) {
-	u.appendToDetails(fmt.Sprintf("%s%s:%s\n%s", "[red]", heading, "[black]", errorString))
+// selectCheckDetails displays the outputs of the specified check
+// in the details pane
+func (u *UI) selectCheckDetails(checkType string) {
+	u.selectedCheck = checkType
+	u.details.SetTitle(fmt.Sprintf("  Check Errors: %s  ", checkDescription(checkType)))
+	u.refreshDetails()
+	u.details.ScrollToBeginning()
 }
 
-func (u *UI) appendToDetails(newLines string) {
-	current := u.details.GetText(false)
-	if len(current) > 10000 {
-		// if details run more than 10000 characters, reset
-		current = ""
-	}
-	u.details.SetText(current + newLines)
+func (u *UI) refreshDetails() {
+	u.details.SetText(u.checkDetails.render(u.selectedCheck, u.detailsFilter.GetText()))
 }
 
 func (u *UI) setCheckWidget(table *tview.Table, row int, checkType string, desc string, config checks.Config) {
@@ -118,21 +209,73 @@ func (u *UI) createCheckPage(config checks.Config) {
 	u.setCheckWidget(u.checks, 0, checks.CheckTypeReleaseImageHostDNS, "nslookup %s", config)
 	u.setCheckWidget(u.checks, 1, checks.CheckTypeReleaseImageHostPing, "ping %s", config)
 	u.setCheckWidget(u.checks, 2, checks.CheckTypeReleaseImageHttp, "%s responds to http GET", config)
+	u.setCheckWidget(u.checks, 3, checks.CheckTypeNetStateLint, "network state has no known problems", config)
+	u.setCheckWidget(u.checks, 4, checks.CheckTypeGateway, "default gateway and DNS servers reachable", config)
+	u.setCheckWidget(u.checks, 5, checks.CheckTypeDuplicateIP, "no other host uses the IP addresses", config)
+	u.setCheckWidget(u.checks, 6, checks.CheckTypePathMTU, "path MTU matches the interface MTU", config)
+
+	// The checks rows can be selected to display their errors
+	// in the details pane
+	u.checkRows = []string{
+		checks.CheckTypeReleaseImageHostDNS,
+		checks.CheckTypeReleaseImageHostPing,
+		checks.CheckTypeReleaseImageHttp,
+		checks.CheckTypeNetStateLint,
+		checks.CheckTypeGateway,
+		checks.CheckTypeDuplicateIP,
+		checks.CheckTypePathMTU,
+	}
+	// The additional endpoints, if any, are listed after the
+	// release image checks
+	for _, endpoint := range config.CheckEndpoints {
+		row := len(u.checkRows)
+		u.checkRows = append(u.checkRows, checks.EndpointCheckType(endpoint))
+		u.setCheckWidget(u.checks, row, u.checkRows[row], fmt.Sprintf("%s responds to http GET", tview.Escape(endpoint)), config)
+	}
+	checkRows := u.checkRows
+	u.checks.SetSelectedStyle(tcell.StyleDefault.Background(newt.ColorBlue).Foreground(newt.ColorGray))
+	u.checks.SetSelectionChangedFunc(func(row, column int) {
+		if row >= 0 && row < len(checkRows) {
+			u.selectCheckDetails(checkRows[row])
+		}
+	})
+	u.checks.SetFocusFunc(func() {
+		u.checks.SetSelectable(true, false)
+		row, _ := u.checks.GetSelection()
+		u.selectCheckDetails(checkRows[row])
+	})
+	u.checks.SetBlurFunc(func() {
+		u.checks.SetSelectable(false, false)
+	})
+	u.primaryCheck.SetFocusFunc(func() {
+		u.selectCheckDetails(checks.CheckTypeReleaseImagePull)
+	})
 
+	u.checkDetails = newCheckDetails(maxCheckOutputs)
 	u.details = tview.NewTextView()
 	u.details.SetBorder(true)
-	u.details.SetTitle("  Check Errors  ")
 	u.details.SetDynamicColors(true)
 	u.details.SetBorderColor(newt.ColorBlack)
 	u.details.SetBackgroundColor(newt.ColorGray)
 	u.details.SetTitleColor(newt.ColorBlack)
 
+	u.detailsFilter = tview.NewInputField()
+	u.detailsFilter.SetLabel(FIELD_FILTER_DETAILS)
+	u.detailsFilter.SetLabelColor(newt.ColorBlack)
+	u.detailsFilter.SetBackgroundColor(newt.ColorGray)
+	u.detailsFilter.SetFieldTextColor(newt.ColorGray)
+	u.detailsFilter.SetChangedFunc(func(text string) {
+		u.refreshDetails()
+		u.details.ScrollToBeginning()
+	})
+	u.selectCheckDetails(checks.CheckTypeReleaseImagePull)
+
 	u.netConfigForm = tview.NewForm()
 	u.netConfigForm.SetBorder(false)
 	u.netConfigForm.SetBackgroundColor(newt.ColorGray)
 	u.netConfigForm.SetButtonsAlign(tview.AlignCenter)
 	u.netConfigForm.AddButton(CONFIGURE_NETWORK_BUTTON, func() {
-		u.showNMTUIWithErrorDialog(u.setFocusToChecks)
+		u.showNetEditor(u.setFocusToChecks)
 	})
 	u.netConfigForm.AddButton(QUIT_BUTTON, func() {
 		u.app.Stop()
@@ -142,10 +285,34 @@ func (u *UI) createCheckPage(config checks.Config) {
 	u.netConfigForm.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
 		Foreground(newt.ColorBlack))
 
+	u.createShortcutsBar()
+	u.addShortcut('L', "Logs", func() {
+		u.ShowLogPage(u.setFocusToChecks)
+	})
+	u.addShortcut('S', "Settings", func() {
+		u.ShowSettingsPage(u.setFocusToChecks)
+	})
+	u.addShortcut('N', "Network", func() {
+		u.ShowNetStatusPage(u.setFocusToChecks)
+	})
+	u.addShortcut('E', "Export", func() {
+		u.ShowExportPage(u.setFocusToChecks)
+	})
+	u.addShortcut('I', "Import", func() {
+		u.ShowImportPage(u.setFocusToChecks)
+	})
+	u.addShortcut('T', "Traceroute", func() {
+		u.ShowTraceroutePage(u.setFocusToChecks)
+	})
+	u.addShortcut('D', "DHCP", func() {
+		u.ShowDHCPProbePage(u.setFocusToChecks)
+	})
+
 	u.mainFlex = tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(u.primaryCheck, 5, 0, false).
-		AddItem(u.netConfigForm, 3, 0, false)
+		AddItem(u.netConfigForm, 3, 0, false).
+		AddItem(u.shortcutsBar, 1, 0, false)
 	u.mainFlex.SetTitle("  Agent installer network boot setup  ").
 		SetTitleColor(newt.ColorRed).
 		SetBorder(true).
@@ -158,12 +325,17 @@ func (u *UI) createCheckPage(config checks.Config) {
 		AddItem(nil, 0, 1, false)
 
 	// Initially, only the form buttons can receive the focus
-	u.focusableItems = []tview.Primitive{
-		u.netConfigForm.GetButton(0),
-		u.netConfigForm.GetButton(1),
-	}
+	u.resetChecksFocusableItems()
 	// Allow the user to cycle the focus only over the configured items
 	u.mainFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if u.detailsFilter.HasFocus() {
+			switch event.Key() {
+			case tcell.KeyRune, tcell.KeyLeft, tcell.KeyRight:
+				// let the user edit the filter
+				return event
+			}
+		}
+
 		switch event.Key() {
 		case tcell.KeyTab, tcell.KeyRight:
 			u.focusedItem++
@@ -185,7 +357,10 @@ func (u *UI) createCheckPage(config checks.Config) {
 				u.app.Stop()
 			}
 			if event.Rune() == 'C' || event.Rune() == 'c' {
-				u.showNMTUIWithErrorDialog(u.setFocusToChecks)
+				u.showNetEditor(u.setFocusToChecks)
+			}
+			if u.handleShortcut(event.Rune()) {
+				return nil
 			}
 
 		default:
@@ -208,8 +383,20 @@ func (u *UI) createCheckPage(config checks.Config) {
 	u.app.SetRoot(u.pages, true).SetFocus(u.netConfigForm)
 }
 
+// checksHeight returns the height of the additional checks table,
+// including its borders
+func (u *UI) checksHeight() int {
+	return len(u.checkRows) + 2
+}
+
+// detailsHeight returns the height of the details pane, including its
+// borders
+func (u *UI) detailsHeight() int {
+	return max(minDetailsHeight, maxDetailsHeight-max(0, len(u.checkRows)-4))
+}
+
 func (u *UI) additionalChecksVisible() bool {
-	return u.mainFlex.GetItemCount() > 2
+	return u.mainFlex.GetItemCount() > 3
 }
 
 func (u *UI) HideAdditionalChecks() {
@@ -219,16 +406,17 @@ func (u *UI) HideAdditionalChecks() {
 
 	u.mainFlex.
 		RemoveItem(u.checks).
-		RemoveItem(u.details)
+		RemoveItem(u.details).
+		RemoveItem(u.detailsFilter)
 	u.innerFlex.ResizeItem(u.mainFlex, mainFlexHeight, 0)
 
+	// Remove the details from the focusable list
+	u.resetChecksFocusableItems()
 	// Switch focus if the details pane was previously focused
-	if u.focusableItems[u.focusedItem] == u.details {
+	if u.focusedItem > len(u.focusableItems)-1 {
 		u.focusedItem = 0
 		u.app.SetFocus(u.focusableItems[u.focusedItem])
 	}
-	// Remove the details from the focusable list
-	u.focusableItems = u.focusableItems[:len(u.focusableItems)-1]
 }
 
 func (u *UI) ShowAdditionalChecks() {
@@ -238,11 +426,31 @@ func (u *UI) ShowAdditionalChecks() {
 
 	u.mainFlex.
 		RemoveItem(u.netConfigForm).
-		AddItem(u.checks, 5, 0, false).
-		AddItem(u.details, 15, 0, false).
-		AddItem(u.netConfigForm, 3, 0, false)
-	u.innerFlex.ResizeItem(u.mainFlex, mainFlexWithDetailsHeight, 0)
+		RemoveItem(u.shortcutsBar).
+		AddItem(u.checks, u.checksHeight(), 0, false).
+		AddItem(u.details, u.detailsHeight(), 0, false).
+		AddItem(u.detailsFilter, 1, 0, false).
+		AddItem(u.netConfigForm, 3, 0, false).
+		AddItem(u.shortcutsBar, 1, 0, false)
+	u.innerFlex.ResizeItem(u.mainFlex, mainFlexWithDetailsHeight-maxDetailsHeight+u.detailsHeight()+len(u.checkRows)-4, 0)
 
 	// Details can be focused again
-	u.focusableItems = append(u.focusableItems, u.details)
+	u.resetChecksFocusableItems()
+}
+
+// resetChecksFocusableItems sets the widgets that can be focused on the
+// checks page. The checks and their details can be focused only when the
+// additional checks are visible.
+func (u *UI) resetChecksFocusableItems() {
+	u.focusableItems = []tview.Primitive{
+		u.netConfigForm.GetButton(0),
+		u.netConfigForm.GetButton(1),
+	}
+	if u.additionalChecksVisible() {
+		u.focusableItems = append(u.focusableItems,
+			u.primaryCheck,
+			u.checks,
+			u.details,
+			u.detailsFilter)
+	}
 }
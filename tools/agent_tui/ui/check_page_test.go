@@ -114,6 +114,25 @@ func TestInteractiveUIModeWithPrefilledIP(t *testing.T) {
 	assert.True(t, ui.IsRendezvousIPTimeoutActive())
 }
 
+func TestEndpointChecks(t *testing.T) {
+	config := checks.Config{
+		ReleaseImageURL: "",
+		LogPath:         "/tmp/agent-tui.log",
+		CheckEndpoints:  []string{"https://mirror.example.com:5000", "http://192.168.111.1/health"},
+	}
+
+	logger := logrus.New()
+	ui := NewUI(tview.NewApplication(), config, logger, "")
+
+	assert.Equal(t, 9, ui.checks.GetRowCount())
+	assert.Equal(t, 11, ui.checksHeight())
+	assert.Equal(t, 9, ui.detailsHeight())
+	assert.Equal(t, "http://192.168.111.1/health responds to http GET", ui.checks.GetCell(8, 1).Text)
+
+	ui.selectCheckDetails(checks.EndpointCheckType("https://mirror.example.com:5000"))
+	assert.Equal(t, "  Check Errors: http GET https://mirror.example.com:5000  ", ui.details.GetTitle())
+}
+
 func TestInteractiveUIModeWithoutPrefilledIP(t *testing.T) {
 	config := checks.Config{
 		ReleaseImageURL: "",
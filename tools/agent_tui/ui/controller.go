@@ -82,7 +82,7 @@ func (c *Controller) Init(numChecks int, rendezvousIP string, interactiveUIMode
 				c.ui.app.QueueUpdateDraw(func() {
 					c.ui.HideSplashScreen()
 					if c.state {
-						c.ui.ShowTimeoutDialog()
+						c.ui.continueOrPrompt()
 					} else {
 						c.ui.setFocusToChecks()
 					}
@@ -103,7 +103,7 @@ func (c *Controller) Init(numChecks int, rendezvousIP string, interactiveUIMode
 			// then it's safe to display the timeout dialog
 			if c.state && !c.ui.IsTimeoutDialogActive() && !c.ui.IsDirty() {
 				c.ui.app.QueueUpdateDraw(func() {
-					c.ui.ShowTimeoutDialog()
+					c.ui.continueOrPrompt()
 				})
 			}
 
@@ -134,5 +134,17 @@ func (c *Controller) updateCheckWidgets(res checks.CheckResult) {
 		c.ui.SetPingCheck(res)
 	case checks.CheckTypeReleaseImageHttp:
 		c.ui.SetHttpGetCheck(res)
+	case checks.CheckTypeNetStateLint:
+		c.ui.SetNetStateLintCheck(res)
+	case checks.CheckTypeGateway:
+		c.ui.SetGatewayCheck(res)
+	case checks.CheckTypeDuplicateIP:
+		c.ui.SetDuplicateIPCheck(res)
+	case checks.CheckTypePathMTU:
+		c.ui.SetPathMTUCheck(res)
+	default:
+		if _, isEndpoint := checks.EndpointFromCheckType(res.Type); isEndpoint {
+			c.ui.SetEndpointCheck(res)
+		}
 	}
 }
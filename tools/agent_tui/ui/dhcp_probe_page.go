@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_DHCP_PROBE                 string = "dhcpProbe"
+	FIELD_DHCP_PROBE_IFACE          string = "Interface: "
+	DHCP_PROBE_BUTTON               string = "<Probe>"
+	DHCP_PROBE_START_TEXT                  = "Select an interface and press Probe to look for the DHCP servers answering on it. The configuration of the interface is not changed."
+	DHCP_PROBE_NO_IFACE_TEXT               = "No interface can be probed."
+	DHCP_PROBE_RUNNING_TEXT                = "Probing DHCPv4 and DHCPv6 on %s for %s..."
+	DHCP_PROBE_NO_OFFER_TEXT               = "[red]No DHCP server answered on %s.[black] Check the cabling, the VLAN of the switch port and the DHCP relay, or configure a static address."
+	DHCP_PROBE_SEVERAL_SERVERS_TEXT        = "[red]Several DHCPv4 servers answered, one of them may be unexpected.[black]"
+	DHCP_PROBE_ERROR_TEXT                  = "[red]%s[black]"
+
+	dhcpProbeTimeout = 5 * time.Second
+)
+
+func (u *UI) createDHCPProbePage() {
+	u.dhcpProbeView = tview.NewTextView()
+	u.dhcpProbeView.SetDynamicColors(true).
+		SetWordWrap(true).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack).
+		SetTitle(" Offers ").
+		SetTitleColor(newt.ColorBlack)
+	u.dhcpProbeView.SetBackgroundColor(newt.ColorGray)
+	u.dhcpProbeView.SetTextColor(newt.ColorBlack)
+
+	u.dhcpProbeForm = tview.NewForm()
+	u.dhcpProbeForm.SetItemPadding(0)
+	u.dhcpProbeForm.AddDropDown(FIELD_DHCP_PROBE_IFACE, []string{}, 0, nil)
+	u.dhcpProbeForm.SetFieldTextColor(newt.ColorGray)
+
+	u.dhcpProbeButtons = tview.NewForm()
+	u.dhcpProbeButtons.SetButtonsAlign(tview.AlignCenter)
+	u.dhcpProbeButtons.AddButton(DHCP_PROBE_BUTTON, func() {
+		u.startDHCPProbe()
+	})
+	u.dhcpProbeButtons.AddButton(BACK_BUTTON, func() {
+		u.hideDHCPProbePage()
+	})
+	u.dhcpProbeButtons.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
+		Foreground(newt.ColorGray))
+	u.dhcpProbeButtons.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
+		Foreground(newt.ColorBlack))
+
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.dhcpProbeView, 0, 1, false).
+		AddItem(u.dhcpProbeForm, 1, 0, false).
+		AddItem(u.dhcpProbeButtons, 3, 0, false)
+	mainFlex.SetTitle("  DHCP probe  ").
+		SetTitleColor(newt.ColorRed).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	mainFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			u.focusedItem++
+			if u.focusedItem > len(u.focusableItems)-1 {
+				u.focusedItem = 0
+			}
+		case tcell.KeyBacktab:
+			u.focusedItem--
+			if u.focusedItem < 0 {
+				u.focusedItem = len(u.focusableItems) - 1
+			}
+		case tcell.KeyESC:
+			if u.dhcpProbeView.HasFocus() {
+				u.hideDHCPProbePage()
+				return nil
+			}
+			return event
+		default:
+			return event
+		}
+		u.app.SetFocus(u.focusableItems[u.focusedItem])
+		return nil
+	})
+
+	u.pages.AddPage(PAGE_DHCP_PROBE, mainFlex, true, false)
+}
+
+// ShowDHCPProbePage displays the DHCP probe page, where the DHCP servers
+// answering on an interface can be listed. The doneFunc callback is used
+// to go back to the previous page.
+func (u *UI) ShowDHCPProbePage(doneFunc func()) {
+	u.dhcpProbePageDone = doneFunc
+
+	u.dhcpProbeIfaces = []string{}
+	options := []string{}
+	selected := -1
+	netState, err := u.retrieveNetState()
+	if err != nil {
+		u.logger.Infof("failed to retrieve the network state: %v", err)
+	}
+	for _, iface := range netState.Ifaces {
+		if iface.Type == "loopback" || iface.Name == "lo" {
+			continue
+		}
+		// the interfaces without an address are the usual suspects
+		option := iface.Name
+		if !hasRoutableAddress(iface) {
+			option += " (no address)"
+			if selected < 0 {
+				selected = len(options)
+			}
+		}
+		u.dhcpProbeIfaces = append(u.dhcpProbeIfaces, iface.Name)
+		options = append(options, option)
+	}
+	ifaceDropDown := u.dhcpProbeForm.GetFormItemByLabel(FIELD_DHCP_PROBE_IFACE).(*tview.DropDown)
+	ifaceDropDown.SetOptions(options, nil)
+	if len(options) > 0 {
+		ifaceDropDown.SetCurrentOption(max(selected, 0))
+		u.dhcpProbeView.SetText(DHCP_PROBE_START_TEXT)
+	} else {
+		u.dhcpProbeView.SetText(DHCP_PROBE_NO_IFACE_TEXT)
+	}
+
+	u.focusableItems = []tview.Primitive{
+		u.dhcpProbeView,
+		ifaceDropDown,
+		u.dhcpProbeButtons.GetButton(0),
+		u.dhcpProbeButtons.GetButton(1),
+	}
+	u.focusedItem = 2
+	u.pages.SwitchToPage(PAGE_DHCP_PROBE)
+	u.app.SetFocus(u.dhcpProbeButtons.GetButton(0))
+}
+
+// hasRoutableAddress returns true if the interface has an address
+// other than the IPv6 link-local ones
+func hasRoutableAddress(iface net.Iface) bool {
+	for _, config := range []net.IPConfig{iface.IPv4, iface.IPv6} {
+		for _, address := range config.Addresses {
+			if !address.IP.IsLinkLocalUnicast() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (u *UI) hideDHCPProbePage() {
+	// a probe still running is ignored when it ends
+	u.dhcpProbeRun++
+	u.focusedItem = 0
+	u.dhcpProbePageDone()
+}
+
+// startDHCPProbe probes the DHCP servers on the selected interface in
+// the background, and displays the offers received. The results are
+// logged too.
+func (u *UI) startDHCPProbe() {
+	index, _ := u.dhcpProbeForm.GetFormItemByLabel(FIELD_DHCP_PROBE_IFACE).(*tview.DropDown).GetCurrentOption()
+	if index < 0 || index >= len(u.dhcpProbeIfaces) {
+		return
+	}
+	iface := u.dhcpProbeIfaces[index]
+
+	u.dhcpProbeRun++
+	run := u.dhcpProbeRun
+	u.dhcpProbeView.SetText(fmt.Sprintf(DHCP_PROBE_RUNNING_TEXT, iface, dhcpProbeTimeout))
+	u.app.SetFocus(u.dhcpProbeView)
+	u.focusedItem = 0
+	u.logger.Infof("probing the DHCP servers on %s", iface)
+
+	go func() {
+		offers, err := u.probeDHCP(iface, dhcpProbeTimeout)
+		lines := []string{}
+		v4Servers := map[string]bool{}
+		for _, offer := range offers {
+			u.logger.Infof("DHCP probe on %s: %s", iface, offer)
+			lines = append(lines, tview.Escape(offer.String()))
+			if offer.Version == 4 {
+				v4Servers[offer.Server] = true
+			}
+		}
+		if len(v4Servers) > 1 {
+			lines = append(lines, DHCP_PROBE_SEVERAL_SERVERS_TEXT)
+		}
+		if len(offers) == 0 {
+			u.logger.Infof("DHCP probe on %s: no offer received", iface)
+			lines = append(lines, fmt.Sprintf(DHCP_PROBE_NO_OFFER_TEXT, iface))
+		}
+		if err != nil {
+			u.logger.Infof("DHCP probe on %s: %v", iface, err)
+			lines = append(lines, fmt.Sprintf(DHCP_PROBE_ERROR_TEXT, tview.Escape(err.Error())))
+		}
+
+		u.app.QueueUpdateDraw(func() {
+			if run != u.dhcpProbeRun {
+				return
+			}
+			u.dhcpProbeView.SetText(strings.Join(lines, "\n"))
+		})
+	}()
+}
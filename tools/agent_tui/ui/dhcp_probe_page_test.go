@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDHCPProbePage(t *testing.T) {
+	ui, screen := newSimulatedUI(t, checks.Config{}, nil)
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return testNetState(t), nil
+	}
+	probed := make(chan string, 1)
+	ui.probeDHCP = func(iface string, timeout time.Duration) ([]net.DHCPOffer, error) {
+		probed <- iface
+		return []net.DHCPOffer{
+			{Iface: iface, Version: 4, Server: "192.168.111.1", IP: "192.168.111.81/24", Gateway: "192.168.111.1", LeaseTime: time.Hour},
+			{Iface: iface, Version: 4, Server: "192.168.111.66", IP: "192.168.111.99/24"},
+		}, errors.New("DHCPv6 probe failed: no IPv6 link-local address on eth1")
+	}
+
+	startUI(t, ui)
+	openShortcutPage(t, ui, 'd', PAGE_DHCP_PROBE)
+
+	// the interface without an address is selected first
+	var selected string
+	ui.app.QueueUpdate(func() {
+		_, selected = ui.dhcpProbeForm.GetFormItemByLabel(FIELD_DHCP_PROBE_IFACE).(*tview.DropDown).GetCurrentOption()
+	})
+	assert.Equal(t, "eth1 (no address)", selected)
+	assert.Equal(t, []string{"eth0", "eth1"}, ui.dhcpProbeIfaces)
+
+	// <Probe> is focused
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	select {
+	case iface := <-probed:
+		assert.Equal(t, "eth1", iface)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the DHCP probe was not started")
+	}
+
+	var text string
+	assert.Eventually(t, func() bool {
+		ui.app.QueueUpdate(func() {
+			text = ui.dhcpProbeView.GetText(true)
+		})
+		return strings.HasPrefix(text, "DHCPv4")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, strings.Join([]string{
+		"DHCPv4 server 192.168.111.1 offers 192.168.111.81/24, gateway 192.168.111.1, lease 1h0m0s",
+		"DHCPv4 server 192.168.111.66 offers 192.168.111.99/24",
+		"Several DHCPv4 servers answered, one of them may be unexpected.",
+		"DHCPv6 probe failed: no IPv6 link-local address on eth1",
+	}, "\n"), text)
+
+	screen.InjectKey(tcell.KeyESC, 0, tcell.ModNone)
+	waitForPage(t, ui, PAGE_CHECKSCREEN)
+}
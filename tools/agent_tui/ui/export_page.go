@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/media"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_EXPORT             string = "export"
+	FIELD_EXPORT_MEDIA      string = "Destination: "
+	FIELD_EXPORT_FILE       string = "File: "
+	EXPORT_SAVE_BUTTON      string = "<Save>"
+	EXPORT_OTHER_PATH       string = "other path"
+	EXPORT_DEFAULT_DIR      string = "/tmp"
+	EXPORT_SAVED_TEXT              = "The network configuration has been saved to %s"
+	EXPORT_SAVE_FAILED_TEXT        = "Failed to save the network configuration to %s:\n\n%v"
+	EXPORT_FAILED_TEXT             = "Failed to export the network configuration:\n\n%v"
+)
+
+func (u *UI) createExportPage() {
+	u.exportView = tview.NewTextView()
+	u.exportView.SetDynamicColors(false).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack).
+		SetTitle(" agent-config.yaml host network settings ").
+		SetTitleColor(newt.ColorBlack)
+	u.exportView.SetBackgroundColor(newt.ColorGray)
+	u.exportView.SetTextColor(newt.ColorBlack)
+
+	u.exportForm = tview.NewForm()
+	u.exportForm.SetItemPadding(0)
+	u.exportForm.
+		AddDropDown(FIELD_EXPORT_MEDIA, []string{EXPORT_OTHER_PATH}, 0, nil).
+		AddInputField(FIELD_EXPORT_FILE, "", 55, nil, nil)
+	u.exportForm.SetFieldTextColor(newt.ColorGray)
+
+	u.exportButtons = tview.NewForm()
+	u.exportButtons.SetButtonsAlign(tview.AlignCenter)
+	u.exportButtons.AddButton(EXPORT_SAVE_BUTTON, func() {
+		u.saveExport()
+	})
+	u.exportButtons.AddButton(BACK_BUTTON, func() {
+		u.exportPageDone()
+	})
+	u.exportButtons.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
+		Foreground(newt.ColorGray))
+	u.exportButtons.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
+		Foreground(newt.ColorBlack))
+
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.exportView, 0, 1, false).
+		AddItem(u.exportForm, 2, 0, false).
+		AddItem(u.exportButtons, 3, 0, false)
+	mainFlex.SetTitle("  Export network configuration  ").
+		SetTitleColor(newt.ColorRed).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	mainFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			u.focusedItem++
+			if u.focusedItem > len(u.focusableItems)-1 {
+				u.focusedItem = 0
+			}
+		case tcell.KeyBacktab:
+			u.focusedItem--
+			if u.focusedItem < 0 {
+				u.focusedItem = len(u.focusableItems) - 1
+			}
+		case tcell.KeyESC:
+			if u.exportView.HasFocus() {
+				u.exportPageDone()
+				return nil
+			}
+			return event
+		default:
+			return event
+		}
+		u.app.SetFocus(u.focusableItems[u.focusedItem])
+		return nil
+	})
+
+	u.pages.AddPage(PAGE_EXPORT, mainFlex, true, false)
+}
+
+// ShowExportPage displays the current network configuration converted
+// to the agent-config.yaml format, that can be saved to a file or to a
+// removable media. The doneFunc callback is used to go back to the
+// previous page.
+func (u *UI) ShowExportPage(doneFunc func()) {
+	u.exportPageDone = func() {
+		u.focusedItem = 0
+		doneFunc()
+	}
+
+	data, err := u.exportNetworkConfig()
+	if err != nil {
+		u.logger.Infof("failed to export the network configuration: %v", err)
+		u.showNetModal(fmt.Sprintf(EXPORT_FAILED_TEXT, err), []string{BACK_BUTTON}, func(string) {
+			u.exportPageDone()
+		})
+		return
+	}
+	u.exportData = data
+	u.exportView.SetText(string(data)).ScrollToBeginning()
+
+	// the removable media are listed first, since the configuration
+	// is usually copied to another host
+	destinations := []string{}
+	mounts, err := u.removableMounts()
+	if err != nil {
+		u.logger.Infof("failed to list the removable media: %v", err)
+	}
+	for _, mount := range mounts {
+		if !mount.ReadOnly {
+			destinations = append(destinations, mount.Path)
+		}
+	}
+	destinations = append(destinations, EXPORT_OTHER_PATH)
+	fileField := u.exportForm.GetFormItemByLabel(FIELD_EXPORT_FILE).(*tview.InputField)
+	mediaDropDown := u.exportForm.GetFormItemByLabel(FIELD_EXPORT_MEDIA).(*tview.DropDown)
+	mediaDropDown.SetOptions(destinations, func(option string, index int) {
+		dir := option
+		if option == EXPORT_OTHER_PATH {
+			dir = EXPORT_DEFAULT_DIR
+		}
+		fileField.SetText(filepath.Join(dir, net.ExportFileName))
+	})
+	mediaDropDown.SetCurrentOption(0)
+
+	u.focusableItems = []tview.Primitive{
+		u.exportView,
+		mediaDropDown,
+		fileField,
+		u.exportButtons.GetButton(0),
+		u.exportButtons.GetButton(1),
+	}
+	u.setFocusToExport()
+}
+
+func (u *UI) setFocusToExport() {
+	u.focusedItem = 0
+	u.pages.SwitchToPage(PAGE_EXPORT)
+	u.app.SetFocus(u.exportView)
+}
+
+func (u *UI) exportNetworkConfig() ([]byte, error) {
+	netState, err := u.retrieveNetState()
+	if err != nil {
+		return nil, err
+	}
+	host, err := net.Export(netState, nil)
+	if err != nil {
+		return nil, err
+	}
+	return host.YAML()
+}
+
+func (u *UI) saveExport() {
+	path := u.exportForm.GetFormItemByLabel(FIELD_EXPORT_FILE).(*tview.InputField).GetText()
+	if err := media.WriteFile(path, u.exportData); err != nil {
+		u.logger.Infof("failed to save the network configuration to %s: %v", path, err)
+		u.showNetModal(fmt.Sprintf(EXPORT_SAVE_FAILED_TEXT, path, err), []string{BACK_BUTTON}, func(string) {
+			u.setFocusToExport()
+		})
+		return
+	}
+	u.logger.Infof("network configuration saved to %s", path)
+	u.showNetModal(fmt.Sprintf(EXPORT_SAVED_TEXT, path), []string{NET_EDITOR_OK_BUTTON}, func(string) {
+		u.exportPageDone()
+	})
+}
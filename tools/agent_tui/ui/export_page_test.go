@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/media"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportPage(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return testNetState(t), nil
+	}
+	dir := t.TempDir()
+	ui.removableMounts = func() ([]media.Mount, error) {
+		return []media.Mount{
+			{Device: "/dev/sr0", Path: "/run/media/iso", ReadOnly: true},
+			{Device: "/dev/sdb1", Path: dir},
+		}, nil
+	}
+
+	ui.ShowExportPage(func() {})
+	page, _ := ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_EXPORT, page)
+	assert.Contains(t, ui.exportView.GetText(false), "networkConfig:")
+
+	// the removable media is the default destination
+	path := filepath.Join(dir, net.ExportFileName)
+	assert.Equal(t, path, ui.exportForm.GetFormItemByLabel(FIELD_EXPORT_FILE).(*tview.InputField).GetText())
+	_, option := ui.exportForm.GetFormItemByLabel(FIELD_EXPORT_MEDIA).(*tview.DropDown).GetCurrentOption()
+	assert.Equal(t, dir, option)
+
+	ui.saveExport()
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(ui.exportData), string(data))
+	assert.Contains(t, string(data), "macAddress: 52:54:00:aa:bb:01")
+	page, _ = ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_NET_MODAL, page)
+}
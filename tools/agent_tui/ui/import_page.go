@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/media"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_IMPORT                 string = "import"
+	FIELD_IMPORT_SOURCE         string = "Source: "
+	FIELD_IMPORT_FILE           string = "File: "
+	IMPORT_OTHER_PATH           string = "other path"
+	IMPORT_NO_FILE_TEXT                = "Select a NMState file from the removable media, or enter its path and press Enter."
+	IMPORT_INVALID_TEXT                = "[red]The file %s is not valid:[black]\n\n%v"
+	IMPORT_NO_CHANGES_TEXT             = "The file %s does not change the network configuration."
+	IMPORT_CHANGES_TEXT                = "Applying %s will change the network configuration as follows:\n\n"
+	IMPORT_NOT_LOADED_TEXT             = "No valid NMState file has been loaded."
+	IMPORT_RETRIEVE_FAILED_TEXT        = "[red]Failed to retrieve the current network configuration:[black]\n\n%v"
+)
+
+func (u *UI) createImportPage() {
+	u.importView = tview.NewTextView()
+	u.importView.SetDynamicColors(true).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack).
+		SetTitle(" Changes ").
+		SetTitleColor(newt.ColorBlack)
+	u.importView.SetBackgroundColor(newt.ColorGray)
+	u.importView.SetTextColor(newt.ColorBlack)
+
+	u.importForm = tview.NewForm()
+	u.importForm.SetItemPadding(0)
+	u.importForm.
+		AddDropDown(FIELD_IMPORT_SOURCE, []string{IMPORT_OTHER_PATH}, 0, nil).
+		AddInputField(FIELD_IMPORT_FILE, "", 55, nil, nil)
+	u.importForm.SetFieldTextColor(newt.ColorGray)
+	u.importForm.GetFormItemByLabel(FIELD_IMPORT_FILE).(*tview.InputField).SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			u.loadImport(u.importForm.GetFormItemByLabel(FIELD_IMPORT_FILE).(*tview.InputField).GetText())
+		}
+	})
+
+	u.importButtons = tview.NewForm()
+	u.importButtons.SetButtonsAlign(tview.AlignCenter)
+	u.importButtons.AddButton(NET_EDITOR_APPLY_BUTTON, func() {
+		u.applyImport()
+	})
+	u.importButtons.AddButton(BACK_BUTTON, func() {
+		u.importPageDone()
+	})
+	u.importButtons.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
+		Foreground(newt.ColorGray))
+	u.importButtons.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
+		Foreground(newt.ColorBlack))
+
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.importForm, 2, 0, false).
+		AddItem(u.importView, 0, 1, false).
+		AddItem(u.importButtons, 3, 0, false)
+	mainFlex.SetTitle("  Import network configuration  ").
+		SetTitleColor(newt.ColorRed).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	mainFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			u.focusedItem++
+			if u.focusedItem > len(u.focusableItems)-1 {
+				u.focusedItem = 0
+			}
+		case tcell.KeyBacktab:
+			u.focusedItem--
+			if u.focusedItem < 0 {
+				u.focusedItem = len(u.focusableItems) - 1
+			}
+		case tcell.KeyESC:
+			if u.importForm.GetFormItemByLabel(FIELD_IMPORT_SOURCE).(*tview.DropDown).HasFocus() {
+				u.importPageDone()
+				return nil
+			}
+			return event
+		default:
+			return event
+		}
+		u.app.SetFocus(u.focusableItems[u.focusedItem])
+		return nil
+	})
+
+	u.pages.AddPage(PAGE_IMPORT, mainFlex, true, false)
+}
+
+// ShowImportPage lists the NMState files found on the removable media,
+// and previews the changes of the selected one before applying it. The
+// doneFunc callback is used to go back to the previous page.
+func (u *UI) ShowImportPage(doneFunc func()) {
+	u.importPageDone = func() {
+		u.focusedItem = 0
+		doneFunc()
+	}
+
+	mounts, err := u.removableMounts()
+	if err != nil {
+		u.logger.Infof("failed to list the removable media: %v", err)
+	}
+	sources := append(media.FindOnMounts(mounts, net.ImportFileExtensions, net.ImportSearchDepth), IMPORT_OTHER_PATH)
+	fileField := u.importForm.GetFormItemByLabel(FIELD_IMPORT_FILE).(*tview.InputField)
+	sourceDropDown := u.importForm.GetFormItemByLabel(FIELD_IMPORT_SOURCE).(*tview.DropDown)
+	sourceDropDown.SetOptions(sources, func(option string, index int) {
+		if option == IMPORT_OTHER_PATH {
+			fileField.SetText("")
+			u.importState = nil
+			u.importView.SetText(IMPORT_NO_FILE_TEXT)
+			return
+		}
+		fileField.SetText(option)
+		u.loadImport(option)
+	})
+	sourceDropDown.SetCurrentOption(0)
+
+	u.focusableItems = []tview.Primitive{
+		sourceDropDown,
+		fileField,
+		u.importView,
+		u.importButtons.GetButton(0),
+		u.importButtons.GetButton(1),
+	}
+	u.setFocusToImport()
+}
+
+func (u *UI) setFocusToImport() {
+	u.focusedItem = 0
+	u.pages.SwitchToPage(PAGE_IMPORT)
+	u.app.SetFocus(u.focusableItems[0])
+}
+
+// loadImport validates the file and displays the changes it would
+// make to the current network state
+func (u *UI) loadImport(path string) {
+	u.importState = nil
+	u.importChanges = nil
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var imported net.ImportedState
+		if imported, err = net.ParseNMState(data); err == nil {
+			u.importState = &imported
+		}
+	}
+	if err != nil {
+		u.logger.Infof("invalid NMState file %s: %v", path, err)
+		u.importView.SetText(fmt.Sprintf(IMPORT_INVALID_TEXT, path, tview.Escape(err.Error())))
+		return
+	}
+
+	current, err := u.retrieveNetState()
+	if err != nil {
+		u.importState = nil
+		u.importView.SetText(fmt.Sprintf(IMPORT_RETRIEVE_FAILED_TEXT, tview.Escape(err.Error())))
+		return
+	}
+	u.importChanges = net.Diff(current, u.importState.Predict(current))
+	if len(u.importChanges) == 0 {
+		u.importView.SetText(fmt.Sprintf(IMPORT_NO_CHANGES_TEXT, path))
+		return
+	}
+
+	text := strings.Builder{}
+	text.WriteString(fmt.Sprintf(IMPORT_CHANGES_TEXT, path))
+	for _, change := range u.importChanges {
+		color := colorModified
+		switch change.Kind {
+		case net.ChangeAdded:
+			color = colorAdded
+		case net.ChangeRemoved:
+			color = colorRemoved
+		}
+		text.WriteString(fmt.Sprintf("[#%06x]%s[black]\n", color.Hex(), tview.Escape(change.String())))
+	}
+	u.importView.SetText(text.String()).ScrollToBeginning()
+}
+
+func (u *UI) applyImport() {
+	if u.importState == nil {
+		u.showNetModal(IMPORT_NOT_LOADED_TEXT, []string{BACK_BUTTON}, func(string) {
+			u.setFocusToImport()
+		})
+		return
+	}
+	if len(u.importChanges) == 0 {
+		u.showNetModal(NET_EDITOR_NO_CHANGES_TEXT, []string{BACK_BUTTON}, func(string) {
+			u.setFocusToImport()
+		})
+		return
+	}
+
+	state := u.importState.JSON
+	u.showNetModal(NET_EDITOR_CONFIRM_TEXT, []string{NET_EDITOR_APPLY_BUTTON, NET_EDITOR_CANCEL_BUTTON}, func(label string) {
+		if label != NET_EDITOR_APPLY_BUTTON {
+			u.setFocusToImport()
+			return
+		}
+		u.showNetModal(NET_EDITOR_APPLYING_TEXT, []string{}, nil)
+		go u.applyNetworkState(state, nil, u.setFocusToImport, u.importPageDone)
+	})
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/media"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportPage(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return testNetState(t), nil
+	}
+	dir := t.TempDir()
+	ui.removableMounts = func() ([]media.Mount, error) {
+		return []media.Mount{{Device: "/dev/sdb1", Path: dir}}, nil
+	}
+	path := filepath.Join(dir, "network.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("interfaces:\n- name: eth1\n  state: up\n  mtu: 9000\n"), 0644))
+	invalidPath := filepath.Join(dir, "invalid.yaml")
+	assert.NoError(t, os.WriteFile(invalidPath, []byte("hosts: []\n"), 0644))
+
+	ui.ShowImportPage(func() {})
+	page, _ := ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_IMPORT, page)
+
+	// the first file found is previewed
+	assert.Equal(t, invalidPath, ui.importForm.GetFormItemByLabel(FIELD_IMPORT_FILE).(*tview.InputField).GetText())
+	assert.Contains(t, ui.importView.GetText(true), "unknown nmstate keys: hosts")
+	assert.Nil(t, ui.importState)
+	ui.applyImport()
+	page, _ = ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_NET_MODAL, page)
+
+	ui.loadImport(path)
+	assert.NotNil(t, ui.importState)
+	assert.Equal(t, []string{"~ interfaces/eth1/mtu: 1500 -> 9000"}, changeStrings(ui.importChanges))
+	assert.Contains(t, ui.importView.GetText(true), "~ interfaces/eth1/mtu: 1500 -> 9000")
+}
+
+func changeStrings(changes []net.Change) []string {
+	items := []string{}
+	for _, change := range changes {
+		items = append(items, change.String())
+	}
+	return items
+}
@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/logs"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PAGE_LOGS              string = "logs"
+	FIELD_LOG_SOURCE       string = "Source: "
+	FIELD_LOG_LEVEL        string = "Level: "
+	FIELD_LOG_SEARCH       string = "Search: "
+	LOGS_BACK_BUTTON       string = "<Back>"
+	ALL_LOG_SOURCES_OPTION string = "all"
+	AGENT_TUI_LOG_SOURCE   string = "agent-tui"
+
+	logsPollInterval = 2 * time.Second
+	// Maximum number of entries kept in memory by the viewer
+	maxLogEntries = 5000
+	// Number of entries initially read from each journal
+	journalInitialLines = 500
+)
+
+// Units whose journal is displayed when not configured otherwise
+var defaultJournalUnits = []string{
+	"agent.service",
+	"agent-interactive-console.service",
+	"assisted-service.service",
+}
+
+var logLevelOptions = []logrus.Level{
+	logrus.ErrorLevel,
+	logrus.WarnLevel,
+	logrus.InfoLevel,
+	logrus.DebugLevel,
+}
+
+func (u *UI) createLogPage(config checks.Config) {
+	units := config.JournalUnits
+	if len(units) == 0 {
+		units = defaultJournalUnits
+	}
+	u.logSources = []logs.Source{logs.NewFileSource(AGENT_TUI_LOG_SOURCE, config.LogPath)}
+	for _, unit := range units {
+		u.logSources = append(u.logSources, logs.NewJournalSource(unit, journalInitialLines))
+	}
+
+	sourceOptions := []string{ALL_LOG_SOURCES_OPTION}
+	for _, s := range u.logSources {
+		sourceOptions = append(sourceOptions, s.Name())
+	}
+	u.logSourceDropDown = tview.NewDropDown().
+		SetLabel(FIELD_LOG_SOURCE).
+		SetOptions(sourceOptions, func(text string, index int) {
+			u.refreshLogView()
+		}).
+		SetCurrentOption(0)
+
+	levelOptions := []string{}
+	for _, l := range logLevelOptions {
+		levelOptions = append(levelOptions, l.String())
+	}
+	u.logLevelDropDown = tview.NewDropDown().
+		SetLabel(FIELD_LOG_LEVEL).
+		SetOptions(levelOptions, func(text string, index int) {
+			u.refreshLogView()
+		}).
+		SetCurrentOption(2)
+
+	u.logSearch = tview.NewInputField().
+		SetLabel(FIELD_LOG_SEARCH).
+		SetFieldWidth(30).
+		SetChangedFunc(func(text string) {
+			u.refreshLogView()
+		})
+
+	u.logSourceDropDown.SetLabelColor(newt.ColorBlack)
+	u.logLevelDropDown.SetLabelColor(newt.ColorBlack)
+	u.logSearch.SetLabelColor(newt.ColorBlack)
+	u.logSearch.SetFieldTextColor(newt.ColorGray)
+
+	filters := tview.NewFlex().
+		AddItem(u.logSourceDropDown, 0, 2, false).
+		AddItem(u.logLevelDropDown, 0, 1, false).
+		AddItem(u.logSearch, 0, 2, false)
+
+	u.logView = tview.NewTextView()
+	u.logView.SetDynamicColors(true).
+		SetWrap(true).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack).
+		SetTitleColor(newt.ColorBlack)
+	u.logView.SetBackgroundColor(newt.ColorGray)
+
+	u.logBackForm = tview.NewForm()
+	u.logBackForm.SetButtonsAlign(tview.AlignCenter)
+	u.logBackForm.AddButton(LOGS_BACK_BUTTON, func() {
+		u.hideLogPage()
+	})
+	u.logBackForm.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
+		Foreground(newt.ColorGray))
+	u.logBackForm.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
+		Foreground(newt.ColorBlack))
+
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(filters, 1, 0, false).
+		AddItem(u.logView, 0, 1, false).
+		AddItem(u.logBackForm, 3, 0, false)
+	mainFlex.SetTitle("  Logs  ").
+		SetTitleColor(newt.ColorRed).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	mainFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			u.focusedItem++
+			if u.focusedItem > len(u.focusableItems)-1 {
+				u.focusedItem = 0
+			}
+		case tcell.KeyBacktab:
+			u.focusedItem--
+			if u.focusedItem < 0 {
+				u.focusedItem = len(u.focusableItems) - 1
+			}
+		case tcell.KeyESC:
+			if u.logView.HasFocus() {
+				u.hideLogPage()
+				return nil
+			}
+			return event
+		default:
+			return event
+		}
+		u.app.SetFocus(u.focusableItems[u.focusedItem])
+		return nil
+	})
+
+	u.pages.AddPage(PAGE_LOGS, mainFlex, true, false)
+}
+
+// ShowLogPage displays the log viewer. The doneFunc callback
+// is used to go back to the previous page.
+func (u *UI) ShowLogPage(doneFunc func()) {
+	u.logPageDone = doneFunc
+	u.logPageStop = make(chan struct{})
+
+	u.focusableItems = []tview.Primitive{
+		u.logView,
+		u.logSourceDropDown,
+		u.logLevelDropDown,
+		u.logSearch,
+		u.logBackForm.GetButton(0),
+	}
+	u.focusedItem = 0
+	u.pages.SwitchToPage(PAGE_LOGS)
+	u.app.SetFocus(u.logView)
+	u.logView.ScrollToEnd()
+
+	go u.pollLogSources(u.logPageStop)
+}
+
+func (u *UI) hideLogPage() {
+	close(u.logPageStop)
+	u.focusedItem = 0
+	u.logPageDone()
+}
+
+func (u *UI) pollLogSources(stop chan struct{}) {
+	ticker := time.NewTicker(logsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		newEntries := []logs.Entry{}
+		for _, source := range u.logSources {
+			entries, err := source.ReadNew()
+			if err != nil {
+				u.logger.Debugf("failed to read logs from %s: %v", source.Name(), err)
+			}
+			newEntries = append(newEntries, entries...)
+		}
+
+		if len(newEntries) > 0 {
+			u.app.QueueUpdateDraw(func() {
+				u.addLogEntries(newEntries)
+				u.refreshLogView()
+			})
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *UI) addLogEntries(entries []logs.Entry) {
+	u.logEntries = append(u.logEntries, entries...)
+	logs.SortByTime(u.logEntries)
+	if len(u.logEntries) > maxLogEntries {
+		u.logEntries = u.logEntries[len(u.logEntries)-maxLogEntries:]
+	}
+}
+
+func (u *UI) refreshLogView() {
+	if u.logView == nil {
+		return
+	}
+
+	source := ""
+	if index, option := u.logSourceDropDown.GetCurrentOption(); index > 0 {
+		source = option
+	}
+	level := logrus.InfoLevel
+	if index, _ := u.logLevelDropDown.GetCurrentOption(); index >= 0 {
+		level = logLevelOptions[index]
+	}
+
+	filtered := logs.Filter(u.logEntries, source, level, u.logSearch.GetText())
+	lines := make([]string, 0, len(filtered))
+	for _, e := range filtered {
+		lines = append(lines, formatLogEntry(e))
+	}
+	u.logView.SetTitle(fmt.Sprintf("  %d of %d entries  ", len(filtered), len(u.logEntries)))
+	u.logView.SetText(strings.Join(lines, "\n"))
+}
+
+func formatLogEntry(e logs.Entry) string {
+	color := "-"
+	switch {
+	case e.Level <= logrus.ErrorLevel:
+		color = "red"
+	case e.Level == logrus.WarnLevel:
+		color = "darkorange"
+	case e.Level >= logrus.DebugLevel:
+		color = "gray"
+	}
+
+	level := strings.ToUpper(e.Level.String())
+	if len(level) > 4 {
+		level = level[:4]
+	}
+	timestamp := "--:--:--"
+	if !e.Time.IsZero() {
+		timestamp = e.Time.Local().Format("15:04:05")
+	}
+	return fmt.Sprintf("%s [%s]%-4s[-] [::b]%s[::-] %s",
+		timestamp, color, level, tview.Escape(e.Source), tview.Escape(strings.TrimRight(e.Message, "\n")))
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+)
+
+const (
+	NET_KEEP_BUTTON   string = "<Keep>"
+	NET_REVERT_BUTTON string = "<Revert>"
+
+	NET_EDITOR_APPLYING_TEXT     = "Applying the network configuration and verifying the connectivity..."
+	NET_EDITOR_APPLY_FAILED_TEXT = "Failed to apply the network configuration, the previous configuration has been restored:\n\n%v"
+	NET_KEEP_SETTINGS_TEXT       = "The network configuration has been applied and the connectivity checks did not get worse.\n\n" +
+		"Keep these settings?\n\nThe previous configuration will be restored in [red]%.f[white] seconds."
+	NET_REGRESSION_TEXT    = "The connectivity got worse after applying the network configuration, the following checks are now failing:\n\n%s\n\n"
+	NET_VERIFY_FAILED_TEXT = "The network configuration has been applied but could not be verified:\n\n%v\n\n"
+	NET_ROLLING_BACK_TEXT  = "Restoring the previous network configuration..."
+	NET_ROLLED_BACK_TEXT   = "The previous network configuration has been restored."
+	NET_COMMITTED_TEXT     = "The network configuration has been saved."
+	NET_ROLLBACK_FAILED    = "Failed to restore the previous network configuration:\n\n%v"
+	NET_COMMIT_FAILED      = "Failed to save the network configuration, it will be rolled back automatically:\n\n%v"
+
+	// Time left to the verification and to the connectivity checks,
+	// bounded by checks.ConnectivityChecksTimeout, to complete before
+	// the checkpoint is rolled back automatically by nmstate
+	checkpointChecksTimeout = 2 * time.Minute
+)
+
+func (u *UI) runChecksOnce() []checks.CheckResult {
+	if u.runChecks == nil {
+		return nil
+	}
+	return u.runChecks()
+}
+
+// applyNetworkState applies the state in a nmstate checkpoint. The
+// checkpoint is rolled back when the connectivity gets worse, and
+// otherwise the user has to confirm the new settings before the
+// countdown expires. The optional verifyFunc can check the result
+// before the connectivity. The backFunc callback is used when the
+// state is not applied or is rolled back, and doneFunc when it is
+// committed.
+func (u *UI) applyNetworkState(state interface{}, verifyFunc func() error, backFunc, doneFunc func()) {
+	before := u.runChecksOnce()
+
+	u.logger.Infof("applying network configuration: %s", formatState(state))
+	// the checkpoint must outlive both the checks and the prompt
+	err := u.applyCheckpoint(state, checkpointChecksTimeout+u.promptTimeout)
+	if err != nil {
+		u.logger.Infof("failed to apply the network configuration: %v", err)
+		u.app.QueueUpdateDraw(func() {
+			u.showNetModal(fmt.Sprintf(NET_EDITOR_APPLY_FAILED_TEXT, err), []string{BACK_BUTTON}, func(string) {
+				backFunc()
+			})
+		})
+		return
+	}
+
+	u.verifyCheckpoint(before, verifyFunc, backFunc, doneFunc)
+}
+
+// verifyCheckpoint rolls back the checkpoint when the verifyFunc fails
+// or when checks successful before the change are now failing, and
+// otherwise asks the user to confirm the new settings
+func (u *UI) verifyCheckpoint(before []checks.CheckResult, verifyFunc func() error, backFunc, doneFunc func()) {
+	if verifyFunc != nil {
+		if err := verifyFunc(); err != nil {
+			u.logger.Infof("failed to verify the network configuration: %v, rolling back", err)
+			u.finishCheckpoint(false, fmt.Sprintf(NET_VERIFY_FAILED_TEXT, err), backFunc, doneFunc)
+			return
+		}
+	}
+
+	after := u.runChecksOnce()
+	if regressions := checks.Regressions(before, after); len(regressions) > 0 {
+		u.logger.Infof("checks failing after the network change: %s, rolling back", strings.Join(regressions, ", "))
+		u.finishCheckpoint(false, fmt.Sprintf(NET_REGRESSION_TEXT, describeChecks(regressions)), backFunc, doneFunc)
+		return
+	}
+
+	u.app.QueueUpdateDraw(func() {
+		u.showKeepSettingsModal(backFunc, doneFunc)
+	})
+}
+
+// formatState returns the JSON representation of the state, for logging
+func formatState(state interface{}) string {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Sprintf("%+v", state)
+	}
+	return string(data)
+}
+
+func describeChecks(checkTypes []string) string {
+	descriptions := []string{}
+	for _, checkType := range checkTypes {
+		descriptions = append(descriptions, checkDescription(checkType))
+	}
+	return strings.Join(descriptions, "\n")
+}
+
+func (u *UI) showKeepSettingsModal(backFunc, doneFunc func()) {
+	// the channel is buffered so that the button handler never waits
+	// for the countdown goroutine, which may be waiting for the UI
+	select {
+	case <-u.checkpointCancel:
+	default:
+	}
+	u.showNetModal(fmt.Sprintf(NET_KEEP_SETTINGS_TEXT, u.promptTimeout.Seconds()),
+		[]string{NET_KEEP_BUTTON, NET_REVERT_BUTTON}, func(label string) {
+			u.checkpointCancel <- true
+			go u.finishCheckpoint(label == NET_KEEP_BUTTON, "", backFunc, doneFunc)
+		})
+
+	u.startCountdownTimer(u.promptTimeout, u.checkpointCancel, func(remaining float64) {
+		u.app.QueueUpdateDraw(func() {
+			u.netModal.SetText(fmt.Sprintf(NET_KEEP_SETTINGS_TEXT, remaining))
+		})
+	}, func() {
+		u.logger.Infof("network configuration not confirmed, rolling back")
+		u.finishCheckpoint(false, "", backFunc, doneFunc)
+	})
+}
+
+// finishCheckpoint commits or rolls back the checkpoint, and displays
+// the outcome prefixed by the reason of the rollback, if any
+func (u *UI) finishCheckpoint(commit bool, reason string, backFunc, doneFunc func()) {
+	if !commit {
+		u.app.QueueUpdateDraw(func() {
+			u.showNetModal(reason+NET_ROLLING_BACK_TEXT, []string{}, nil)
+		})
+	}
+
+	text := reason
+	if commit {
+		if err := u.commitCheckpoint(); err != nil {
+			u.logger.Infof("failed to commit the network configuration: %v", err)
+			text += fmt.Sprintf(NET_COMMIT_FAILED, err)
+		} else {
+			u.logger.Infof("network configuration committed")
+			text += NET_COMMITTED_TEXT
+		}
+	} else {
+		if err := u.rollbackCheckpoint(); err != nil {
+			u.logger.Infof("failed to roll back the network configuration: %v", err)
+			text += fmt.Sprintf(NET_ROLLBACK_FAILED, err)
+		} else {
+			u.logger.Infof("network configuration rolled back")
+			text += NET_ROLLED_BACK_TEXT
+		}
+	}
+
+	u.app.QueueUpdateDraw(func() {
+		u.showNetModal(text, []string{NET_EDITOR_OK_BUTTON}, func(string) {
+			if commit {
+				doneFunc()
+				return
+			}
+			backFunc()
+		})
+	})
+}
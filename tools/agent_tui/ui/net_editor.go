@@ -0,0 +1,500 @@
+package ui
+
+import (
+	"fmt"
+	gonet "net"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_NET_EDITOR string = "netEditor"
+
+	FIELD_IPV4_MODE      string = "IPv4: "
+	FIELD_IPV4_ADDRESSES string = "IPv4 addresses: "
+	FIELD_IPV4_GATEWAY   string = "IPv4 gateway: "
+	FIELD_IPV6_MODE      string = "IPv6: "
+	FIELD_IPV6_ADDRESSES string = "IPv6 addresses: "
+	FIELD_IPV6_GATEWAY   string = "IPv6 gateway: "
+	FIELD_MTU            string = "MTU: "
+	FIELD_DNS_SERVERS    string = "DNS servers: "
+	FIELD_SEARCH_DOMAINS string = "Search domains: "
+
+	NET_EDITOR_APPLY_BUTTON    string = "<Apply>"
+	NET_EDITOR_BOND_BUTTON     string = "<Bond/VLAN>"
+	NET_EDITOR_ADVANCED_BUTTON string = "<Advanced (nmtui)>"
+	NET_EDITOR_CANCEL_BUTTON   string = "<Cancel>"
+	NET_EDITOR_OK_BUTTON       string = "<Ok>"
+
+	IP_MODE_DHCP     string = "DHCP"
+	IP_MODE_STATIC   string = "Static"
+	IP_MODE_DISABLED string = "Disabled"
+
+	NET_EDITOR_NO_CHANGES_TEXT = "No changes to apply."
+	NET_EDITOR_CONFIRM_TEXT    = "Apply the network configuration?\n\nThe connectivity may be briefly interrupted."
+	NET_EDITOR_RETRIEVE_FAILED = "Failed to retrieve the current network configuration:\n\n%v\n\nThe network can still be configured using nmtui."
+)
+
+var ipModeOptions = []string{IP_MODE_DHCP, IP_MODE_STATIC, IP_MODE_DISABLED}
+
+// ifaceFields are the values of the editor fields for an interface
+type ifaceFields struct {
+	ipv4Mode      string
+	ipv4Addresses string
+	ipv4Gateway   string
+	ipv6Mode      string
+	ipv6Addresses string
+	ipv6Gateway   string
+	mtu           string
+}
+
+// ifaceEdit tracks the edits of an interface, so that only the
+// interfaces modified by the user are part of the desired state
+type ifaceEdit struct {
+	name    string
+	ifType  string
+	initial ifaceFields
+	fields  ifaceFields
+}
+
+func newIfaceEdit(settings net.IfaceSettings) *ifaceEdit {
+	fields := ifaceFields{
+		ipv4Mode:      ipMode(settings.IPv4),
+		ipv4Addresses: net.FormatAddresses(settings.IPv4.Addresses),
+		ipv4Gateway:   formatIP(settings.IPv4.Gateway),
+		ipv6Mode:      ipMode(settings.IPv6),
+		ipv6Addresses: net.FormatAddresses(settings.IPv6.Addresses),
+		ipv6Gateway:   formatIP(settings.IPv6.Gateway),
+	}
+	if settings.MTU != 0 {
+		fields.mtu = strconv.Itoa(settings.MTU)
+	}
+	return &ifaceEdit{
+		name:    settings.Name,
+		ifType:  settings.Type,
+		initial: fields,
+		fields:  fields,
+	}
+}
+
+func ipMode(settings net.IPSettings) string {
+	switch {
+	case !settings.Enabled:
+		return IP_MODE_DISABLED
+	case settings.DHCP:
+		return IP_MODE_DHCP
+	default:
+		return IP_MODE_STATIC
+	}
+}
+
+func formatIP(ip gonet.IP) string {
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+func (e *ifaceEdit) changed() bool {
+	return e.fields != e.initial
+}
+
+// settings parses the fields. The addresses and the gateway are
+// ignored when the family is not statically configured.
+func (e *ifaceEdit) settings() (net.IfaceSettings, error) {
+	settings := net.IfaceSettings{
+		Name: e.name,
+		Type: e.ifType,
+	}
+	errs := []string{}
+
+	if mtu := strings.TrimSpace(e.fields.mtu); mtu != "" {
+		value, err := strconv.Atoi(mtu)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("MTU %s is not a number", mtu))
+		}
+		settings.MTU = value
+	}
+
+	var err error
+	settings.IPv4, err = parseIPSettings("IPv4", e.fields.ipv4Mode, e.fields.ipv4Addresses, e.fields.ipv4Gateway)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	settings.IPv6, err = parseIPSettings("IPv6", e.fields.ipv6Mode, e.fields.ipv6Addresses, e.fields.ipv6Gateway)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return settings, fmt.Errorf("%s: %s", e.name, strings.Join(errs, ", "))
+	}
+	return settings, nil
+}
+
+func parseIPSettings(family, mode, addresses, gateway string) (net.IPSettings, error) {
+	settings := net.IPSettings{
+		Enabled: mode != IP_MODE_DISABLED,
+		DHCP:    mode == IP_MODE_DHCP,
+	}
+	if mode != IP_MODE_STATIC {
+		return settings, nil
+	}
+
+	var err error
+	settings.Addresses, err = net.ParseAddresses(addresses)
+	if err != nil {
+		return settings, err
+	}
+	if gateway = strings.TrimSpace(gateway); gateway != "" {
+		settings.Gateway = gonet.ParseIP(gateway)
+		if settings.Gateway == nil {
+			return settings, fmt.Errorf("%s gateway %s is not a valid IP address", family, gateway)
+		}
+	}
+	return settings, nil
+}
+
+// dnsEdit tracks the edits of the DNS settings
+type dnsEdit struct {
+	initialServers, initialSearch string
+	servers, search               string
+}
+
+func newDNSEdit(config net.DNSConfig) *dnsEdit {
+	servers := strings.Join(config.Servers, ", ")
+	search := strings.Join(config.SearchDomains, ", ")
+	return &dnsEdit{
+		initialServers: servers,
+		initialSearch:  search,
+		servers:        servers,
+		search:         search,
+	}
+}
+
+func (d *dnsEdit) changed() bool {
+	return d.servers != d.initialServers || d.search != d.initialSearch
+}
+
+func (d *dnsEdit) config() net.DNSConfig {
+	return net.DNSConfig{
+		Servers:       splitList(d.servers),
+		SearchDomains: splitList(d.search),
+	}
+}
+
+func splitList(text string) []string {
+	items := []string{}
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// networkSettings returns the validated settings of the modified
+// interfaces, and the DNS configuration if modified
+func networkSettings(edits []*ifaceEdit, dns *dnsEdit) (net.NetworkSettings, error) {
+	settings := net.NetworkSettings{}
+	errs := []string{}
+	for _, edit := range edits {
+		if !edit.changed() {
+			continue
+		}
+		iface, err := edit.settings()
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		settings.Ifaces = append(settings.Ifaces, iface)
+	}
+	if dns != nil && dns.changed() {
+		config := dns.config()
+		settings.DNS = &config
+	}
+	if len(errs) > 0 {
+		return settings, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return settings, settings.Validate()
+}
+
+func (u *UI) createNetEditorPage() {
+	u.netEditorIfaces = tview.NewList().
+		ShowSecondaryText(false).
+		SetMainTextColor(newt.ColorBlack).
+		SetSelectedTextColor(newt.ColorGray).
+		SetSelectedBackgroundColor(newt.ColorRed).
+		SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+			u.selectNetEditorIface(index)
+		})
+	u.netEditorIfaces.SetBackgroundColor(newt.ColorGray)
+	u.netEditorIfaces.SetTitle(" Interfaces ").
+		SetTitleColor(newt.ColorBlack).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	u.netEditorForm = tview.NewForm()
+	u.netEditorForm.SetItemPadding(0)
+	u.netEditorForm.SetBorder(true).
+		SetBorderColor(newt.ColorBlack).
+		SetTitleColor(newt.ColorBlack)
+	u.netEditorForm.
+		AddDropDown(FIELD_IPV4_MODE, ipModeOptions, 0, func(option string, index int) {
+			u.updateNetEditorField(func(f *ifaceFields) { f.ipv4Mode = option })
+		}).
+		AddInputField(FIELD_IPV4_ADDRESSES, "", 40, nil, func(text string) {
+			u.updateNetEditorField(func(f *ifaceFields) { f.ipv4Addresses = text })
+		}).
+		AddInputField(FIELD_IPV4_GATEWAY, "", 40, nil, func(text string) {
+			u.updateNetEditorField(func(f *ifaceFields) { f.ipv4Gateway = text })
+		}).
+		AddDropDown(FIELD_IPV6_MODE, ipModeOptions, 0, func(option string, index int) {
+			u.updateNetEditorField(func(f *ifaceFields) { f.ipv6Mode = option })
+		}).
+		AddInputField(FIELD_IPV6_ADDRESSES, "", 40, nil, func(text string) {
+			u.updateNetEditorField(func(f *ifaceFields) { f.ipv6Addresses = text })
+		}).
+		AddInputField(FIELD_IPV6_GATEWAY, "", 40, nil, func(text string) {
+			u.updateNetEditorField(func(f *ifaceFields) { f.ipv6Gateway = text })
+		}).
+		AddInputField(FIELD_MTU, "", 6, tview.InputFieldInteger, func(text string) {
+			u.updateNetEditorField(func(f *ifaceFields) { f.mtu = text })
+		})
+	u.netEditorForm.SetFieldTextColor(newt.ColorGray)
+
+	u.netEditorDNSForm = tview.NewForm()
+	u.netEditorDNSForm.SetItemPadding(0)
+	u.netEditorDNSForm.SetBorder(true).
+		SetBorderColor(newt.ColorBlack).
+		SetTitle(" DNS ").
+		SetTitleColor(newt.ColorBlack)
+	u.netEditorDNSForm.
+		AddInputField(FIELD_DNS_SERVERS, "", 50, nil, func(text string) {
+			if u.netEditorDNS != nil {
+				u.netEditorDNS.servers = text
+			}
+		}).
+		AddInputField(FIELD_SEARCH_DOMAINS, "", 50, nil, func(text string) {
+			if u.netEditorDNS != nil {
+				u.netEditorDNS.search = text
+			}
+		})
+	u.netEditorDNSForm.SetFieldTextColor(newt.ColorGray)
+
+	u.netEditorButtons = tview.NewForm()
+	u.netEditorButtons.SetButtonsAlign(tview.AlignCenter)
+	u.netEditorButtons.AddButton(NET_EDITOR_APPLY_BUTTON, func() {
+		u.applyNetEditor()
+	})
+	u.netEditorButtons.AddButton(NET_EDITOR_BOND_BUTTON, func() {
+		// the editor is reloaded afterwards, to show the new interfaces
+		u.showBondWizard(func() {
+			u.showNetEditor(u.netEditorDone)
+		})
+	})
+	u.netEditorButtons.AddButton(NET_EDITOR_ADVANCED_BUTTON, func() {
+		u.showNMTUIWithErrorDialog(u.netEditorDone)
+	})
+	u.netEditorButtons.AddButton(BACK_BUTTON, func() {
+		u.netEditorDone()
+	})
+	u.netEditorButtons.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
+		Foreground(newt.ColorGray))
+	u.netEditorButtons.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
+		Foreground(newt.ColorBlack))
+
+	description := tview.NewTextView().
+		SetText("Select an interface and edit its settings. Addresses use the <ip>/<prefix length> format, multiple values are comma separated.").
+		SetTextColor(newt.ColorBlack).
+		SetWordWrap(true)
+	description.SetBackgroundColor(newt.ColorGray)
+
+	ifaceFlex := tview.NewFlex().
+		AddItem(u.netEditorIfaces, 18, 0, false).
+		AddItem(u.netEditorForm, 0, 1, false)
+
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(description, 2, 0, false).
+		AddItem(ifaceFlex, 9, 0, false).
+		AddItem(u.netEditorDNSForm, 4, 0, false).
+		AddItem(u.netEditorButtons, 3, 0, false)
+	mainFlex.SetTitle("  Network configuration  ").
+		SetTitleColor(newt.ColorRed).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	mainFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			u.focusedItem++
+			if u.focusedItem > len(u.focusableItems)-1 {
+				u.focusedItem = 0
+			}
+		case tcell.KeyBacktab:
+			u.focusedItem--
+			if u.focusedItem < 0 {
+				u.focusedItem = len(u.focusableItems) - 1
+			}
+		case tcell.KeyESC:
+			if u.netEditorIfaces.HasFocus() {
+				u.netEditorDone()
+				return nil
+			}
+			return event
+		default:
+			return event
+		}
+		u.app.SetFocus(u.focusableItems[u.focusedItem])
+		return nil
+	})
+
+	width := 80
+	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(mainFlex, 2+9+4+3+2, 0, false).
+		AddItem(nil, 0, 1, false)
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(innerFlex, width, 1, false).
+		AddItem(nil, 0, 1, false)
+
+	u.pages.AddPage(PAGE_NET_EDITOR, flex, true, false)
+}
+
+// showNetEditor displays the network configuration editor, loaded
+// with the current network state. The doneFunc callback is used
+// to go back to the previous page.
+func (u *UI) showNetEditor(doneFunc func()) {
+	u.netEditorDone = func() {
+		u.focusedItem = 0
+		doneFunc()
+	}
+
+	netState, err := u.retrieveNetState()
+	if err != nil {
+		u.logger.Infof("failed to retrieve the network state: %v", err)
+		u.showNetModal(fmt.Sprintf(NET_EDITOR_RETRIEVE_FAILED, err),
+			[]string{NET_EDITOR_ADVANCED_BUTTON, BACK_BUTTON}, func(label string) {
+				if label == NET_EDITOR_ADVANCED_BUTTON {
+					u.showNMTUIWithErrorDialog(u.netEditorDone)
+					return
+				}
+				u.netEditorDone()
+			})
+		return
+	}
+	u.loadNetEditor(netState)
+
+	u.focusableItems = []tview.Primitive{u.netEditorIfaces}
+	for i := 0; i < u.netEditorForm.GetFormItemCount(); i++ {
+		u.focusableItems = append(u.focusableItems, u.netEditorForm.GetFormItem(i))
+	}
+	for i := 0; i < u.netEditorDNSForm.GetFormItemCount(); i++ {
+		u.focusableItems = append(u.focusableItems, u.netEditorDNSForm.GetFormItem(i))
+	}
+	for i := 0; i < u.netEditorButtons.GetButtonCount(); i++ {
+		u.focusableItems = append(u.focusableItems, u.netEditorButtons.GetButton(i))
+	}
+	u.setFocusToNetEditor()
+}
+
+func (u *UI) setFocusToNetEditor() {
+	u.focusedItem = 0
+	u.pages.SwitchToPage(PAGE_NET_EDITOR)
+	u.app.SetFocus(u.netEditorIfaces)
+}
+
+// loadNetEditor fills the editor with the interfaces of the network
+// state. The loopback interface can't be configured.
+func (u *UI) loadNetEditor(netState net.NetState) {
+	u.netEditorEdits = []*ifaceEdit{}
+	u.netEditorSelected = -1
+	u.netEditorIfaces.Clear()
+	for _, iface := range netState.Ifaces {
+		if iface.Type == "loopback" || iface.Name == "lo" {
+			continue
+		}
+		u.netEditorEdits = append(u.netEditorEdits, newIfaceEdit(net.NewIfaceSettings(netState, iface)))
+		u.netEditorIfaces.AddItem(iface.Name, "", 0, nil)
+	}
+
+	u.netEditorDNS = nil
+	dns := newDNSEdit(netState.DNS.Running)
+	u.netEditorDNSForm.GetFormItemByLabel(FIELD_DNS_SERVERS).(*tview.InputField).SetText(dns.servers)
+	u.netEditorDNSForm.GetFormItemByLabel(FIELD_SEARCH_DOMAINS).(*tview.InputField).SetText(dns.search)
+	u.netEditorDNS = dns
+
+	u.selectNetEditorIface(0)
+}
+
+// selectNetEditorIface displays the fields of the selected interface
+func (u *UI) selectNetEditorIface(index int) {
+	if index < 0 || index >= len(u.netEditorEdits) {
+		return
+	}
+	// the fields are updated while no interface is selected, to
+	// avoid recording the changes in the previous interface
+	u.netEditorSelected = -1
+	edit := u.netEditorEdits[index]
+	fields := edit.fields
+	setDropDownOption(u.netEditorForm, FIELD_IPV4_MODE, fields.ipv4Mode)
+	u.netEditorForm.GetFormItemByLabel(FIELD_IPV4_ADDRESSES).(*tview.InputField).SetText(fields.ipv4Addresses)
+	u.netEditorForm.GetFormItemByLabel(FIELD_IPV4_GATEWAY).(*tview.InputField).SetText(fields.ipv4Gateway)
+	setDropDownOption(u.netEditorForm, FIELD_IPV6_MODE, fields.ipv6Mode)
+	u.netEditorForm.GetFormItemByLabel(FIELD_IPV6_ADDRESSES).(*tview.InputField).SetText(fields.ipv6Addresses)
+	u.netEditorForm.GetFormItemByLabel(FIELD_IPV6_GATEWAY).(*tview.InputField).SetText(fields.ipv6Gateway)
+	u.netEditorForm.GetFormItemByLabel(FIELD_MTU).(*tview.InputField).SetText(fields.mtu)
+	u.netEditorForm.SetTitle(fmt.Sprintf(" %s (%s) ", edit.name, edit.ifType))
+	u.netEditorSelected = index
+}
+
+func setDropDownOption(form *tview.Form, label, option string) {
+	dropDown := form.GetFormItemByLabel(label).(*tview.DropDown)
+	for i, o := range ipModeOptions {
+		if o == option {
+			dropDown.SetCurrentOption(i)
+			return
+		}
+	}
+}
+
+func (u *UI) updateNetEditorField(update func(f *ifaceFields)) {
+	if u.netEditorSelected < 0 || u.netEditorSelected >= len(u.netEditorEdits) {
+		return
+	}
+	update(&u.netEditorEdits[u.netEditorSelected].fields)
+}
+
+func (u *UI) applyNetEditor() {
+	settings, err := networkSettings(u.netEditorEdits, u.netEditorDNS)
+	if err != nil {
+		u.showNetModal(err.Error(), []string{BACK_BUTTON}, func(string) {
+			u.setFocusToNetEditor()
+		})
+		return
+	}
+	if len(settings.Ifaces) == 0 && settings.DNS == nil {
+		u.showNetModal(NET_EDITOR_NO_CHANGES_TEXT, []string{BACK_BUTTON}, func(string) {
+			u.setFocusToNetEditor()
+		})
+		return
+	}
+
+	u.showNetModal(NET_EDITOR_CONFIRM_TEXT, []string{NET_EDITOR_APPLY_BUTTON, NET_EDITOR_CANCEL_BUTTON}, func(label string) {
+		if label != NET_EDITOR_APPLY_BUTTON {
+			u.setFocusToNetEditor()
+			return
+		}
+		u.showNetModal(NET_EDITOR_APPLYING_TEXT, []string{}, nil)
+		// applying the configuration and verifying the connectivity
+		// can take a while, so it's done in the background while the
+		// modal is displayed
+		go u.applyNetworkState(settings.DesiredState(), nil, u.setFocusToNetEditor, u.netEditorDone)
+	})
+}
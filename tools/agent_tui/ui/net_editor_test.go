@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func testNetState(t *testing.T) net.NetState {
+	addresses, err := net.ParseAddresses("192.168.111.80/24")
+	assert.NoError(t, err)
+	return net.NetState{
+		DNS: net.DNSResolver{Running: net.DNSConfig{Servers: []string{"192.168.111.1"}}},
+		Routes: net.RoutesRC{Running: []net.Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1"},
+		}},
+		Ifaces: []net.Iface{
+			{Name: "lo", Type: "loopback", MTU: 65536},
+			{Name: "eth0", Type: "ethernet", State: "up", MAC: "52:54:00:aa:bb:01", MTU: 1500, IPv4: net.IPConfig{Enabled: true, Addresses: addresses}},
+			{Name: "eth1", Type: "ethernet", State: "up", MAC: "52:54:00:aa:bb:02", MTU: 1500, IPv4: net.IPConfig{Enabled: true, DHCP: true}},
+		},
+	}
+}
+
+func TestNetworkSettings(t *testing.T) {
+	ns := testNetState(t)
+	eth0 := newIfaceEdit(net.NewIfaceSettings(ns, ns.Ifaces[1]))
+	eth1 := newIfaceEdit(net.NewIfaceSettings(ns, ns.Ifaces[2]))
+	dns := newDNSEdit(ns.DNS.Running)
+
+	assert.Equal(t, IP_MODE_STATIC, eth0.fields.ipv4Mode)
+	assert.Equal(t, "192.168.111.1", eth0.fields.ipv4Gateway)
+	assert.Equal(t, IP_MODE_DHCP, eth1.fields.ipv4Mode)
+	assert.Equal(t, IP_MODE_DISABLED, eth1.fields.ipv6Mode)
+
+	settings, err := networkSettings([]*ifaceEdit{eth0, eth1}, dns)
+	assert.NoError(t, err)
+	assert.Empty(t, settings.Ifaces)
+	assert.Nil(t, settings.DNS)
+
+	// only the modified interfaces are part of the settings
+	eth1.fields.ipv4Mode = IP_MODE_STATIC
+	eth1.fields.ipv4Addresses = "10.0.0.2/24"
+	eth1.fields.ipv4Gateway = "10.0.0.1"
+	eth1.fields.mtu = "9000"
+	dns.servers = "10.0.0.1, 10.0.0.254"
+	settings, err = networkSettings([]*ifaceEdit{eth0, eth1}, dns)
+	assert.NoError(t, err)
+	assert.Len(t, settings.Ifaces, 1)
+	assert.Equal(t, "eth1", settings.Ifaces[0].Name)
+	assert.Equal(t, 9000, settings.Ifaces[0].MTU)
+	assert.Equal(t, "10.0.0.1", settings.Ifaces[0].IPv4.Gateway.String())
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.254"}, settings.DNS.Servers)
+
+	eth1.fields.ipv4Gateway = "10.0.0"
+	dns.servers = "dns.example.com"
+	_, err = networkSettings([]*ifaceEdit{eth0, eth1}, dns)
+	assert.ErrorContains(t, err, "IPv4 gateway 10.0.0 is not a valid IP address")
+
+	eth1.fields.ipv4Gateway = "10.0.1.1"
+	_, err = networkSettings([]*ifaceEdit{eth0, eth1}, dns)
+	assert.ErrorContains(t, err, "IPv4 gateway 10.0.1.1 is not in the subnet of any address")
+	assert.ErrorContains(t, err, "DNS server dns.example.com is not a valid IP address")
+}
+
+func TestNetEditor(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return testNetState(t), nil
+	}
+
+	done := false
+	ui.showNetEditor(func() { done = true })
+	page, _ := ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_NET_EDITOR, page)
+	assert.Equal(t, 2, ui.netEditorIfaces.GetItemCount())
+	assert.Equal(t, "192.168.111.80/24", ui.netEditorForm.GetFormItemByLabel(FIELD_IPV4_ADDRESSES).(*tview.InputField).GetText())
+
+	// editing a field updates the selected interface only
+	ui.netEditorIfaces.SetCurrentItem(1)
+	ui.netEditorForm.GetFormItemByLabel(FIELD_MTU).(*tview.InputField).SetText("9000")
+	assert.Equal(t, "9000", ui.netEditorEdits[1].fields.mtu)
+	assert.False(t, ui.netEditorEdits[0].changed())
+	ui.netEditorIfaces.SetCurrentItem(0)
+	assert.Equal(t, "1500", ui.netEditorForm.GetFormItemByLabel(FIELD_MTU).(*tview.InputField).GetText())
+
+	ui.netEditorDone()
+	assert.True(t, done)
+
+	// the editor offers nmtui when the network state can't be retrieved
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return net.NetState{}, errors.New("nmstate failure")
+	}
+	ui.showNetEditor(func() {})
+	page, _ = ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_NET_MODAL, page)
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const PAGE_NET_MODAL string = "netModal"
+
+// A generic modal shared by the network configuration pages. The
+// buttons and the action taken when one of them is pressed depend on
+// the context, and are set by showNetModal.
+func (u *UI) createNetModal() {
+	u.netModal = tview.NewModal().
+		SetBackgroundColor(newt.ColorGray).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if u.netModalDone != nil {
+				u.netModalDone(buttonLabel)
+			}
+		})
+	u.netModal.
+		SetBorderColor(newt.ColorBlack).
+		SetBorder(true)
+	u.netModal.
+		SetButtonBackgroundColor(newt.ColorGray).
+		SetButtonTextColor(newt.ColorRed)
+	u.pages.AddPage(PAGE_NET_MODAL, u.netModal, true, false)
+}
+
+func (u *UI) showNetModal(text string, buttons []string, doneFunc func(label string)) {
+	u.netModalDone = doneFunc
+	u.netModal.ClearButtons()
+	u.netModal.AddButtons(buttons)
+	u.netModal.SetText(text)
+	u.pages.SwitchToPage(PAGE_NET_MODAL)
+	u.app.SetFocus(u.netModal)
+}
@@ -0,0 +1,505 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_NET_STATUS string = "netStatus"
+
+	NET_STATUS_TITLE         = "Network Status (live)"
+	NET_STATUS_CHANGES_TITLE = "Network Status (live) - %d changes at %s"
+	NET_STATUS_STATIC_TITLE  = "Network Status (not updated: %v)"
+	NET_STATUS_RAW_TITLE     = "nmstate output (%s) at %s"
+	NET_STATUS_RAW_FAILED    = "Failed to retrieve the nmstate output: %v"
+
+	NET_STATUS_LLDP_LISTENING = "listening for the switch advertisements..."
+	NET_STATUS_LLDP_NONE      = "no neighbor advertised"
+	NET_STATUS_LLDP_FAILED    = "listening failed, see the logs"
+
+	FIELD_NET_STATUS_SEARCH    string = "Search: "
+	NET_STATUS_SEARCH_MATCHES  string = "Search (%d/%d): "
+	NET_STATUS_SEARCH_NO_MATCH string = "Search (no match): "
+
+	netStatusTreeView = "tree"
+	netStatusRawView  = "raw"
+
+	// The notifications are usually received in bursts, for example
+	// when an interface goes up, so the state is retrieved once the
+	// burst is over
+	netStatusDebounce = 500 * time.Millisecond
+)
+
+var colorTagRegexp = regexp.MustCompile(`\[[a-zA-Z#0-9]*:?[a-zA-Z#0-9]*:?[a-zA-Z-]*\]`)
+
+func (u *UI) createNetStatusPage() {
+	u.netStatusTree = tview.NewTreeView()
+	u.netStatusTree.SetTitle(NET_STATUS_TITLE).
+		SetBackgroundColor(newt.ColorGray).
+		SetBorder(true).
+		SetBorderColor(tcell.ColorBlack).
+		SetTitleColor(tcell.ColorBlack)
+	u.netStatusTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			u.netStatusDone()
+			return nil
+		}
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		switch event.Rune() {
+		case 'q':
+			u.netStatusDone()
+		case '/':
+			u.app.SetFocus(u.netStatusSearch)
+		case 'n':
+			u.searchNetStatus(u.netStatusSearch.GetText(), true, false)
+		case 'N':
+			u.searchNetStatus(u.netStatusSearch.GetText(), false, false)
+		case '+':
+			u.netStatusTree.GetRoot().ExpandAll()
+		case '-':
+			// TreeNode.CollapseAll collapses only the node itself.
+			// The sections stay visible.
+			u.netStatusTree.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+				node.Collapse()
+				return true
+			}).Expand()
+			u.netStatusTree.SetCurrentNode(u.netStatusTree.GetRoot())
+		case 'v':
+			u.netStatusHideVirtual = !u.netStatusHideVirtual
+			u.renderNetStatus()
+		case 'd':
+			u.netStatusHideDown = !u.netStatusHideDown
+			u.renderNetStatus()
+		case 'r':
+			u.showNetStatusRaw()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	u.netStatusRaw = tview.NewTextView()
+	u.netStatusRaw.SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false).
+		SetBorder(true).
+		SetBorderColor(tcell.ColorBlack).
+		SetTitleColor(tcell.ColorBlack)
+	u.netStatusRaw.SetBackgroundColor(newt.ColorGray)
+	u.netStatusRaw.SetTextColor(newt.ColorBlack)
+	u.netStatusRaw.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyESC || event.Rune() == 'q':
+			u.netStatusDone()
+		case event.Rune() == 'r':
+			u.showNetStatusTree()
+		case event.Rune() == 'f':
+			u.netStatusRawJSON = !u.netStatusRawJSON
+			u.showNetStatusRaw()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	u.netStatusViews = tview.NewPages().
+		AddPage(netStatusTreeView, u.netStatusTree, true, true).
+		AddPage(netStatusRawView, u.netStatusRaw, true, false)
+
+	u.netStatusSearch = tview.NewInputField().
+		SetLabel(FIELD_NET_STATUS_SEARCH).
+		SetFieldWidth(30).
+		SetChangedFunc(func(text string) {
+			// the search restarts from the top at each change
+			u.searchNetStatus(text, true, true)
+		}).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyESC {
+				u.netStatusSearch.SetText("")
+			}
+			u.app.SetFocus(u.netStatusTree)
+		})
+	u.netStatusSearch.SetLabelColor(newt.ColorBlack)
+	u.netStatusSearch.SetFieldTextColor(newt.ColorGray)
+	u.netStatusSearch.SetBackgroundColor(newt.ColorGray)
+
+	u.netStatusHints = tview.NewTextView()
+	u.netStatusHints.SetDynamicColors(true)
+	u.netStatusHints.SetTextAlign(tview.AlignCenter)
+	u.netStatusHints.SetTextColor(newt.ColorBlack)
+	u.netStatusHints.SetBackgroundColor(newt.ColorGray)
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(u.netStatusSearch, 1, 0, false).
+			AddItem(u.netStatusViews, 0, 3, true).
+			AddItem(u.netStatusHints, 1, 0, false).
+			AddItem(nil, 5, 1, false), 0, 2, true).
+		AddItem(nil, 0, 1, false)
+
+	u.pages.AddPage(PAGE_NET_STATUS, flex, true, false)
+}
+
+// ShowNetStatusPage displays the network state, refreshed whenever the
+// kernel notifies a change of the links, addresses or routes. The
+// changes since the previous refresh are highlighted. The doneFunc
+// callback is used to go back to the previous page.
+func (u *UI) ShowNetStatusPage(doneFunc func()) {
+	stop := make(chan struct{})
+	u.netStatusDone = func() {
+		close(stop)
+		doneFunc()
+	}
+
+	netState, err := u.retrieveNetState()
+	if err != nil {
+		u.logger.Infof("failed to retrieve the network state: %v", err)
+		u.showNetModal(fmt.Sprintf(NET_EDITOR_RETRIEVE_FAILED, err), []string{BACK_BUTTON}, func(string) {
+			doneFunc()
+		})
+		return
+	}
+	u.netStatusSearch.SetText("")
+	u.startLLDP(netState, stop)
+	u.refreshNetStatus(netState, nil)
+	u.netStatusTree.SetTitle(NET_STATUS_TITLE)
+	u.netStatusViews.SwitchToPage(netStatusTreeView)
+
+	events, err := u.watchNetlink(stop)
+	if err != nil {
+		u.logger.Infof("failed to watch the network changes: %v", err)
+		u.netStatusTree.SetTitle(fmt.Sprintf(NET_STATUS_STATIC_TITLE, err))
+	} else {
+		go u.watchNetStatus(netState, events)
+	}
+
+	u.pages.SwitchToPage(PAGE_NET_STATUS)
+	u.app.SetFocus(u.netStatusTree)
+}
+
+// startLLDP listens for the LLDP advertisements received by the
+// physical interfaces while the page is displayed, during one
+// advertisement interval. The neighbors are added to the tree as they
+// are received, and logged.
+func (u *UI) startLLDP(netState net.NetState, stop <-chan struct{}) {
+	u.lldpRun++
+	run := u.lldpRun
+	u.lldpIfaces = []string{}
+	for _, nic := range u.physicalNICs(netState) {
+		u.lldpIfaces = append(u.lldpIfaces, nic.Name)
+	}
+	u.lldpNeighbors = map[string][]net.LLDPNeighbor{}
+	u.lldpErr = nil
+	u.lldpListening = len(u.lldpIfaces) > 0
+	if !u.lldpListening {
+		return
+	}
+
+	ifaces := u.lldpIfaces
+	go func() {
+		err := u.listenLLDP(ifaces, net.DefaultLLDPWindow, func(neighbor net.LLDPNeighbor) {
+			u.logger.Infof("LLDP neighbor of %s", neighbor)
+			u.app.QueueUpdateDraw(func() {
+				if run != u.lldpRun {
+					return
+				}
+				// a changed neighbor replaces the previous one
+				neighbors := u.lldpNeighbors[neighbor.Iface]
+				i := slices.IndexFunc(neighbors, func(n net.LLDPNeighbor) bool {
+					return n.ChassisID == neighbor.ChassisID && n.PortID == neighbor.PortID
+				})
+				if i < 0 {
+					neighbors = append(neighbors, neighbor)
+				} else {
+					neighbors[i] = neighbor
+				}
+				u.lldpNeighbors[neighbor.Iface] = neighbors
+				u.renderNetStatus()
+			})
+		}, stop)
+		if err != nil {
+			u.logger.Infof("failed to listen for the LLDP neighbors: %v", err)
+		}
+		u.app.QueueUpdateDraw(func() {
+			if run != u.lldpRun {
+				return
+			}
+			u.lldpListening = false
+			u.lldpErr = err
+			u.renderNetStatus()
+		})
+	}()
+}
+
+// watchNetStatus refreshes the page after each burst of notifications,
+// until the events channel is closed
+func (u *UI) watchNetStatus(netState net.NetState, events <-chan net.LinkEvent) {
+	for range events {
+		// wait for the end of the burst
+		debounce := time.After(netStatusDebounce)
+		for waiting := true; waiting; {
+			select {
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+			case <-debounce:
+				waiting = false
+			}
+		}
+
+		current, err := u.retrieveNetState()
+		if err != nil {
+			u.logger.Infof("failed to retrieve the network state: %v", err)
+			continue
+		}
+		changes := net.Diff(netState, current)
+		if len(changes) == 0 {
+			continue
+		}
+		for _, change := range changes {
+			u.logger.Infof("network change: %s", change)
+		}
+		netState = current
+
+		if affectsConnectivity(changes) && u.triggerChecks != nil {
+			u.logger.Infof("addresses or default routes changed, running the checks")
+			u.triggerChecks()
+		}
+		u.app.QueueUpdateDraw(func() {
+			u.refreshNetStatus(current, changes)
+			u.netStatusTree.SetTitle(fmt.Sprintf(NET_STATUS_CHANGES_TITLE, len(changes), time.Now().Format("15:04:05")))
+		})
+	}
+}
+
+// affectsConnectivity checks whether the changes include addresses,
+// interface states or default routes, that may change the results of
+// the checks
+func affectsConnectivity(changes []net.Change) bool {
+	for _, change := range changes {
+		path := change.Path
+		if strings.Contains(path, "/address/") || strings.HasSuffix(path, "/state") ||
+			strings.HasPrefix(path, "routes/0.0.0.0/0 ") || strings.HasPrefix(path, "routes/::/0 ") {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshNetStatus replaces the network state displayed, with the
+// changes since the previous one
+func (u *UI) refreshNetStatus(netState net.NetState, changes []net.Change) {
+	u.netStatusState = netState
+	u.netStatusChanges = changes
+	u.renderNetStatus()
+}
+
+// renderNetStatus rebuilds the tree with the interfaces not filtered
+// out, preserving the nodes expanded or collapsed by the user and the
+// selected one
+func (u *UI) renderNetStatus() {
+	netState, hidden := u.filterNetState(u.netStatusState)
+	findings := []net.Finding{}
+	for _, finding := range u.lintNetState(u.netStatusState) {
+		if !hidden[ifaceOfPath(finding.Path)] {
+			findings = append(findings, finding)
+		}
+	}
+	root := netStateTree(netState, u.netStatusChanges, findings)
+	switch {
+	case u.lldpListening:
+		addLLDPNeighbors(root, u.lldpIfaces, u.lldpNeighbors, NET_STATUS_LLDP_LISTENING, tcell.ColorBlack)
+	case u.lldpErr != nil:
+		addLLDPNeighbors(root, u.lldpIfaces, u.lldpNeighbors, NET_STATUS_LLDP_FAILED, colorWarning)
+	default:
+		addLLDPNeighbors(root, u.lldpIfaces, u.lldpNeighbors, NET_STATUS_LLDP_NONE, tcell.ColorBlack)
+	}
+
+	collapsed := map[string]bool{}
+	selected := ""
+	if oldRoot := u.netStatusTree.GetRoot(); oldRoot != nil {
+		current := u.netStatusTree.GetCurrentNode()
+		walkTree(oldRoot, "", func(node *tview.TreeNode, key string) {
+			if !node.IsExpanded() {
+				collapsed[key] = true
+			}
+			if node == current {
+				selected = key
+			}
+		})
+	}
+
+	var current *tview.TreeNode
+	walkTree(root, "", func(node *tview.TreeNode, key string) {
+		if collapsed[key] {
+			node.Collapse()
+		}
+		if key == selected {
+			current = node
+		}
+	})
+	if current == nil {
+		current = root
+	}
+	u.netStatusTree.SetRoot(root).SetCurrentNode(current)
+	u.netStatusHidden = len(hidden)
+	u.setNetStatusHints()
+}
+
+// filterNetState removes the virtual interfaces or the ones down, if
+// requested, returning the names of the interfaces removed. The
+// interfaces of the default routes are always kept.
+func (u *UI) filterNetState(netState net.NetState) (net.NetState, map[string]bool) {
+	hidden := map[string]bool{}
+	primaryIfaces := netState.PrimaryIfaces()
+	ifaces := []net.Iface{}
+	for _, iface := range netState.Ifaces {
+		_, primary := primaryIfaces[iface.Name]
+		if !primary && ((u.netStatusHideVirtual && u.isVirtualIface(iface)) ||
+			(u.netStatusHideDown && iface.State != "up")) {
+			hidden[iface.Name] = true
+			continue
+		}
+		ifaces = append(ifaces, iface)
+	}
+	netState.Ifaces = ifaces
+	return netState, hidden
+}
+
+// ifaceOfPath returns the name of the interface a change or finding
+// path refers to, if any
+func ifaceOfPath(path string) string {
+	name, found := strings.CutPrefix(path, net.IfacePath(""))
+	if !found {
+		return ""
+	}
+	name, _, _ = strings.Cut(name, "/")
+	return name
+}
+
+// setNetStatusHints displays the keys available in the current view
+func (u *UI) setNetStatusHints() {
+	if page, _ := u.netStatusViews.GetFrontPage(); page == netStatusRawView {
+		format := "JSON"
+		if u.netStatusRawJSON {
+			format = "YAML"
+		}
+		u.netStatusHints.SetText(fmt.Sprintf("[::b]f[::-] %s  [::b]r[::-] Tree  [::b]q[::-] Back", format))
+		return
+	}
+
+	onOff := func(hide bool) string {
+		if hide {
+			return "Show"
+		}
+		return "Hide"
+	}
+	hints := fmt.Sprintf("[::b]/[::-] Search  [::b]n[::-]/[::b]N[::-] Next/Prev  [::b]+[::-]/[::b]-[::-] Expand/Collapse  "+
+		"[::b]v[::-] %s virtual  [::b]d[::-] %s down  [::b]r[::-] Raw  [::b]q[::-] Back",
+		onOff(u.netStatusHideVirtual), onOff(u.netStatusHideDown))
+	if u.netStatusHidden > 0 {
+		hints = fmt.Sprintf("%d hidden  %s", u.netStatusHidden, hints)
+	}
+	u.netStatusHints.SetText(hints)
+}
+
+// searchNetStatus selects the next or previous node containing the
+// text, ignoring the case, expanding its ancestors. The search starts
+// from the root when restart is true, otherwise from the node after
+// or before the selected one, wrapping around.
+func (u *UI) searchNetStatus(text string, forward bool, restart bool) {
+	text = strings.ToLower(text)
+	if text == "" {
+		u.netStatusSearch.SetLabel(FIELD_NET_STATUS_SEARCH)
+		return
+	}
+
+	type visible struct {
+		node      *tview.TreeNode
+		ancestors []*tview.TreeNode
+	}
+	nodes := []visible{}
+	var visit func(node *tview.TreeNode, ancestors []*tview.TreeNode)
+	visit = func(node *tview.TreeNode, ancestors []*tview.TreeNode) {
+		nodes = append(nodes, visible{node, ancestors})
+		ancestors = append(ancestors[:len(ancestors):len(ancestors)], node)
+		for _, child := range node.GetChildren() {
+			visit(child, ancestors)
+		}
+	}
+	visit(u.netStatusTree.GetRoot(), nil)
+
+	matches := []int{}
+	current := 0
+	for i, n := range nodes {
+		if strings.Contains(strings.ToLower(stripColorTags(n.node.GetText())), text) {
+			matches = append(matches, i)
+		}
+		if n.node == u.netStatusTree.GetCurrentNode() {
+			current = i
+		}
+	}
+	if len(matches) == 0 {
+		u.netStatusSearch.SetLabel(NET_STATUS_SEARCH_NO_MATCH)
+		return
+	}
+
+	match := 0
+	switch {
+	case restart:
+		// the first match
+	case forward:
+		for match < len(matches) && matches[match] <= current {
+			match++
+		}
+		match %= len(matches)
+	default:
+		match = len(matches) - 1
+		for match >= 0 && matches[match] >= current {
+			match--
+		}
+		if match < 0 {
+			match = len(matches) - 1
+		}
+	}
+
+	found := nodes[matches[match]]
+	for _, ancestor := range found.ancestors {
+		ancestor.Expand()
+	}
+	u.netStatusTree.SetCurrentNode(found.node)
+	u.netStatusSearch.SetLabel(fmt.Sprintf(NET_STATUS_SEARCH_MATCHES, match+1, len(matches)))
+}
+
+// stripColorTags removes the color tags from the text of a node
+func stripColorTags(text string) string {
+	return colorTagRegexp.ReplaceAllString(text, "")
+}
+
+// walkTree visits the nodes with their keys, made of the keys of their
+// ancestors and either their reference or their text
+func walkTree(node *tview.TreeNode, parentKey string, visit func(node *tview.TreeNode, key string)) {
+	key := node.GetText()
+	if path, ok := node.GetReference().(string); ok {
+		key = path
+	}
+	key = parentKey + "/" + key
+	visit(node, key)
+	for _, child := range node.GetChildren() {
+		walkTree(child, key, visit)
+	}
+}
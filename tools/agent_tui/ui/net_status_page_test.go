@@ -0,0 +1,273 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetStatusPage(t *testing.T) {
+	ui, _ := newSimulatedUI(t, checks.Config{}, nil)
+
+	netState := testNetState(t)
+	states := make(chan net.NetState, 1)
+	ui.retrieveNetState = func() (net.NetState, error) {
+		select {
+		case netState = <-states:
+		default:
+		}
+		return netState, nil
+	}
+	events := make(chan net.LinkEvent)
+	ui.watchNetlink = func(stop <-chan struct{}) (<-chan net.LinkEvent, error) {
+		return events, nil
+	}
+	triggered := make(chan bool, 1)
+	ui.SetTriggerChecks(func() { triggered <- true })
+
+	startUI(t, ui)
+
+	// the updates are queued, since the page is refreshed in the background
+	ui.app.QueueUpdateDraw(func() {
+		ui.ShowNetStatusPage(func() {})
+		// collapse eth0 and select the MTU of eth1
+		interfaces := findNode(ui.netStatusTree.GetRoot(), "Interfaces")
+		findNode(interfaces, "eth0 (ethernet)").Collapse()
+		ui.netStatusTree.SetCurrentNode(findNode(findNode(interfaces, "eth1 (ethernet)"), "MTU: 1500"))
+	})
+	page, _ := ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_NET_STATUS, page)
+
+	changed := testNetState(t)
+	changed.Ifaces[2].MTU = 9000
+	changed.Ifaces[1].IPv4.Addresses = nil
+	states <- changed
+	events <- net.LinkEventLink
+	events <- net.LinkEventAddress
+
+	select {
+	case <-triggered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the checks were not triggered")
+	}
+	var eth0, eth1, current *tview.TreeNode
+	assert.Eventually(t, func() bool {
+		ui.app.QueueUpdate(func() {
+			interfaces := findNode(ui.netStatusTree.GetRoot(), "Interfaces")
+			eth0 = findNode(interfaces, "eth0 (ethernet)")
+			eth1 = findNode(interfaces, "eth1 (ethernet)")
+			current = ui.netStatusTree.GetCurrentNode()
+		})
+		return eth1 != nil && findNode(eth1, "~ MTU: 9000 (was 1500)") != nil
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.False(t, eth0.IsExpanded(), "the collapsed nodes stay collapsed")
+	assert.Equal(t, findNode(eth1, "~ MTU: 9000 (was 1500)"), current, "the selection is preserved")
+
+	close(events)
+}
+
+func TestNetStatusPageNotLive(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return testNetState(t), nil
+	}
+	ui.watchNetlink = func(stop <-chan struct{}) (<-chan net.LinkEvent, error) {
+		return nil, errors.New("permission denied")
+	}
+
+	ui.ShowNetStatusPage(func() {})
+	assert.Equal(t, "Network Status (not updated: permission denied)", ui.netStatusTree.GetTitle())
+	assert.NotNil(t, findNode(ui.netStatusTree.GetRoot(), "Interfaces"))
+}
+
+func TestNetStatusPageLLDP(t *testing.T) {
+	ui, _ := newSimulatedUI(t, checks.Config{}, nil)
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return testNetState(t), nil
+	}
+	ui.watchNetlink = func(stop <-chan struct{}) (<-chan net.LinkEvent, error) {
+		return nil, errors.New("permission denied")
+	}
+	ui.physicalNICs = func(ns net.NetState) []net.NIC {
+		return []net.NIC{{Name: "eth0"}, {Name: "eth1"}}
+	}
+	listened := make(chan []string, 1)
+	advertise := make(chan bool)
+	ui.listenLLDP = func(ifaces []string, window time.Duration, neighbor func(net.LLDPNeighbor), stop <-chan struct{}) error {
+		listened <- ifaces
+		<-advertise
+		neighbor(net.LLDPNeighbor{
+			Iface:      "eth0",
+			ChassisID:  "2c:6b:f5:11:22:00",
+			PortID:     "ge-0/0/12",
+			SystemName: "sw-rack3",
+			PortVLANID: 100,
+			VLANs:      []net.LLDPVLAN{{ID: 100, Name: "prov"}, {ID: 200, Name: "baremetal"}},
+			MTU:        9216,
+		})
+		<-advertise
+		return nil
+	}
+
+	startUI(t, ui)
+
+	ui.app.QueueUpdateDraw(func() {
+		ui.ShowNetStatusPage(func() {})
+	})
+	assert.Equal(t, []string{"eth0", "eth1"}, <-listened)
+	lldpNodes := func(iface string) (texts []string) {
+		ui.app.QueueUpdate(func() {
+			node := findNode(findNode(ui.netStatusTree.GetRoot(), "Interfaces"), iface)
+			for _, child := range node.GetChildren() {
+				if strings.HasPrefix(child.GetText(), "LLDP") {
+					texts = append(texts, child.GetText())
+					texts = append(texts, nodeTexts(child)...)
+				}
+			}
+		})
+		return texts
+	}
+	assert.Equal(t, []string{"LLDP: " + NET_STATUS_LLDP_LISTENING}, lldpNodes("eth1 (ethernet)"))
+	assert.Empty(t, lldpNodes("lo (loopback)"))
+
+	// the neighbors are displayed as they are received
+	advertise <- true
+	assert.Eventually(t, func() bool {
+		return len(lldpNodes("eth0 (ethernet)")) > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{
+		"LLDP: switch sw-rack3, port ge-0/0/12",
+		"Chassis ID: 2c:6b:f5:11:22:00",
+		"VLANs: 100 (untagged), 200 (baremetal)",
+		"MTU: 9216",
+	}, lldpNodes("eth0 (ethernet)"))
+	assert.Equal(t, []string{"LLDP: " + NET_STATUS_LLDP_LISTENING}, lldpNodes("eth1 (ethernet)"))
+
+	advertise <- true
+	assert.Eventually(t, func() bool {
+		nodes := lldpNodes("eth1 (ethernet)")
+		return len(nodes) == 1 && nodes[0] == "LLDP: "+NET_STATUS_LLDP_NONE
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, lldpNodes("eth0 (ethernet)"), 4)
+}
+
+func TestNetStatusPageNavigation(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+	netState := testNetState(t)
+	netState.Ifaces = append(netState.Ifaces,
+		net.Iface{Name: "eth2", Type: "ethernet", State: "down", MTU: 1500},
+		net.Iface{Name: "veth0", Type: "veth", State: "up", MTU: 1500})
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return netState, nil
+	}
+	ui.retrieveRawNetState = func() ([]byte, error) {
+		return []byte(`{"interfaces":[{"name":"eth0","mtu":1500}]}`), nil
+	}
+	ui.watchNetlink = func(stop <-chan struct{}) (<-chan net.LinkEvent, error) {
+		return nil, errors.New("permission denied")
+	}
+	ui.isVirtualIface = func(iface net.Iface) bool {
+		return iface.Type == "loopback" || iface.Type == "veth"
+	}
+	key := func(r rune) {
+		ui.netStatusTree.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	interfaces := func() []string {
+		return nodeTexts(findNode(ui.netStatusTree.GetRoot(), "Interfaces"))
+	}
+
+	ui.ShowNetStatusPage(func() {})
+	assert.Equal(t, []string{"eth0 (ethernet)", "lo (loopback)", "eth1 (ethernet)", "eth2 (ethernet)", "veth0 (veth)"}, interfaces())
+
+	// filters
+	key('v')
+	assert.Equal(t, []string{"eth0 (ethernet)", "eth1 (ethernet)", "eth2 (ethernet)"}, interfaces())
+	assert.Contains(t, ui.netStatusHints.GetText(false), "2 hidden")
+	key('d')
+	assert.Equal(t, []string{"eth0 (ethernet)", "eth1 (ethernet)"}, interfaces())
+	key('v')
+	key('d')
+	assert.Len(t, interfaces(), 5)
+
+	// incremental search
+	ui.netStatusSearch.SetText("mtu")
+	assert.Equal(t, "MTU: 1500", ui.netStatusTree.GetCurrentNode().GetText())
+	assert.Equal(t, "Search (1/5): ", ui.netStatusSearch.GetLabel())
+	key('n')
+	assert.Equal(t, "Search (2/5): ", ui.netStatusSearch.GetLabel())
+	key('N')
+	key('N')
+	assert.Equal(t, "Search (5/5): ", ui.netStatusSearch.GetLabel())
+	ui.netStatusSearch.SetText("no such item")
+	assert.Equal(t, NET_STATUS_SEARCH_NO_MATCH, ui.netStatusSearch.GetLabel())
+
+	// the ancestors of a match are expanded
+	key('-')
+	assert.False(t, findNode(ui.netStatusTree.GetRoot(), "Interfaces").IsExpanded())
+	ui.netStatusSearch.SetText("52:54:00:AA:BB:02")
+	assert.Equal(t, "MAC: 52:54:00:aa:bb:02", ui.netStatusTree.GetCurrentNode().GetText())
+	assert.True(t, findNode(ui.netStatusTree.GetRoot(), "Interfaces").IsExpanded())
+	key('+')
+	assert.True(t, findNode(findNode(ui.netStatusTree.GetRoot(), "Interfaces"), "eth0 (ethernet)").IsExpanded())
+
+	// raw output
+	key('r')
+	page, _ := ui.netStatusViews.GetFrontPage()
+	assert.Equal(t, netStatusRawView, page)
+	assert.Contains(t, ui.netStatusRaw.GetText(true), "- name: eth0")
+	assert.Contains(t, ui.netStatusRaw.GetTitle(), "(YAML)")
+	ui.netStatusRaw.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone))
+	assert.Contains(t, ui.netStatusRaw.GetText(true), `"name": "eth0",`)
+	assert.Contains(t, ui.netStatusRaw.GetTitle(), "(JSON)")
+	ui.netStatusRaw.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone))
+	page, _ = ui.netStatusViews.GetFrontPage()
+	assert.Equal(t, netStatusTreeView, page)
+}
+
+func TestFormatRawNetState(t *testing.T) {
+	raw := []byte(`{"dns-resolver":{"running":{"server":["fe80::1%eth0"]}},"interfaces":[{"name":"eth0","mtu":1500,"ipv4":{"enabled":true}}]}`)
+	key := colorTag(colorRawKey)
+	str := colorTag(colorRawString)
+	lit := colorTag(colorRawLiteral)
+
+	text, err := formatRawNetState(raw, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		key + "dns-resolver[-]:",
+		"    " + key + "running[-]:",
+		"        " + key + "server[-]:",
+		"            - " + str + "fe80::1%eth0[-]",
+		key + "interfaces[-]:",
+		"    - " + key + "name[-]: " + str + "eth0[-]",
+		"      " + key + "mtu[-]: " + lit + "1500[-]",
+		"      " + key + "ipv4[-]:",
+		"        " + key + "enabled[-]: " + lit + "true[-]",
+	}, strings.Split(text, "\n"))
+
+	text, err = formatRawNetState(raw, true)
+	assert.NoError(t, err)
+	lines := strings.Split(text, "\n")
+	assert.Equal(t, "{", lines[0])
+	assert.Equal(t, "  "+key+`"dns-resolver"[-]: {`, lines[1])
+	assert.Equal(t, "        "+str+`"fe80::1%eth0"[-]`, lines[4])
+	assert.Contains(t, lines, "      "+key+`"mtu"[-]: `+lit+"1500[-],")
+
+	_, err = formatRawNetState([]byte("not json"), true)
+	assert.Error(t, err)
+}
+
+func TestAffectsConnectivity(t *testing.T) {
+	assert.True(t, affectsConnectivity([]net.Change{{Kind: net.ChangeAdded, Path: "interfaces/eth0/ipv4/address/192.168.111.80/24"}}))
+	assert.True(t, affectsConnectivity([]net.Change{{Kind: net.ChangeRemoved, Path: "routes/::/0 via fd00::1 dev eth0"}}))
+	assert.True(t, affectsConnectivity([]net.Change{{Kind: net.ChangeModified, Path: "interfaces/eth0/state"}}))
+	assert.False(t, affectsConnectivity([]net.Change{{Kind: net.ChangeAdded, Path: "routes/10.0.0.0/8 via 192.168.111.2 dev eth0"}}))
+	assert.False(t, affectsConnectivity([]net.Change{{Kind: net.ChangeModified, Path: "interfaces/eth0/mtu"}}))
+}
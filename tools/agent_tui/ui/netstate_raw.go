@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+var (
+	colorRawKey     = newt.ColorBlue
+	colorRawString  = tcell.ColorDarkGreen
+	colorRawLiteral = tcell.ColorDarkMagenta
+
+	// a mapping key, optionally in a list item, followed by its value
+	yamlKeyRegexp  = regexp.MustCompile(`^(\s*(?:- )*)([^\s:#"'-][^:]*|"[^"]*"):(?: (.*))?$`)
+	yamlItemRegexp = regexp.MustCompile(`^(\s*(?:- )+)(.*)$`)
+	jsonKeyRegexp  = regexp.MustCompile(`^(\s*)("(?:[^"\\]|\\.)*"): (.*)$`)
+	jsonItemRegexp = regexp.MustCompile(`^(\s*)(.*)$`)
+	literalRegexp  = regexp.MustCompile(`^(-?[0-9.]+(e[+-]?[0-9]+)?|true|false|null|~)$`)
+)
+
+// showNetStatusRaw replaces the tree with the document reported by
+// nmstate, in YAML or JSON format
+func (u *UI) showNetStatusRaw() {
+	format := "YAML"
+	if u.netStatusRawJSON {
+		format = "JSON"
+	}
+
+	text := ""
+	raw, err := u.retrieveRawNetState()
+	if err == nil {
+		text, err = formatRawNetState(raw, u.netStatusRawJSON)
+	}
+	if err != nil {
+		u.logger.Infof("failed to retrieve the nmstate output: %v", err)
+		text = tview.Escape(fmt.Sprintf(NET_STATUS_RAW_FAILED, err))
+	}
+
+	u.netStatusRaw.SetTitle(fmt.Sprintf(NET_STATUS_RAW_TITLE, format, time.Now().Format("15:04:05")))
+	u.netStatusRaw.SetText(text).ScrollToBeginning()
+	u.netStatusViews.SwitchToPage(netStatusRawView)
+	u.setNetStatusHints()
+	u.app.SetFocus(u.netStatusRaw)
+}
+
+func (u *UI) showNetStatusTree() {
+	u.netStatusViews.SwitchToPage(netStatusTreeView)
+	u.setNetStatusHints()
+	u.app.SetFocus(u.netStatusTree)
+}
+
+// formatRawNetState returns the nmstate JSON document, as YAML unless
+// asJSON is true, with the keys and the values highlighted
+func formatRawNetState(raw []byte, asJSON bool) (string, error) {
+	if asJSON {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, raw, "", "  "); err != nil {
+			return "", err
+		}
+		return highlightLines(indented.String(), jsonKeyRegexp, jsonItemRegexp), nil
+	}
+
+	data, err := net.JSONToYAML(raw)
+	if err != nil {
+		return "", err
+	}
+	return highlightLines(string(data), yamlKeyRegexp, yamlItemRegexp), nil
+}
+
+// highlightLines colors the keys of the lines matching keyRegexp, and
+// the values of both them and the ones matching itemRegexp
+func highlightLines(text string, keyRegexp, itemRegexp *regexp.Regexp) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if m := keyRegexp.FindStringSubmatch(line); m != nil {
+			lines[i] = fmt.Sprintf("%s%s%s[-]:", tview.Escape(m[1]), colorTag(colorRawKey), tview.Escape(m[2]))
+			if m[3] != "" {
+				lines[i] += " " + highlightValue(m[3])
+			}
+		} else if m := itemRegexp.FindStringSubmatch(line); m != nil {
+			lines[i] = tview.Escape(m[1]) + highlightValue(m[2])
+		} else {
+			lines[i] = tview.Escape(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightValue colors a scalar value, keeping the JSON separator and
+// the brackets as they are
+func highlightValue(value string) string {
+	value, comma := strings.CutSuffix(value, ",")
+	separator := ""
+	if comma {
+		separator = ","
+	}
+
+	color := colorRawString
+	switch {
+	case value == "" || strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") ||
+		strings.HasPrefix(value, "}") || strings.HasPrefix(value, "]"):
+		return tview.Escape(value) + separator
+	case literalRegexp.MatchString(value):
+		color = colorRawLiteral
+	}
+	return fmt.Sprintf("%s%s[-]%s", colorTag(color), tview.Escape(value), separator)
+}
+
+func colorTag(color tcell.Color) string {
+	return fmt.Sprintf("[#%06x]", color.Hex())
+}
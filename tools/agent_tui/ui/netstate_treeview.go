@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
@@ -9,51 +11,218 @@ import (
 	"github.com/rivo/tview"
 )
 
-func getIfaceTree(iface net.Iface) *tview.TreeNode {
-	root := tview.NewTreeNode(fmt.Sprintf("%s (%s)", iface.Name, iface.Type)).SetColor(tcell.ColorBlack)
-	root.AddChild(tview.NewTreeNode(fmt.Sprintf("MTU: %d", iface.MTU)).SetColor(tcell.ColorBlack))
-	root.AddChild(tview.NewTreeNode(fmt.Sprintf("State: %s", iface.State)).SetColor(tcell.ColorBlack))
+var (
+	colorAdded    = tcell.ColorDarkGreen
+	colorRemoved  = tcell.ColorRed
+	colorModified = newt.ColorBlue
+	colorWarning  = tcell.ColorDarkOrange
+)
+
+// netChanges indexes the changes between two network states by path,
+// to highlight the corresponding nodes of the tree
+type netChanges map[string]net.Change
+
+func newNetChanges(changes []net.Change) netChanges {
+	c := netChanges{}
+	for _, change := range changes {
+		c[change.Path] = change
+	}
+	return c
+}
+
+// node returns the tree node of the item at path, highlighted when
+// the item has been added or modified
+func (c netChanges) node(path, text string) *tview.TreeNode {
+	// the path identifies the node across refreshes
+	change, ok := c[path]
+	switch {
+	case !ok:
+		return tview.NewTreeNode(text).SetColor(tcell.ColorBlack).SetReference(path)
+	case change.Kind == net.ChangeAdded:
+		return tview.NewTreeNode("+ " + text).SetColor(colorAdded).SetReference(path)
+	default:
+		return tview.NewTreeNode(fmt.Sprintf("~ %s (was %s)", text, change.Old)).SetColor(colorModified).SetReference(path)
+	}
+}
+
+// addRemoved adds to parent the items removed below prefix, sorted by
+// name. Removed items are not part of the current state, so only their
+// name is displayed.
+func (c netChanges) addRemoved(parent *tview.TreeNode, prefix string) {
+	for _, item := range c.removed(prefix) {
+		parent.AddChild(tview.NewTreeNode("- " + item).SetColor(colorRemoved))
+	}
+}
 
-	if len(iface.IPv4.Addresses) > 0 {
-		IPv4Node := tview.NewTreeNode("IPv4 Addresses").SetColor(tcell.ColorBlack)
-		for _, address := range iface.IPv4.Addresses {
-			IPv4Node.AddChild(tview.NewTreeNode(address.String()).SetColor(tcell.ColorBlack))
+func (c netChanges) removed(prefix string) []string {
+	items := []string{}
+	for path, change := range c {
+		if change.Kind == net.ChangeRemoved && strings.HasPrefix(path, prefix) {
+			items = append(items, strings.TrimPrefix(path, prefix))
 		}
-		root.AddChild(IPv4Node)
 	}
-	if len(iface.IPv6.Addresses) > 0 {
-		IPv6Node := tview.NewTreeNode("IPv6 Addresses").SetColor(tcell.ColorBlack)
-		for _, address := range iface.IPv6.Addresses {
-			IPv6Node.AddChild(tview.NewTreeNode(address.String()).SetColor(tcell.ColorBlack))
+	sort.Strings(items)
+	return items
+}
+
+func (c netChanges) has(path string) bool {
+	_, ok := c[path]
+	return ok
+}
+
+func getIfaceTree(iface net.Iface, changes netChanges) *tview.TreeNode {
+	path := net.IfacePath(iface.Name)
+	root := changes.node(path, fmt.Sprintf("%s (%s)", iface.Name, iface.Type))
+	root.AddChild(changes.node(path+"/mtu", fmt.Sprintf("MTU: %d", iface.MTU)))
+	root.AddChild(changes.node(path+"/state", fmt.Sprintf("State: %s", iface.State)))
+	if iface.MAC != "" || changes.has(path+"/mac-address") {
+		root.AddChild(changes.node(path+"/mac-address", fmt.Sprintf("MAC: %s", iface.MAC)))
+	}
+	if iface.Ethernet != nil && iface.Ethernet.Speed > 0 {
+		link := fmt.Sprintf("Link: %d Mb/s", iface.Ethernet.Speed)
+		if iface.Ethernet.Duplex != "" {
+			link += fmt.Sprintf(", %s duplex", iface.Ethernet.Duplex)
+		}
+		if iface.Ethernet.AutoNegotiation {
+			link += ", auto-negotiation"
 		}
-		root.AddChild(IPv6Node)
+		root.AddChild(tview.NewTreeNode(link).SetColor(tcell.ColorBlack))
+	}
+	if iface.Controller != "" || changes.has(path+"/controller") {
+		root.AddChild(changes.node(path+"/controller", fmt.Sprintf("Controller: %s", iface.Controller)))
+	}
+	if iface.LinkAggregation != nil {
+		bondPath := path + "/link-aggregation"
+		bondNode := tview.NewTreeNode("Bond").SetColor(tcell.ColorBlack)
+		bondNode.AddChild(changes.node(bondPath+"/mode", fmt.Sprintf("Mode: %s", iface.LinkAggregation.Mode)))
+		portsNode := tview.NewTreeNode("Ports").SetColor(tcell.ColorBlack)
+		for _, port := range iface.LinkAggregation.Ports {
+			portsNode.AddChild(changes.node(bondPath+"/port/"+port, port))
+		}
+		changes.addRemoved(portsNode, bondPath+"/port/")
+		bondNode.AddChild(portsNode)
+		root.AddChild(bondNode)
+	}
+	if iface.VLAN != nil {
+		vlanPath := path + "/vlan"
+		vlanNode := tview.NewTreeNode("VLAN").SetColor(tcell.ColorBlack)
+		vlanNode.AddChild(changes.node(vlanPath+"/base-iface", fmt.Sprintf("Base interface: %s", iface.VLAN.BaseIface)))
+		vlanNode.AddChild(changes.node(vlanPath+"/id", fmt.Sprintf("ID: %d", iface.VLAN.ID)))
+		root.AddChild(vlanNode)
+	}
+
+	for _, family := range []struct {
+		name, label string
+		config      net.IPConfig
+	}{
+		{"ipv4", "IPv4", iface.IPv4},
+		{"ipv6", "IPv6", iface.IPv6},
+	} {
+		familyPath := path + "/" + family.name
+		addressPath := familyPath + "/address/"
+		removed := changes.removed(addressPath)
+		dynamic := family.config.DHCP || family.config.Autoconf
+		if len(family.config.Addresses) == 0 && len(removed) == 0 && !dynamic && !changes.has(familyPath+"/enabled") &&
+			!changes.has(familyPath+"/dhcp") && !changes.has(familyPath+"/autoconf") {
+			continue
+		}
+
+		familyNode := tview.NewTreeNode(family.label + " Addresses").SetColor(tcell.ColorBlack)
+		// the IP settings are displayed when enabled or modified
+		for _, flag := range []struct {
+			name  string
+			label string
+			value bool
+		}{
+			{"enabled", "Enabled", family.config.Enabled},
+			{"dhcp", "DHCP", family.config.DHCP},
+			{"autoconf", "Autoconf", family.config.Autoconf},
+		} {
+			if changes.has(familyPath+"/"+flag.name) || (flag.name != "enabled" && flag.value) {
+				familyNode.AddChild(changes.node(familyPath+"/"+flag.name, fmt.Sprintf("%s: %v", flag.label, flag.value)))
+			}
+		}
+		// the settings taken from the DHCP server are reported only
+		// when DHCP or autoconf are enabled
+		for _, flag := range []struct {
+			label string
+			value *bool
+		}{
+			{"Auto DNS", family.config.AutoDNS},
+			{"Auto gateway", family.config.AutoGateway},
+			{"Auto routes", family.config.AutoRoutes},
+		} {
+			if dynamic && flag.value != nil {
+				familyNode.AddChild(tview.NewTreeNode(fmt.Sprintf("%s: %v", flag.label, *flag.value)).SetColor(tcell.ColorBlack))
+			}
+		}
+		for _, address := range family.config.Addresses {
+			familyNode.AddChild(changes.node(addressPath+address.String(), address.String()))
+		}
+		changes.addRemoved(familyNode, addressPath)
+		root.AddChild(familyNode)
 	}
 
 	return root
 }
 
-func getRouteTree(route net.Route) *tview.TreeNode {
+// getRouteTree returns the node of a route, highlighted when primary,
+// that is the default route of its family. The routes of the tables
+// other than the main one list the rules selecting them.
+func getRouteTree(route net.Route, primary bool, rules []net.RouteRule, changes netChanges) *tview.TreeNode {
 	var dest string
-	if net.IsIPv4DefaultRoute(route.Destination) || net.IsIPv6DefaultRoute(route.Destination) {
+	if route.IsDefault() {
 		dest = "default"
 	} else {
 		dest = route.Destination
 	}
+	if primary {
+		dest += fmt.Sprintf(" (primary %s)", route.Family())
+	}
 
-	root := tview.NewTreeNode(dest).SetColor(tcell.ColorBlack)
+	root := changes.node(net.RoutePath(route), dest)
+	if primary && !changes.has(net.RoutePath(route)) {
+		root.SetColor(tcell.ColorGreen)
+	}
 	root.AddChild(tview.NewTreeNode(fmt.Sprintf("Next hop address: %s", route.NextHopAddr)).SetColor(tcell.ColorBlack))
 	root.AddChild(tview.NewTreeNode(fmt.Sprintf("Next hop interface: %s", route.NextHopIface)).SetColor(tcell.ColorBlack))
+	root.AddChild(tview.NewTreeNode(fmt.Sprintf("Metric: %d", route.Metric)).SetColor(tcell.ColorBlack))
+	if !route.InMainTable() {
+		root.AddChild(tview.NewTreeNode(fmt.Sprintf("Table ID: %d", route.TableID)).SetColor(tcell.ColorBlack))
+		if len(rules) == 0 {
+			root.AddChild(tview.NewTreeNode("Not used by any route rule").SetColor(tcell.ColorBlack))
+		}
+		for _, rule := range rules {
+			root.AddChild(tview.NewTreeNode("Route rule: " + rule.String()).SetColor(tcell.ColorBlack))
+		}
+	}
 	return root
 }
 
+// sortRoutes returns the default routes first, by family and metric,
+// followed by the other routes
+func sortRoutes(netState net.NetState) []net.Route {
+	routes := []net.Route{}
+	for _, family := range net.Families {
+		routes = append(routes, netState.DefaultRoutes(family)...)
+	}
+	for _, route := range netState.Routes.Running {
+		if !route.IsDefault() {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
 // ModalTreeView creates a centered modal dialog containing a network state tree view.
+// The changes, if not nil, are highlighted in the tree.
 // The doneFunc callback specifies where to navigate after the user exits the tree view.
-func (u *UI) ModalTreeView(netState net.NetState, doneFunc func()) (tview.Primitive, error) {
+func (u *UI) ModalTreeView(netState net.NetState, changes []net.Change, doneFunc func()) (tview.Primitive, error) {
 	if u.pages == nil {
 		return nil, fmt.Errorf("can't make a NetState treeView page for nil pages")
 	}
 
-	treeView, err := u.TreeView(netState, doneFunc)
+	treeView, err := u.TreeView(netState, changes, doneFunc)
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +240,14 @@ func (u *UI) ModalTreeView(netState net.NetState, doneFunc func()) (tview.Primit
 // The doneFunc callback is called when the user exits the tree view (via 'q' key or ESC).
 // This allows the caller to specify where to navigate after exiting, ensuring the user
 // returns to the correct page (e.g., checks page vs rendezvous IP page).
-func (u *UI) TreeView(netState net.NetState, doneFunc func()) (*tview.TreeView, error) {
+// When changes is not nil, the added and modified items are highlighted and the
+// removed ones are displayed too.
+func (u *UI) TreeView(netState net.NetState, changes []net.Change, doneFunc func()) (*tview.TreeView, error) {
 	if u.pages == nil {
 		return nil, fmt.Errorf("can't make a NetState treeView page for nil pages")
 	}
 
-	root := tview.NewTreeNode(fmt.Sprintf("[black::b]%s", netState.Hostname.Running))
+	root := netStateTree(netState, changes, u.lintNetState(netState))
 	tree := tview.NewTreeView().
 		SetRoot(root).
 		SetCurrentNode(root).SetDoneFunc(
@@ -86,7 +257,11 @@ func (u *UI) TreeView(netState net.NetState, doneFunc func()) (*tview.TreeView,
 			doneFunc()
 		})
 
-	tree.SetTitle("Network Status").
+	title := "Network Status"
+	if changes != nil {
+		title = fmt.Sprintf("Network Status - %d changes", len(changes))
+	}
+	tree.SetTitle(title).
 		SetBackgroundColor(newt.ColorGray).
 		SetBorder(true).
 		SetBorderColor(tcell.ColorBlack).
@@ -102,56 +277,181 @@ func (u *UI) TreeView(netState net.NetState, doneFunc func()) (*tview.TreeView,
 			return event
 		})
 
+	return tree, nil
+}
+
+// netStateTree returns the root node of the network state tree, with
+// the changes highlighted and the findings of the linter displayed
+// below the items they refer to
+func netStateTree(netState net.NetState, changes []net.Change, findings []net.Finding) *tview.TreeNode {
+	root := tview.NewTreeNode(fmt.Sprintf("[black::b]%s", netState.Hostname.Running))
+	marks := newNetChanges(changes)
+	if change, ok := marks["hostname"]; ok {
+		root.SetText(fmt.Sprintf("[black::b]%s[-::-] (was %s)", netState.Hostname.Running, change.Old))
+	}
+
 	interfaces := tview.NewTreeNode("Interfaces").SetColor(tcell.ColorBlack)
 	root.AddChild(interfaces)
 
-	defaultIface, err := netState.GetDefaultNextHopIface()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate network state view: %w", err)
-	}
-	if defaultIface != nil {
-		interfaces.AddChild(getIfaceTree(*defaultIface).SetColor(tcell.ColorGreen))
+	// the interfaces of the primary default routes are displayed first
+	primaryIfaces := netState.PrimaryIfaces()
+	for _, iface := range netState.Ifaces {
+		if families, ok := primaryIfaces[iface.Name]; ok {
+			node := getIfaceTree(iface, marks)
+			labels := []string{}
+			for _, family := range families {
+				labels = append(labels, family.String())
+			}
+			node.AddChild(tview.NewTreeNode("Primary interface: " + strings.Join(labels, ", ")).SetColor(tcell.ColorGreen))
+			if !marks.has(net.IfacePath(iface.Name)) {
+				node.SetColor(tcell.ColorGreen)
+			}
+			interfaces.AddChild(node)
+		}
 	}
 	for _, iface := range netState.Ifaces {
-		if defaultIface != nil && defaultIface.Name == iface.Name {
-			continue // Skip defaultRouteIface, since we always display it first
+		if _, ok := primaryIfaces[iface.Name]; !ok {
+			interfaces.AddChild(getIfaceTree(iface, marks))
+		}
+	}
+	for _, name := range marks.removed(net.IfacePath("")) {
+		// only the interfaces themselves, not their removed addresses
+		if !strings.Contains(name, "/") {
+			interfaces.AddChild(tview.NewTreeNode("- " + name).SetColor(colorRemoved))
 		}
-		interfaces.AddChild(getIfaceTree(iface))
 	}
 
-	if len(netState.Routes.Running) > 0 {
-		routes := tview.NewTreeNode("Routes").SetColor(tcell.ColorBlack)
+	removedRoutes := marks.removed("routes/")
+	if len(netState.Routes.Running) > 0 || len(removedRoutes) > 0 {
+		routes := tview.NewTreeNode("Routes").SetColor(tcell.ColorBlack).SetReference("routes")
 		root.AddChild(routes)
 
-		for _, route := range netState.Routes.Running {
-			routes.AddChild(getRouteTree(route))
+		primaryRoutes := map[net.Route]bool{}
+		for _, family := range net.Families {
+			if route := netState.PrimaryDefaultRoute(family); route != nil {
+				primaryRoutes[*route] = true
+			}
+		}
+		for _, route := range sortRoutes(netState) {
+			routes.AddChild(getRouteTree(route, primaryRoutes[route], netState.RulesForTable(route.TableID), marks))
+		}
+		marks.addRemoved(routes, "routes/")
+	}
+
+	removedRules := marks.removed("route-rules/")
+	if len(netState.Rules()) > 0 || len(removedRules) > 0 {
+		rules := tview.NewTreeNode("Route rules").SetColor(tcell.ColorBlack)
+		root.AddChild(rules)
+
+		for _, rule := range netState.Rules() {
+			rules.AddChild(marks.node("route-rules/"+rule.String(), rule.String()))
 		}
+		marks.addRemoved(rules, "route-rules/")
 	}
 
 	var dns *tview.TreeNode
-	if len(netState.DNS.Running.Servers) > 0 {
-		dns = tview.NewTreeNode("DNS").SetColor(tcell.ColorBlack)
+	removedServers := marks.removed("dns/servers/")
+	if len(netState.DNS.Running.Servers) > 0 || len(removedServers) > 0 {
+		dns = tview.NewTreeNode("DNS").SetColor(tcell.ColorBlack).SetReference("dns")
 		root.AddChild(dns)
 
 		servers := tview.NewTreeNode("Servers").SetColor(tcell.ColorBlack)
 		dns.AddChild(servers)
 		for _, server := range netState.DNS.Running.Servers {
-			servers.AddChild(tview.NewTreeNode(server).SetColor(tcell.ColorBlack))
+			servers.AddChild(marks.node("dns/servers/"+server, server))
 		}
+		marks.addRemoved(servers, "dns/servers/")
 	}
 
-	if len(netState.DNS.Running.SearchDomains) > 0 {
+	removedSearch := marks.removed("dns/search/")
+	if len(netState.DNS.Running.SearchDomains) > 0 || len(removedSearch) > 0 {
 		if dns == nil {
-			dns = tview.NewTreeNode("DNS").SetColor(tcell.ColorBlack)
+			dns = tview.NewTreeNode("DNS").SetColor(tcell.ColorBlack).SetReference("dns")
 			root.AddChild(dns)
 		}
 		searchDomains := tview.NewTreeNode("Search domains").SetColor(tcell.ColorBlack)
 		dns.AddChild(searchDomains)
 
 		for _, search := range netState.DNS.Running.SearchDomains {
-			searchDomains.AddChild(tview.NewTreeNode(search).SetColor(tcell.ColorBlack))
+			searchDomains.AddChild(marks.node("dns/search/"+search, search))
 		}
+		marks.addRemoved(searchDomains, "dns/search/")
 	}
 
-	return tree, nil
+	addFindings(root, findings)
+	return root
+}
+
+// addFindings adds each finding below the node of the item it refers
+// to, or of its closest ancestor, falling back to the root
+func addFindings(root *tview.TreeNode, findings []net.Finding) {
+	nodes := map[string]*tview.TreeNode{}
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if path, ok := node.GetReference().(string); ok {
+			nodes[path] = node
+		}
+		return true
+	})
+
+	for _, finding := range findings {
+		parent := root
+		for path := finding.Path; path != ""; {
+			if node, ok := nodes[path]; ok {
+				parent = node
+				break
+			}
+			i := strings.LastIndex(path, "/")
+			if i < 0 {
+				break
+			}
+			path = path[:i]
+		}
+		parent.AddChild(findingNode(finding))
+	}
+}
+
+// addLLDPNeighbors adds below the interfaces the switch ports they are
+// connected to, as advertised with LLDP. The status is displayed below
+// the interfaces without neighbor.
+func addLLDPNeighbors(root *tview.TreeNode, ifaces []string, neighbors map[string][]net.LLDPNeighbor, status string, statusColor tcell.Color) {
+	nodes := map[string]*tview.TreeNode{}
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if path, ok := node.GetReference().(string); ok {
+			nodes[path] = node
+		}
+		return true
+	})
+
+	for _, name := range ifaces {
+		path := net.IfacePath(name)
+		parent, ok := nodes[path]
+		if !ok {
+			// the interface is hidden
+			continue
+		}
+		if len(neighbors[name]) == 0 {
+			parent.AddChild(tview.NewTreeNode("LLDP: " + status).SetColor(statusColor).SetReference(path + "/lldp"))
+			continue
+		}
+		for _, neighbor := range neighbors[name] {
+			node := tview.NewTreeNode(fmt.Sprintf("LLDP: switch %s, port %s", neighbor.Switch(), neighbor.Port())).
+				SetColor(tcell.ColorBlack).
+				SetReference(fmt.Sprintf("%s/lldp/%s/%s", path, neighbor.ChassisID, neighbor.PortID))
+			node.AddChild(tview.NewTreeNode("Chassis ID: " + neighbor.ChassisID).SetColor(tcell.ColorBlack))
+			if vlans := neighbor.VLANList(); vlans != "" {
+				node.AddChild(tview.NewTreeNode("VLANs: " + vlans).SetColor(tcell.ColorBlack))
+			}
+			if neighbor.MTU != 0 {
+				node.AddChild(tview.NewTreeNode(fmt.Sprintf("MTU: %d", neighbor.MTU)).SetColor(tcell.ColorBlack))
+			}
+			parent.AddChild(node)
+		}
+	}
+}
+
+func findingNode(finding net.Finding) *tview.TreeNode {
+	if finding.Severity == net.SeverityError {
+		return tview.NewTreeNode("✖ " + finding.Message).SetColor(newt.ColorRed)
+	}
+	return tview.NewTreeNode("! " + finding.Message).SetColor(colorWarning)
 }
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// nodeTexts returns the texts of the children of node
+func nodeTexts(node *tview.TreeNode) []string {
+	texts := []string{}
+	for _, child := range node.GetChildren() {
+		texts = append(texts, child.GetText())
+	}
+	return texts
+}
+
+func findNode(node *tview.TreeNode, text string) *tview.TreeNode {
+	for _, child := range node.GetChildren() {
+		if child.GetText() == text {
+			return child
+		}
+	}
+	return nil
+}
+
+func TestTreeViewChanges(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+
+	before := testNetState(t)
+	after := testNetState(t)
+	after.Ifaces[1].MTU = 9000
+	after.Ifaces = after.Ifaces[:2]
+	after.DNS.Running.Servers = []string{"10.0.0.1"}
+	changes := net.Diff(before, after)
+
+	tree, err := ui.TreeView(after, changes, func() {})
+	assert.NoError(t, err)
+	assert.Equal(t, "Network Status - 4 changes", tree.GetTitle())
+
+	root := tree.GetRoot()
+	interfaces := findNode(root, "Interfaces")
+	assert.Equal(t, []string{"eth0 (ethernet)", "lo (loopback)", "- eth1"}, nodeTexts(interfaces))
+	eth0 := findNode(interfaces, "eth0 (ethernet)")
+	assert.Contains(t, nodeTexts(eth0), "~ MTU: 9000 (was 1500)")
+	assert.Equal(t, colorModified, findNode(eth0, "~ MTU: 9000 (was 1500)").GetColor())
+
+	servers := findNode(findNode(root, "DNS"), "Servers")
+	assert.Equal(t, []string{"+ 10.0.0.1", "- 192.168.111.1"}, nodeTexts(servers))
+	assert.Equal(t, colorAdded, findNode(servers, "+ 10.0.0.1").GetColor())
+	assert.Equal(t, colorRemoved, findNode(servers, "- 192.168.111.1").GetColor())
+
+	// without changes nothing is highlighted
+	tree, err = ui.TreeView(after, nil, func() {})
+	assert.NoError(t, err)
+	assert.Equal(t, "Network Status", tree.GetTitle())
+}
+
+func TestTreeViewDetails(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+	autoDNS := true
+
+	before := net.NetState{
+		Routes: net.RoutesRC{Running: []net.Route{
+			{Destination: "0.0.0.0/0", NextHopIface: "bond0.100", NextHopAddr: "192.168.100.1", Metric: 400, TableID: 254},
+			{Destination: "10.0.0.0/8", NextHopIface: "bond0.100", NextHopAddr: "192.168.100.2", Metric: 100, TableID: 100},
+		}},
+		Ifaces: []net.Iface{
+			{Name: "eth0", Type: "ethernet", State: "up", MAC: "52:54:00:aa:bb:01", Controller: "bond0",
+				Ethernet: &net.Ethernet{AutoNegotiation: true, Speed: 10000, Duplex: "full"}},
+			{Name: "eth1", Type: "ethernet", State: "up", MAC: "52:54:00:aa:bb:02", Controller: "bond0"},
+			{Name: "bond0", Type: "bond", State: "up",
+				LinkAggregation: &net.LinkAggregation{Mode: "802.3ad", Ports: []string{"eth0", "eth1"}}},
+			{Name: "bond0.100", Type: "vlan", State: "up", VLAN: &net.VLAN{BaseIface: "bond0", ID: 100},
+				IPv4: net.IPConfig{Enabled: true, DHCP: true, AutoDNS: &autoDNS}},
+		},
+	}
+	after := before
+	after.Ifaces = append([]net.Iface{}, before.Ifaces...)
+	after.Ifaces[1].Controller = ""
+	after.Ifaces[2].LinkAggregation = &net.LinkAggregation{Mode: "active-backup", Ports: []string{"eth0"}}
+
+	tree, err := ui.TreeView(after, net.Diff(before, after), func() {})
+	assert.NoError(t, err)
+	interfaces := findNode(tree.GetRoot(), "Interfaces")
+
+	eth0 := findNode(interfaces, "eth0 (ethernet)")
+	assert.Contains(t, nodeTexts(eth0), "MAC: 52:54:00:aa:bb:01")
+	assert.Contains(t, nodeTexts(eth0), "Link: 10000 Mb/s, full duplex, auto-negotiation")
+	assert.Contains(t, nodeTexts(eth0), "Controller: bond0")
+	assert.Contains(t, nodeTexts(findNode(interfaces, "eth1 (ethernet)")), "~ Controller:  (was bond0)")
+
+	bond := findNode(findNode(interfaces, "bond0 (bond)"), "Bond")
+	assert.Equal(t, []string{"~ Mode: active-backup (was 802.3ad)", "Ports"}, nodeTexts(bond))
+	assert.Equal(t, []string{"eth0", "- eth1"}, nodeTexts(findNode(bond, "Ports")))
+
+	vlan := findNode(interfaces, "bond0.100 (vlan)")
+	assert.Equal(t, []string{"Base interface: bond0", "ID: 100"}, nodeTexts(findNode(vlan, "VLAN")))
+	assert.Equal(t, []string{"DHCP: true", "Auto DNS: true"}, nodeTexts(findNode(vlan, "IPv4 Addresses")))
+
+	routes := findNode(tree.GetRoot(), "Routes")
+	assert.Equal(t, []string{"Next hop address: 192.168.100.1", "Next hop interface: bond0.100", "Metric: 400"},
+		nodeTexts(routes.GetChildren()[0]))
+	assert.Contains(t, nodeTexts(routes.GetChildren()[1]), "Table ID: 100")
+}
+
+func TestTreeViewMultipleDefaultRoutes(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+
+	netState := testNetState(t)
+	netState.Routes.Running = []net.Route{
+		{Destination: "::/0", NextHopIface: "eth1", NextHopAddr: "fd00::1", Metric: 1024},
+		{Destination: "0.0.0.0/0", NextHopIface: "eth1", NextHopAddr: "10.0.0.1", Metric: 200},
+		{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.1", Metric: 100},
+		{Destination: "0.0.0.0/0", NextHopIface: "eth0", NextHopAddr: "192.168.111.254", Metric: 100, TableID: 100},
+	}
+	netState.RouteRules = &net.RouteRules{Config: []net.RouteRule{{IPFrom: "192.168.111.0/24", Priority: 100, RouteTable: 100}}}
+
+	tree, err := ui.TreeView(netState, nil, func() {})
+	assert.NoError(t, err)
+
+	interfaces := findNode(tree.GetRoot(), "Interfaces")
+	assert.Equal(t, []string{"eth0 (ethernet)", "eth1 (ethernet)", "lo (loopback)"}, nodeTexts(interfaces))
+	assert.Contains(t, nodeTexts(findNode(interfaces, "eth0 (ethernet)")), "Primary interface: IPv4")
+	assert.Contains(t, nodeTexts(findNode(interfaces, "eth1 (ethernet)")), "Primary interface: IPv6")
+
+	routes := findNode(tree.GetRoot(), "Routes")
+	assert.Equal(t, []string{"default (primary IPv4)", "default", "default", "default (primary IPv6)"}, nodeTexts(routes))
+	assert.Equal(t, tcell.ColorGreen, routes.GetChildren()[0].GetColor())
+	assert.Contains(t, nodeTexts(routes.GetChildren()[2]), "Route rule: priority 100 from 192.168.111.0/24 lookup 100")
+	assert.Equal(t, []string{"priority 100 from 192.168.111.0/24 lookup 100"}, nodeTexts(findNode(tree.GetRoot(), "Route rules")))
+}
+
+func TestTreeViewFindings(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+	ui.lintNetState = func(net.NetState) []net.Finding {
+		return []net.Finding{
+			{Severity: net.SeverityError, Path: "dns/servers/192.168.111.1", Message: "DNS server 192.168.111.1 is not reachable by any route"},
+			{Severity: net.SeverityWarning, Path: "interfaces/eth1/state", Message: "Interface eth1 is up but has no carrier"},
+			{Severity: net.SeverityWarning, Path: "interfaces/eth0/ipv4/address/10.0.0.1/24", Message: "Address 10.0.0.1 is also assigned to eth1"},
+			{Severity: net.SeverityWarning, Path: "hostname", Message: `Hostname "localhost" is not unique`},
+		}
+	}
+
+	tree, err := ui.TreeView(testNetState(t), nil, func() {})
+	assert.NoError(t, err)
+	root := tree.GetRoot()
+
+	servers := findNode(findNode(root, "DNS"), "Servers")
+	server := findNode(servers, "192.168.111.1")
+	assert.Equal(t, []string{"✖ DNS server 192.168.111.1 is not reachable by any route"}, nodeTexts(server))
+	assert.Equal(t, newt.ColorRed, server.GetChildren()[0].GetColor())
+
+	eth1 := findNode(findNode(root, "Interfaces"), "eth1 (ethernet)")
+	state := eth1.GetChildren()[1]
+	assert.Equal(t, []string{"! Interface eth1 is up but has no carrier"}, nodeTexts(state))
+	assert.Equal(t, colorWarning, state.GetChildren()[0].GetColor())
+
+	// the findings without a node are displayed below the closest ancestor
+	eth0 := findNode(findNode(root, "Interfaces"), "eth0 (ethernet)")
+	assert.Contains(t, nodeTexts(eth0), "! Address 10.0.0.1 is also assigned to eth1")
+	assert.Contains(t, nodeTexts(root), `! Hostname "localhost" is not unique`)
+}
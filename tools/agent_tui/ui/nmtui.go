@@ -1,62 +1,154 @@
 package ui
 
 import (
-	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
+	"time"
 
-	"github.com/nmstate/nmstate/rust/src/go/nmstate/v2"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
 	"github.com/rivo/tview"
 )
 
-func (u *UI) ShowNMTUI(doneFunc func()) error {
+const (
+	NMTUI_RUN_BUTTON string = "<Run nmtui>"
+
+	NMTUI_PREPARING_TEXT     = "Verifying the connectivity before running nmtui..."
+	NMTUI_NO_CHECKPOINT_TEXT = "The previous network configuration could not be saved, so it will not be restored " +
+		"if the changes made with nmtui break the connectivity:\n\n%v\n\nRun nmtui anyway?"
+	NMTUI_VERIFYING_TEXT = "Verifying the connectivity after the changes made with nmtui..."
+	NMTUI_FAILED_TEXT    = "nmtui failed:\n\n%v\n\n"
+
+	// Time left to the user in nmtui before the checkpoint is rolled
+	// back automatically by nmstate
+	nmtuiCheckpointTimeout = 10 * time.Minute
+)
+
+func execNMTUI() error {
+	cmd := exec.Command("nmtui")
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = os.Stdout
+	return cmd.Run()
+}
+
+// runNMTUI suspends the UI while nmtui runs, and returns the network
+// state afterwards with the changes made by nmtui. The changes are nil
+// when the state before running nmtui couldn't be retrieved.
+func (u *UI) runNMTUI() (net.NetState, []net.Change, error) {
 	u.nmtuiActive.Store(true)
 	defer u.nmtuiActive.Store(false)
 
+	// the state before running nmtui is used to display the changes,
+	// and it's not required to display the new state
+	before, beforeErr := u.retrieveNetState()
+	if beforeErr != nil {
+		u.logger.Infof("failed to retrieve the network state before running nmtui: %v", beforeErr)
+	}
+
 	var nmtuiErr error
 	u.app.Suspend(func() {
-		cmd := exec.Command("nmtui")
-		cmd.Stdin = os.Stdin
-		cmd.Stderr = os.Stderr
-		cmd.Stdout = os.Stdout
-		nmtuiErr = cmd.Run()
+		nmtuiErr = u.execNMTUI()
 	})
 	if nmtuiErr != nil {
-		return nmtuiErr
+		return net.NetState{}, nil, nmtuiErr
 	}
 
-	nm := nmstate.New()
-	state, err := nm.RetrieveNetState()
+	netState, err := u.retrieveNetState()
 	if err != nil {
-		return err
+		return net.NetState{}, nil, err
 	}
 
-	var netState net.NetState
-	if err := json.Unmarshal([]byte(state), &netState); err != nil {
+	var changes []net.Change
+	if beforeErr == nil {
+		changes = net.Diff(before, netState)
+		u.logger.Infof("nmtui made %d network changes", len(changes))
+		for _, change := range changes {
+			u.logger.Infof("network change: %s", change)
+		}
+	}
+	return netState, changes, nil
+}
+
+// ShowNMTUI runs nmtui, then displays the network state with the
+// changes made. The changes are not verified, see
+// showNMTUIWithErrorDialog.
+func (u *UI) ShowNMTUI(doneFunc func()) error {
+	netState, changes, err := u.runNMTUI()
+	if err != nil {
 		return err
 	}
+	return u.showNMTUIChanges(netState, changes, doneFunc)
+}
 
-	netStatePage, err := u.ModalTreeView(netState, doneFunc)
+func (u *UI) showNMTUIChanges(netState net.NetState, changes []net.Change, doneFunc func()) error {
+	netStatePage, err := u.ModalTreeView(netState, changes, doneFunc)
 	if err != nil {
 		return err
 	}
 	u.pages.AddPage("netstate", netStatePage, true, true)
-
 	return nil
 }
 
+func (u *UI) showNMTUIError(err error, doneFunc func()) {
+	u.logger.Infof("error from ShowNMTUI: %v", err)
+	errorDialog := tview.NewModal().
+		SetBackgroundColor(newt.ColorGray).
+		SetText(err.Error()).
+		AddButtons([]string{"Ok"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			doneFunc()
+		})
+	u.pages.AddPage("error", errorDialog, false, true)
+}
+
+// showNMTUIWithErrorDialog runs nmtui in a nmstate checkpoint, like the
+// changes of the network editor: once nmtui exits, the checkpoint is
+// rolled back when the connectivity got worse, and otherwise the user
+// has to confirm the new settings before the countdown expires. When
+// the checkpoint can't be created, nmtui is run only if the user
+// confirms it.
 func (u *UI) showNMTUIWithErrorDialog(doneFunc func()) {
-	if err := u.ShowNMTUI(doneFunc); err != nil {
-		u.logger.Infof("error from ShowNMTUI: %v", err)
-		errorDialog := tview.NewModal().
-			SetBackgroundColor(newt.ColorGray).
-			SetText(err.Error()).
-			AddButtons([]string{"Ok"}).
-			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-				doneFunc()
-			})
-		u.pages.AddPage("error", errorDialog, false, true)
-	}
+	u.showNetModal(NMTUI_PREPARING_TEXT, []string{}, nil)
+	go func() {
+		before := u.runChecksOnce()
+		// the checkpoint must outlive the nmtui session, the checks
+		// and the prompt
+		err := u.createCheckpoint(nmtuiCheckpointTimeout + checkpointChecksTimeout + u.promptTimeout)
+		u.app.QueueUpdateDraw(func() {
+			if err != nil {
+				u.logger.Infof("failed to create a checkpoint before running nmtui: %v", err)
+				u.showNetModal(fmt.Sprintf(NMTUI_NO_CHECKPOINT_TEXT, err), []string{NMTUI_RUN_BUTTON, BACK_BUTTON}, func(label string) {
+					if label != NMTUI_RUN_BUTTON {
+						doneFunc()
+						return
+					}
+					if err := u.ShowNMTUI(doneFunc); err != nil {
+						u.showNMTUIError(err, doneFunc)
+					}
+				})
+				return
+			}
+
+			netState, changes, err := u.runNMTUI()
+			if err != nil {
+				u.logger.Infof("nmtui failed: %v, rolling back", err)
+				go u.finishCheckpoint(false, fmt.Sprintf(NMTUI_FAILED_TEXT, err), doneFunc, doneFunc)
+				return
+			}
+			showChanges := func() {
+				if err := u.showNMTUIChanges(netState, changes, doneFunc); err != nil {
+					u.showNMTUIError(err, doneFunc)
+				}
+			}
+			if changes != nil && len(changes) == 0 {
+				// nothing to verify
+				go u.finishCheckpoint(true, "", doneFunc, showChanges)
+				return
+			}
+			u.showNetModal(NMTUI_VERIFYING_TEXT, []string{}, nil)
+			go u.verifyCheckpoint(before, nil, doneFunc, showChanges)
+		})
+	}()
 }
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNMTUICheckpoint(t *testing.T) {
+	cases := []struct {
+		name       string
+		afterCheck bool
+		committed  bool
+	}{
+		{name: "confirmed", afterCheck: true, committed: true},
+		{name: "connectivity worse", afterCheck: false, committed: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui, screen := newSimulatedUI(t, checks.Config{}, nil)
+
+			netState := testNetState(t)
+			ui.retrieveNetState = func() (net.NetState, error) {
+				return netState, nil
+			}
+			checkpoint := make(chan time.Duration, 1)
+			ui.createCheckpoint = func(timeout time.Duration) error {
+				checkpoint <- timeout
+				return nil
+			}
+			nmtuiRun := false
+			ui.execNMTUI = func() error {
+				// the checkpoint is created before nmtui is run
+				assert.Len(t, checkpoint, 1)
+				nmtuiRun = true
+				netState = testNetState(t)
+				netState.Ifaces[1].MTU = 9000
+				return nil
+			}
+			ui.SetRunChecks(func() []checks.CheckResult {
+				return []checks.CheckResult{{Type: checks.CheckTypeReleaseImageHostPing, Success: !nmtuiRun || tc.afterCheck}}
+			})
+			finished := make(chan string, 1)
+			ui.commitCheckpoint = func() error {
+				finished <- "committed"
+				return nil
+			}
+			ui.rollbackCheckpoint = func() error {
+				finished <- "rolled back"
+				return nil
+			}
+			done := make(chan bool, 1)
+
+			startUI(t, ui)
+
+			ui.app.QueueUpdateDraw(func() {
+				ui.showNMTUIWithErrorDialog(func() { done <- true })
+			})
+			if tc.committed {
+				// the progress modals have no buttons
+				assert.Eventually(t, func() bool {
+					prompted := false
+					ui.app.QueueUpdate(func() {
+						prompted = ui.netModalDone != nil
+					})
+					return prompted
+				}, 5*time.Second, 10*time.Millisecond)
+				// <Keep> is focused
+				screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+			}
+
+			select {
+			case outcome := <-finished:
+				if tc.committed {
+					assert.Equal(t, "committed", outcome)
+				} else {
+					assert.Equal(t, "rolled back", outcome)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("the checkpoint was neither committed nor rolled back")
+			}
+			assert.Equal(t, nmtuiCheckpointTimeout+checkpointChecksTimeout+ui.promptTimeout, <-checkpoint)
+
+			// the outcome is acknowledged
+			assert.Eventually(t, func() bool {
+				page, _ := ui.pages.GetFrontPage()
+				return page == PAGE_NET_MODAL && len(finished) == 0
+			}, 5*time.Second, 10*time.Millisecond)
+			screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+			if tc.committed {
+				// the changes made by nmtui are displayed
+				waitForPage(t, ui, "netstate")
+				return
+			}
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the rollback was not acknowledged")
+			}
+		})
+	}
+}
@@ -2,11 +2,10 @@ package ui
 
 import (
 	"fmt"
-	"net"
-	"os/exec"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
 	"github.com/rivo/tview"
 )
 
@@ -16,7 +15,7 @@ const (
 	FIELD_ENTER_RENDEZVOUS_IP   = "Rendezvous IP"
 	SAVE_RENDEZVOUS_IP_BUTTON   = "<Save rendezvous IP>"
 	SELECT_IP_ADDRESS_BUTTON    = "<This is the rendezvous node>"
-	RENDEZVOUS_HOST_ENV_PATH    = "/etc/assisted/rendezvous-host.env"
+	RENDEZVOUS_HOST_ENV_PATH    = rendezvous.HostEnvPath
 )
 
 func (u *UI) createTextFlex(text string) *tview.Flex {
@@ -46,7 +45,7 @@ func (u *UI) createRendezvousIPPage() {
 	u.rendezvousIPForm.AddButton(SAVE_RENDEZVOUS_IP_BUTTON, func() {
 		// save rendezvous IP address and switch to checks page
 		ipAddress := u.rendezvousIPForm.GetFormItemByLabel(FIELD_ENTER_RENDEZVOUS_IP).(*tview.InputField).GetText()
-		validationError := validateIP(ipAddress)
+		validationError := rendezvous.ValidateIP(ipAddress)
 		if validationError != "" {
 			if ipAddress == "" {
 				ipAddress = "<blank>"
@@ -95,9 +94,7 @@ func (u *UI) createRendezvousIPPage() {
 	u.configureNetworkForm.SetBorder(false)
 	u.configureNetworkForm.SetButtonsAlign(tview.AlignRight)
 	u.configureNetworkForm.AddButton(RENDEZVOUS_CONFIGURE_NETWORK_BUTTON, func() {
-		u.showNMTUIWithErrorDialog(func() {
-			u.setFocusToRendezvousIP()
-		})
+		u.showNetEditor(u.setFocusToRendezvousIP)
 	})
 	u.configureNetworkForm.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
 		Foreground(newt.ColorGray))
@@ -158,17 +155,9 @@ func (u *UI) createRendezvousIPPage() {
 	u.pages.AddPage(PAGE_RENDEZVOUS_IP, flex, true, false)
 }
 
-func validateIP(ipAddress string) string {
-	if net.ParseIP(ipAddress) == nil {
-		return fmt.Sprintf("%s is not a valid IP address", ipAddress)
-	}
-	return ""
-}
-
 func (u *UI) checkConnectivity(ipAddress string) bool {
-	url := fmt.Sprintf("http://%s:8090/api/assisted-install/v2", ipAddress)
 	connectivtyFailedText := ""
-	stdout, connectivityErr := exec.Command("curl", "-m 1", url).CombinedOutput()
+	stdout, connectivityErr := rendezvous.CheckConnectivity(ipAddress, u.connectivityTimeout)
 	if connectivityErr != nil {
 		connectivtyFailedText = CONNECTIVITY_CHECK_FAIL_TEXT_FORMAT
 		u.logger.Infof("Connectivity check failed: %s: %s", connectivtyFailedText, stdout)
@@ -180,6 +169,17 @@ func (u *UI) checkConnectivity(ipAddress string) bool {
 }
 
 func (u *UI) displayModalAfterConnectivityCheck(ipAddress string) {
+	// a rendezvous IP assigned to several hosts would make the
+	// connectivity check succeed anyway
+	if duplicate := u.duplicateRendezvousIP(ipAddress, false); duplicate != nil {
+		u.app.QueueUpdateDraw(func() {
+			u.showRendezvousIPDuplicateModal(*duplicate, false, func() {
+				u.saveRendezvousIPAndShowModalIfError(ipAddress, true)
+			}, u.setFocusToRendezvousIP)
+		})
+		return
+	}
+
 	haveConnectivity := u.checkConnectivity(ipAddress)
 	u.app.QueueUpdateDraw(func() {
 		if !haveConnectivity {
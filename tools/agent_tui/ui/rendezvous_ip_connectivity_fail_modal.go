@@ -22,8 +22,7 @@ func (u *UI) showRendezvousIPConnectivityFailModal(ipAddress string, focusForBac
 			focusForBackButton()
 		}
 		if buttonLabel == RENDEZVOUS_CONFIGURE_NETWORK_BUTTON {
-			u.showNMTUIWithErrorDialog(u.setFocusToRendezvousIP)
-			u.pages.SwitchToPage(PAGE_RENDEZVOUS_IP_CONNECTIVITY_FAIL)
+			u.showNetEditor(u.setFocusToRendezvousIP)
 		}
 
 	})
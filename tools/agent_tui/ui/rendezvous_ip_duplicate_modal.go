@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_RENDEZVOUS_IP_DUPLICATE string = "rendezvousIPDuplicatePage"
+	SAVE_ANYWAY_BUTTON           string = "<Save anyway>"
+
+	CHECKING_DUPLICATE_TEXT_FORMAT      string = "Checking that no other host uses %s"
+	DUPLICATE_LOCAL_IP_TEXT_FORMAT      string = "Warning: %s is also used by another host (%s). The installation will fail unless the address is changed."
+	DUPLICATE_DAD_IP_TEXT_FORMAT        string = "Warning: %s failed the IPv6 duplicate address detection on %s, another host uses it. The installation will fail unless the address is changed."
+	DUPLICATE_RENDEZVOUS_IP_TEXT_FORMAT string = "Warning: several hosts answer for %s (%s). The rendezvous IP may be assigned to more than one host."
+)
+
+// duplicateRendezvousIP looks for other hosts using the rendezvous IP.
+// The probes that can't be run are only logged, since they must not
+// prevent saving the address.
+func (u *UI) duplicateRendezvousIP(ipAddress string, local bool) *checks.DuplicateIP {
+	ns, err := u.retrieveNetState()
+	if err != nil {
+		u.logger.Warnf("Cannot probe %s for duplicates: %v", ipAddress, err)
+		return nil
+	}
+	duplicate, err := u.probeRendezvousIP(ns, ipAddress, local)
+	if err != nil {
+		u.logger.Warnf("Cannot probe %s for duplicates: %v", ipAddress, err)
+		return nil
+	}
+	if duplicate != nil {
+		u.logger.Warnf("Duplicate rendezvous IP: %s", duplicate)
+	}
+	return duplicate
+}
+
+func duplicateRendezvousIPText(duplicate checks.DuplicateIP, local bool) string {
+	switch {
+	case len(duplicate.MACs) == 0:
+		return fmt.Sprintf(DUPLICATE_DAD_IP_TEXT_FORMAT, duplicate.IP, duplicate.Iface)
+	case local:
+		return fmt.Sprintf(DUPLICATE_LOCAL_IP_TEXT_FORMAT, duplicate.IP, strings.Join(duplicate.MACs, ", "))
+	default:
+		return fmt.Sprintf(DUPLICATE_RENDEZVOUS_IP_TEXT_FORMAT, duplicate.IP, strings.Join(duplicate.MACs, ", "))
+	}
+}
+
+func (u *UI) showRendezvousIPDuplicateModal(duplicate checks.DuplicateIP, local bool, save func(), focusForBackButton func()) {
+	modal := tview.NewModal()
+	modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		u.pages.RemovePage(PAGE_RENDEZVOUS_IP_DUPLICATE)
+		switch buttonLabel {
+		case SAVE_ANYWAY_BUTTON:
+			save()
+		case BACK_BUTTON:
+			focusForBackButton()
+		}
+	})
+	modal.SetBackgroundColor(newt.ColorGray)
+	modal.SetBorder(true)
+	modal.SetButtonBackgroundColor(newt.ColorGray).
+		SetButtonTextColor(newt.ColorRed)
+	modal.AddButtons([]string{BACK_BUTTON, SAVE_ANYWAY_BUTTON})
+	modal.SetText(duplicateRendezvousIPText(duplicate, local))
+
+	u.pages.AddPage(PAGE_RENDEZVOUS_IP_DUPLICATE, modal, true, false)
+	u.app.SetFocus(modal)
+	u.pages.ShowPage(PAGE_RENDEZVOUS_IP_DUPLICATE)
+}
@@ -28,8 +28,7 @@ func (u *UI) showRendezvousIPSaveSuccessModal(savedIP string, focusForBackButton
 			focusForBackButton()
 		}
 		if buttonLabel == CONFIGURE_NETWORK_BUTTON {
-			u.showNMTUIWithErrorDialog(u.setFocusToRendezvousIP)
-			u.pages.SwitchToPage(PAGE_RENDEZVOUS_IP_SAVE_SUCCESS)
+			u.showNetEditor(u.setFocusToRendezvousIP)
 		}
 
 	})
@@ -145,13 +145,29 @@ func (u *UI) updateSelectIPList(ipAddresses []string) {
 			case backOption:
 				u.setFocusToRendezvousIP()
 			default:
-				err := u.saveRendezvousIPAddress(selected)
-				if err != nil {
-					u.showRendezvousModal(fmt.Sprintf(SAVE_RENDEZVOUS_IP_ERROR_FORMAT, err.Error()), []string{BACK_BUTTON})
-				} else {
-					u.showRendezvousIPSaveSuccessModal(selected, u.setFocusToSelectIP)
-				}
+				u.showRendezvousModal(fmt.Sprintf(CHECKING_DUPLICATE_TEXT_FORMAT, selected), []string{})
+				// the probe takes a while, so it runs in the background
+				go u.saveSelectedIPAfterDuplicateCheck(selected)
 			}
 		})
 	}
 }
+
+func (u *UI) saveSelectedIPAfterDuplicateCheck(ipAddress string) {
+	duplicate := u.duplicateRendezvousIP(ipAddress, true)
+	u.app.QueueUpdateDraw(func() {
+		save := func() {
+			err := u.saveRendezvousIPAddress(ipAddress)
+			if err != nil {
+				u.showRendezvousModal(fmt.Sprintf(SAVE_RENDEZVOUS_IP_ERROR_FORMAT, err.Error()), []string{BACK_BUTTON})
+			} else {
+				u.showRendezvousIPSaveSuccessModal(ipAddress, u.setFocusToSelectIP)
+			}
+		}
+		if duplicate != nil {
+			u.showRendezvousIPDuplicateModal(*duplicate, true, save, u.setFocusToSelectIP)
+			return
+		}
+		save()
+	})
+}
@@ -1,9 +1,15 @@
 package ui
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
 )
@@ -77,8 +83,82 @@ func TestSelectIPListNavigation1Address(t *testing.T) {
 	assert.Equal(t, 0, list.GetCurrentItem()) // should skip blank line at position 1
 }
 
+func TestSelectDuplicateIP(t *testing.T) {
+	config := checks.Config{
+		RendezvousHostEnvPath: filepath.Join(t.TempDir(), "rendezvous-host.env"),
+	}
+	assert.NoError(t, os.WriteFile(config.RendezvousHostEnvPath, []byte("NODE_ZERO_IP={{.RendezvousIP}}\n"), 0644))
+	ui, screen := newSimulatedUI(t, config, nil)
+	ui.retrieveNetState = func() (net.NetState, error) { return net.NetState{}, nil }
+	ui.probeRendezvousIP = func(ns net.NetState, ipAddress string, local bool) (*checks.DuplicateIP, error) {
+		assert.True(t, local)
+		return &checks.DuplicateIP{Iface: "eth0", IP: ipAddress, MACs: []string{"52:54:00:12:34:56"}}, nil
+	}
+
+	startUI(t, ui)
+
+	ui.saveSelectedIPAfterDuplicateCheck("192.168.111.20")
+	waitForPage(t, ui, PAGE_RENDEZVOUS_IP_DUPLICATE)
+	assert.Empty(t, rendezvous.Read(config.RendezvousHostEnvPath))
+
+	// <Back> is focused first, then <Save anyway>
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.Eventually(t, func() bool {
+		return rendezvous.Read(config.RendezvousHostEnvPath) == "192.168.111.20"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t,
+		"Warning: 192.168.111.20 is also used by another host (52:54:00:12:34:56). The installation will fail unless the address is changed.",
+		duplicateRendezvousIPText(checks.DuplicateIP{Iface: "eth0", IP: "192.168.111.20", MACs: []string{"52:54:00:12:34:56"}}, true))
+	assert.Equal(t,
+		"Warning: several hosts answer for 192.168.111.80 (52:54:00:00:00:80, 52:54:00:00:00:99). The rendezvous IP may be assigned to more than one host.",
+		duplicateRendezvousIPText(checks.DuplicateIP{Iface: "eth0", IP: "192.168.111.80", MACs: []string{"52:54:00:00:00:80", "52:54:00:00:00:99"}}, false))
+}
+
+func TestConnectivityFailConfigureNetwork(t *testing.T) {
+	ui, screen := newSimulatedUI(t, checks.Config{}, nil)
+	ui.retrieveNetState = func() (net.NetState, error) {
+		return testNetState(t), nil
+	}
+
+	startUI(t, ui)
+
+	ui.app.QueueUpdateDraw(func() {
+		ui.showRendezvousIPConnectivityFailModal("192.168.111.20", func() {})
+	})
+	waitForPage(t, ui, PAGE_RENDEZVOUS_IP_CONNECTIVITY_FAIL)
+
+	// <Configure Network> is the third button
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	waitForPage(t, ui, PAGE_NET_EDITOR)
+}
+
 func applyKeyToList(list *tview.List, key tcell.Key, numKeyPresses int) {
 	for i := 0; i < numKeyPresses; i++ {
 		list.InputHandler()(tcell.NewEventKey(key, 0, tcell.ModNone), func(p tview.Primitive) {})
 	}
 }
+
+func TestRendezvousIPSavedWhenConfirmed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rendezvous-host.env")
+	assert.NoError(t, os.WriteFile(path, []byte("NODE_ZERO_IP={{.RendezvousIP}}\n"), 0644))
+	ui, screen := newSimulatedUI(t, checks.Config{RendezvousHostEnvPath: path, PromptTimeout: time.Minute}, nil)
+	ui.initialRendezvousIP = "192.168.111.80"
+	startUI(t, ui)
+
+	ui.app.QueueUpdateDraw(func() {
+		ui.ShowRendezvousIPPage(ui.initialRendezvousIP)
+	})
+	waitForPage(t, ui, PAGE_RENDEZVOUS_IP_TIMEOUT)
+	// the prefilled IP is not saved until it's confirmed
+	assert.Empty(t, rendezvous.Read(path))
+
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.Eventually(t, func() bool {
+		return rendezvous.Read(path) == "192.168.111.80"
+	}, 5*time.Second, 10*time.Millisecond)
+}
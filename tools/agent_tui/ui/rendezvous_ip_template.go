@@ -1,23 +1,11 @@
 package ui
 
 import (
-	"bytes"
-	"errors"
-	"fmt"
-	"io/fs"
-	"os"
-	"text/template"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
 )
 
-type rendezvousHostEnvTemplateData struct {
-	RendezvousIP string
-}
-
 func (u *UI) saveRendezvousIPAddress(ipAddress string) error {
-	templateData := &rendezvousHostEnvTemplateData{
-		RendezvousIP: ipAddress,
-	}
-	err := templateRendezvousHostEnv(templateData)
+	err := rendezvous.Save(u.rendezvousHostEnvPath, ipAddress)
 	if err != nil {
 		return err
 	}
@@ -26,32 +14,3 @@ func (u *UI) saveRendezvousIPAddress(ipAddress string) error {
 
 	return nil
 }
-
-func templateRendezvousHostEnv(templateData interface{}) error {
-	data, err := os.ReadFile(fmt.Sprintf("%s.template", RENDEZVOUS_HOST_ENV_PATH))
-	if errors.Is(err, fs.ErrNotExist) {
-		data, err = os.ReadFile(RENDEZVOUS_HOST_ENV_PATH)
-	}
-	if err != nil {
-		return err
-	}
-
-	tmpl := template.New(RENDEZVOUS_HOST_ENV_PATH)
-	tmpl, err = tmpl.Parse(string(data))
-	if err != nil {
-		return err
-	}
-
-	buf := &bytes.Buffer{}
-	if err := tmpl.Execute(buf, templateData); err != nil {
-		panic(err)
-	}
-	templatedFileData := buf.Bytes()
-
-	err = os.WriteFile(RENDEZVOUS_HOST_ENV_PATH, []byte(templatedFileData), 0644)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
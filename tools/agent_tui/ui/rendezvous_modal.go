@@ -22,7 +22,7 @@ func (u *UI) createRendezvousModal() {
 			switch buttonLabel {
 			case CONTINUE_BUTTON, BACK_BUTTON:
 			case RENDEZVOUS_CONFIGURE_NETWORK_BUTTON:
-				u.showNMTUIWithErrorDialog(u.setFocusToRendezvousIP)
+				u.showNetEditor(u.setFocusToRendezvousIP)
 			}
 			u.setFocusToRendezvousIP()
 		}).
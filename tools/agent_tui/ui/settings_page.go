@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_SETTINGS          string = "settings"
+	SETTINGS_BACK_BUTTON   string = "<Back>"
+	SETTINGS_DEFAULT_VALUE string = "-"
+)
+
+// createSettingsPage creates the page listing the effective value of
+// each option, and where it was set
+func (u *UI) createSettingsPage() {
+	u.settingsTable = tview.NewTable()
+	u.settingsTable.SetBorder(true).
+		SetBorderColor(newt.ColorBlack).
+		SetTitleColor(newt.ColorBlack)
+	u.settingsTable.SetBackgroundColor(newt.ColorGray)
+	u.settingsTable.SetFixed(1, 0)
+	u.settingsTable.SetSelectedStyle(tcell.StyleDefault.Background(newt.ColorBlue).Foreground(newt.ColorGray))
+
+	u.settingsBackForm = tview.NewForm()
+	u.settingsBackForm.SetButtonsAlign(tview.AlignCenter)
+	u.settingsBackForm.AddButton(SETTINGS_BACK_BUTTON, func() {
+		u.hideSettingsPage()
+	})
+	u.settingsBackForm.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
+		Foreground(newt.ColorGray))
+	u.settingsBackForm.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
+		Foreground(newt.ColorBlack))
+
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.settingsTable, 0, 1, false).
+		AddItem(u.settingsBackForm, 3, 0, false)
+	mainFlex.SetTitle("  Settings  ").
+		SetTitleColor(newt.ColorRed).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	mainFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			u.focusedItem = (u.focusedItem + 1) % len(u.focusableItems)
+		case tcell.KeyBacktab:
+			u.focusedItem--
+			if u.focusedItem < 0 {
+				u.focusedItem = len(u.focusableItems) - 1
+			}
+		case tcell.KeyESC:
+			u.hideSettingsPage()
+			return nil
+		default:
+			return event
+		}
+		u.app.SetFocus(u.focusableItems[u.focusedItem])
+		return nil
+	})
+
+	u.SetSettings(nil, nil)
+	u.pages.AddPage(PAGE_SETTINGS, mainFlex, true, false)
+}
+
+// SetSettings fills the settings page with the effective options, and
+// the warnings found while loading them
+func (u *UI) SetSettings(settings []config.Setting, warnings []string) {
+	u.settingsTable.Clear()
+	for col, header := range []string{"Option", "Value", "Source"} {
+		u.settingsTable.SetCell(0, col, &tview.TableCell{
+			Text:            header,
+			Color:           newt.ColorRed,
+			BackgroundColor: newt.ColorGray,
+			NotSelectable:   true,
+		})
+	}
+
+	for i, s := range settings {
+		value := s.Value
+		if value == "" {
+			value = SETTINGS_DEFAULT_VALUE
+		}
+		color := newt.ColorBlack
+		if s.Source != config.SourceDefault {
+			color = newt.ColorBlue
+		}
+		for col, text := range []string{s.Name, value, string(s.Source)} {
+			u.settingsTable.SetCell(i+1, col, &tview.TableCell{
+				Text:            tview.Escape(text),
+				Color:           color,
+				BackgroundColor: newt.ColorGray,
+				Expansion:       1,
+			})
+		}
+	}
+
+	title := "  Effective settings  "
+	if len(warnings) > 0 {
+		title = fmt.Sprintf("  Effective settings (%d warnings, see the log)  ", len(warnings))
+	}
+	u.settingsTable.SetTitle(title)
+}
+
+// ShowSettingsPage displays the settings. The doneFunc callback
+// is used to go back to the previous page.
+func (u *UI) ShowSettingsPage(doneFunc func()) {
+	u.settingsPageDone = doneFunc
+	u.focusableItems = []tview.Primitive{
+		u.settingsTable,
+		u.settingsBackForm.GetButton(0),
+	}
+	u.focusedItem = 0
+	u.settingsTable.SetSelectable(true, false)
+	u.pages.SwitchToPage(PAGE_SETTINGS)
+	u.app.SetFocus(u.settingsTable)
+}
+
+func (u *UI) hideSettingsPage() {
+	u.focusedItem = 0
+	u.settingsPageDone()
+}
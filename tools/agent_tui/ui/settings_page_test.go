@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/config"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingsPage(t *testing.T) {
+	ui := NewUI(tview.NewApplication(), checks.Config{LogPath: "/tmp/agent-tui.log"}, logrus.New(), "")
+
+	ui.SetSettings([]config.Setting{
+		{Name: "log-level", Value: "info", Source: config.SourceDefault},
+		{Name: "rendezvous-ip", Value: "192.168.111.80", Source: config.SourceKernelCmdline},
+		{Name: "rendezvous-host-env-path", Value: "", Source: config.SourceDefault},
+	}, []string{"ignoring unknown kernel parameter agent-tui.foo"})
+
+	assert.Equal(t, 4, ui.settingsTable.GetRowCount())
+	assert.Equal(t, "rendezvous-ip", ui.settingsTable.GetCell(2, 0).Text)
+	assert.Equal(t, "kernel command line", ui.settingsTable.GetCell(2, 2).Text)
+	assert.Equal(t, SETTINGS_DEFAULT_VALUE, ui.settingsTable.GetCell(3, 1).Text)
+	assert.Contains(t, ui.settingsTable.GetTitle(), "1 warnings")
+
+	// The page is opened from the checks page shortcut, and closed with ESC
+	ui.setFocusToChecks()
+	ui.mainFlex.InputHandler()(tcell.NewEventKey(tcell.KeyRune, 'S', tcell.ModNone), func(p tview.Primitive) {})
+	page, settingsPage := ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_SETTINGS, page)
+
+	// Backtab moves the focus backward, wrapping around
+	assert.Equal(t, 0, ui.focusedItem)
+	settingsPage.InputHandler()(tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone), func(p tview.Primitive) {})
+	assert.Equal(t, 1, ui.focusedItem)
+	settingsPage.InputHandler()(tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone), func(p tview.Primitive) {})
+	assert.Equal(t, 0, ui.focusedItem)
+
+	settingsPage.InputHandler()(tcell.NewEventKey(tcell.KeyESC, 0, tcell.ModNone), func(p tview.Primitive) {})
+	page, _ = ui.pages.GetFrontPage()
+	assert.Equal(t, PAGE_CHECKSCREEN, page)
+}
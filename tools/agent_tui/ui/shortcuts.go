@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+// shortcut is a key that triggers an action from the checks page,
+// for the features that do not have a dedicated button
+type shortcut struct {
+	key    rune
+	label  string
+	action func()
+}
+
+func (u *UI) createShortcutsBar() {
+	u.shortcutsBar = tview.NewTextView()
+	u.shortcutsBar.SetDynamicColors(true)
+	u.shortcutsBar.SetTextAlign(tview.AlignCenter)
+	u.shortcutsBar.SetTextColor(newt.ColorBlack)
+	u.shortcutsBar.SetBackgroundColor(newt.ColorGray)
+}
+
+// addShortcut registers a new shortcut and displays it in the bar
+// at the bottom of the checks page
+func (u *UI) addShortcut(key rune, label string, action func()) {
+	u.shortcuts = append(u.shortcuts, shortcut{
+		key:    unicode.ToLower(key),
+		label:  label,
+		action: action,
+	})
+
+	labels := []string{}
+	for _, s := range u.shortcuts {
+		labels = append(labels, fmt.Sprintf("[::b]%c[::-] %s", unicode.ToUpper(s.key), s.label))
+	}
+	u.shortcutsBar.SetText(strings.Join(labels, "   "))
+}
+
+// handleShortcut runs the action associated to the key, if any
+func (u *UI) handleShortcut(key rune) bool {
+	for _, s := range u.shortcuts {
+		if s.key == unicode.ToLower(key) {
+			s.action()
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/rendezvous"
 	"github.com/rivo/tview"
 )
 
@@ -16,8 +17,6 @@ const (
 	PAGE_TIMEOUTSCREEN         string = "timeout"
 	PAGE_RENDEZVOUS_IP_TIMEOUT string = "rendezvousIPTimeout"
 
-	timeout = 20 * time.Second
-
 	modalText = "Agent-based installer connectivity checks passed. No additional network configuration is required." +
 		"Do you still wish to modify the network configuration for this host?\n\n " +
 		"This prompt will timeout in [red]%.f [white]seconds."
@@ -54,7 +53,7 @@ func (u *UI) createTimeoutModal() {
 	userPromptButtons := []string{YES_BUTTON, NO_BUTTON}
 	u.timeoutModal.AddButtons(userPromptButtons)
 
-	u.timeoutModal.SetText(fmt.Sprintf(modalText, timeout.Seconds()))
+	u.timeoutModal.SetText(fmt.Sprintf(modalText, u.promptTimeout.Seconds()))
 	u.pages.AddPage(PAGE_TIMEOUTSCREEN, u.timeoutModal, true, false)
 }
 
@@ -64,7 +63,7 @@ func (u *UI) ShowTimeoutDialog() {
 	u.pages.ShowPage(PAGE_TIMEOUTSCREEN)
 
 	// Start countdown timer
-	u.startCountdownTimer(timeout, u.timeoutDialogCancel, func(remaining float64) {
+	u.startCountdownTimer(u.promptTimeout, u.timeoutDialogCancel, func(remaining float64) {
 		// Update message with remaining time
 		u.app.QueueUpdateDraw(func() {
 			u.timeoutModal.SetText(fmt.Sprintf(modalText, remaining))
@@ -75,6 +74,18 @@ func (u *UI) ShowTimeoutDialog() {
 	})
 }
 
+// continueOrPrompt is called when all the checks are successful. It asks the
+// user whether to modify the network configuration, unless auto-continue is
+// enabled.
+func (u *UI) continueOrPrompt() {
+	if u.autoContinue {
+		u.logger.Infof("Checks passed and auto-continue is enabled, exiting")
+		u.app.Stop()
+		return
+	}
+	u.ShowTimeoutDialog()
+}
+
 func (u *UI) cancelUserPrompt() {
 	u.timeoutDialogCancel <- true
 	u.setIsTimeoutDialogActive(false)
@@ -94,8 +105,14 @@ func (u *UI) createRendezvousIPTimeoutModal() {
 				u.cancelRendezvousIPTimeout()
 				u.setFocusToRendezvousIP()
 			} else {
-				// Quit button - exit the application
+				// Quit button - the prefilled IP is confirmed, save it
+				// if it's not stored yet and exit the application
 				u.cancelRendezvousIPTimeout()
+				if u.initialRendezvousIP != rendezvous.Read(u.rendezvousHostEnvPath) {
+					if err := u.saveRendezvousIPAddress(u.initialRendezvousIP); err != nil {
+						u.logger.Errorf("Failed to save the rendezvous IP %s: %v", u.initialRendezvousIP, err)
+					}
+				}
 				u.app.Stop()
 			}
 		}).
@@ -114,12 +131,12 @@ func (u *UI) createRendezvousIPTimeoutModal() {
 
 func (u *UI) ShowRendezvousIPTimeoutDialog(rendezvousIP string) {
 	u.setIsRendezvousIPTimeoutActive(true)
-	u.rendezvousIPTimeoutModal.SetText(fmt.Sprintf(rendezvousIPTimeoutModalText, rendezvousIP, timeout.Seconds()))
+	u.rendezvousIPTimeoutModal.SetText(fmt.Sprintf(rendezvousIPTimeoutModalText, rendezvousIP, u.promptTimeout.Seconds()))
 	u.app.SetFocus(u.rendezvousIPTimeoutModal)
 	u.pages.ShowPage(PAGE_RENDEZVOUS_IP_TIMEOUT)
 
 	// Start countdown timer
-	u.startCountdownTimer(timeout, u.rendezvousIPTimeoutCancel, func(remaining float64) {
+	u.startCountdownTimer(u.promptTimeout, u.rendezvousIPTimeoutCancel, func(remaining float64) {
 		// Update message with remaining time
 		u.app.QueueUpdateDraw(func() {
 			u.rendezvousIPTimeoutModal.SetText(fmt.Sprintf(rendezvousIPTimeoutModalText, rendezvousIP, remaining))
@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/newt"
+	"github.com/rivo/tview"
+)
+
+const (
+	PAGE_TRACEROUTE             string = "traceroute"
+	FIELD_TRACEROUTE_TARGET     string = "Target: "
+	FIELD_TRACEROUTE_PROTOCOL   string = "Protocol: "
+	TRACEROUTE_START_BUTTON     string = "<Start>"
+	TRACEROUTE_NO_TARGET_TEXT          = "Neither the release image host nor the rendezvous IP is configured."
+	TRACEROUTE_START_TEXT              = "Select the target and the protocol, then press Start."
+	TRACEROUTE_HEADER_TEXT             = "traceroute to %s (%s), %d hops max, %s probes"
+	TRACEROUTE_FAILED_TEXT             = "[red]Traceroute failed: %v[black]"
+	TRACEROUTE_DONE_TEXT               = "[green]Destination reached[black]"
+	TRACEROUTE_UNREACHABLE_TEXT        = "[red]Destination unreachable[black]"
+	TRACEROUTE_MAX_HOPS_TEXT           = "[red]Destination not reached after %d hops[black]"
+)
+
+var tracerouteProtocols = []net.TracerouteProtocol{net.TracerouteICMP, net.TracerouteUDP}
+
+func (u *UI) createTraceroutePage(config checks.Config) {
+	u.tracerouteConfig = config
+
+	u.tracerouteView = tview.NewTextView()
+	u.tracerouteView.SetDynamicColors(true).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack).
+		SetTitle(" Hops ").
+		SetTitleColor(newt.ColorBlack)
+	u.tracerouteView.SetBackgroundColor(newt.ColorGray)
+	u.tracerouteView.SetTextColor(newt.ColorBlack)
+
+	protocols := []string{}
+	for _, p := range tracerouteProtocols {
+		protocols = append(protocols, string(p))
+	}
+	u.tracerouteForm = tview.NewForm()
+	u.tracerouteForm.SetItemPadding(0)
+	u.tracerouteForm.
+		AddDropDown(FIELD_TRACEROUTE_TARGET, []string{}, 0, nil).
+		AddDropDown(FIELD_TRACEROUTE_PROTOCOL, protocols, 0, nil)
+	u.tracerouteForm.SetFieldTextColor(newt.ColorGray)
+
+	u.tracerouteButtons = tview.NewForm()
+	u.tracerouteButtons.SetButtonsAlign(tview.AlignCenter)
+	u.tracerouteButtons.AddButton(TRACEROUTE_START_BUTTON, func() {
+		u.startTraceroute()
+	})
+	u.tracerouteButtons.AddButton(BACK_BUTTON, func() {
+		u.hideTraceroutePage()
+	})
+	u.tracerouteButtons.SetButtonActivatedStyle(tcell.StyleDefault.Background(newt.ColorRed).
+		Foreground(newt.ColorGray))
+	u.tracerouteButtons.SetButtonStyle(tcell.StyleDefault.Background(newt.ColorGray).
+		Foreground(newt.ColorBlack))
+
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.tracerouteView, 0, 1, false).
+		AddItem(u.tracerouteForm, 2, 0, false).
+		AddItem(u.tracerouteButtons, 3, 0, false)
+	mainFlex.SetTitle("  Traceroute  ").
+		SetTitleColor(newt.ColorRed).
+		SetBorder(true).
+		SetBorderColor(newt.ColorBlack)
+
+	mainFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			u.focusedItem++
+			if u.focusedItem > len(u.focusableItems)-1 {
+				u.focusedItem = 0
+			}
+		case tcell.KeyBacktab:
+			u.focusedItem--
+			if u.focusedItem < 0 {
+				u.focusedItem = len(u.focusableItems) - 1
+			}
+		case tcell.KeyESC:
+			if u.tracerouteView.HasFocus() {
+				u.hideTraceroutePage()
+				return nil
+			}
+			return event
+		default:
+			return event
+		}
+		u.app.SetFocus(u.focusableItems[u.focusedItem])
+		return nil
+	})
+
+	u.pages.AddPage(PAGE_TRACEROUTE, mainFlex, true, false)
+}
+
+// ShowTraceroutePage displays the traceroute page, where the path
+// toward the release image host or the rendezvous IP can be traced.
+// The doneFunc callback is used to go back to the previous page.
+func (u *UI) ShowTraceroutePage(doneFunc func()) {
+	u.traceroutePageDone = doneFunc
+
+	// the rendezvous IP may have been set in the meantime
+	u.tracerouteTargets = checks.TracerouteTargets(u.tracerouteConfig)
+	options := []string{}
+	for _, target := range u.tracerouteTargets {
+		options = append(options, target.String())
+	}
+	targetDropDown := u.tracerouteForm.GetFormItemByLabel(FIELD_TRACEROUTE_TARGET).(*tview.DropDown)
+	targetDropDown.SetOptions(options, nil)
+	if len(options) > 0 {
+		targetDropDown.SetCurrentOption(0)
+		u.tracerouteView.SetText(TRACEROUTE_START_TEXT)
+	} else {
+		u.tracerouteView.SetText(TRACEROUTE_NO_TARGET_TEXT)
+	}
+
+	u.focusableItems = []tview.Primitive{
+		u.tracerouteView,
+		targetDropDown,
+		u.tracerouteForm.GetFormItemByLabel(FIELD_TRACEROUTE_PROTOCOL),
+		u.tracerouteButtons.GetButton(0),
+		u.tracerouteButtons.GetButton(1),
+	}
+	u.focusedItem = 3
+	u.pages.SwitchToPage(PAGE_TRACEROUTE)
+	u.app.SetFocus(u.tracerouteButtons.GetButton(0))
+}
+
+func (u *UI) hideTraceroutePage() {
+	u.stopTraceroute()
+	u.focusedItem = 0
+	u.traceroutePageDone()
+}
+
+func (u *UI) stopTraceroute() {
+	if u.tracerouteStop != nil {
+		close(u.tracerouteStop)
+		u.tracerouteStop = nil
+	}
+}
+
+// startTraceroute traces the path toward the selected target in the
+// background, replacing any traceroute still running. The hops are
+// displayed as they are discovered, and logged.
+func (u *UI) startTraceroute() {
+	index, _ := u.tracerouteForm.GetFormItemByLabel(FIELD_TRACEROUTE_TARGET).(*tview.DropDown).GetCurrentOption()
+	if index < 0 || index >= len(u.tracerouteTargets) {
+		return
+	}
+	target := u.tracerouteTargets[index]
+	_, protocol := u.tracerouteForm.GetFormItemByLabel(FIELD_TRACEROUTE_PROTOCOL).(*tview.DropDown).GetCurrentOption()
+	opts := net.DefaultTracerouteOptions
+	opts.Protocol = net.TracerouteProtocol(protocol)
+
+	u.stopTraceroute()
+	stop := make(chan struct{})
+	u.tracerouteStop = stop
+	u.tracerouteView.Clear()
+	u.app.SetFocus(u.tracerouteView)
+	u.focusedItem = 0
+
+	// the updates of a stopped traceroute are discarded
+	update := func(text string) {
+		u.app.QueueUpdateDraw(func() {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			fmt.Fprintln(u.tracerouteView, text)
+			u.tracerouteView.ScrollToEnd()
+		})
+	}
+
+	header := fmt.Sprintf(TRACEROUTE_HEADER_TEXT, target.Host, target.Name, opts.MaxHops, opts.Protocol)
+	u.logger.Info(header)
+	fmt.Fprintln(u.tracerouteView, header)
+
+	go func() {
+		var last net.Hop
+		_, err := u.traceroute(target.Host, opts, func(hop net.Hop) {
+			last = hop
+			u.logger.Infof("traceroute to %s: %s", target.Host, hop)
+			update(tview.Escape(hop.String()))
+		}, stop)
+		switch {
+		case err != nil:
+			u.logger.Infof("traceroute to %s failed: %v", target.Host, err)
+			update(fmt.Sprintf(TRACEROUTE_FAILED_TEXT, tview.Escape(err.Error())))
+		case last.Reached:
+			update(TRACEROUTE_DONE_TEXT)
+		case last.Unreachable:
+			update(TRACEROUTE_UNREACHABLE_TEXT)
+		case last.TTL == opts.MaxHops:
+			u.logger.Infof("traceroute to %s: not reached after %d hops", target.Host, opts.MaxHops)
+			update(fmt.Sprintf(TRACEROUTE_MAX_HOPS_TEXT, opts.MaxHops))
+		}
+	}()
+}
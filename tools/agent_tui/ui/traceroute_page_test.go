@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"bytes"
+	stdnet "net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceroutePage(t *testing.T) {
+	config := checks.Config{
+		ReleaseImageHostname:  "quay.io",
+		RendezvousHostEnvPath: filepath.Join(t.TempDir(), "rendezvous-host.env"),
+	}
+	assert.NoError(t, os.WriteFile(config.RendezvousHostEnvPath, []byte("NODE_ZERO_IP=192.168.111.80\n"), 0644))
+	logs := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(logs)
+	ui, screen := newSimulatedUI(t, config, logger)
+
+	traced := make(chan net.TracerouteOptions, 1)
+	ui.traceroute = func(host string, opts net.TracerouteOptions, hop func(net.Hop), stop <-chan struct{}) (stdnet.IP, error) {
+		assert.Equal(t, "192.168.111.80", host)
+		hop(net.Hop{TTL: 1, Addr: "192.168.111.1", RTTs: []time.Duration{time.Millisecond}})
+		hop(net.Hop{TTL: 2, RTTs: []time.Duration{0}})
+		hop(net.Hop{TTL: 3, Addr: "192.168.111.80", RTTs: []time.Duration{2 * time.Millisecond}, Reached: true})
+		traced <- opts
+		return stdnet.ParseIP(host), nil
+	}
+
+	startUI(t, ui)
+	openShortcutPage(t, ui, 't', PAGE_TRACEROUTE)
+
+	var targets []string
+	ui.app.QueueUpdate(func() {
+		for _, target := range ui.tracerouteTargets {
+			targets = append(targets, target.String())
+		}
+		ui.tracerouteForm.GetFormItemByLabel(FIELD_TRACEROUTE_TARGET).(*tview.DropDown).SetCurrentOption(1)
+		ui.tracerouteForm.GetFormItemByLabel(FIELD_TRACEROUTE_PROTOCOL).(*tview.DropDown).SetCurrentOption(1)
+	})
+	assert.Equal(t, []string{"quay.io (release image)", "192.168.111.80 (rendezvous IP)"}, targets)
+
+	// <Start> is focused
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	select {
+	case opts := <-traced:
+		assert.Equal(t, net.TracerouteUDP, opts.Protocol)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the traceroute was not started")
+	}
+
+	var text string
+	assert.Eventually(t, func() bool {
+		ui.app.QueueUpdate(func() {
+			text = ui.tracerouteView.GetText(true)
+		})
+		return strings.Contains(text, "Destination reached")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, strings.Join([]string{
+		"traceroute to 192.168.111.80 (rendezvous IP), 30 hops max, udp probes",
+		" 1  192.168.111.1  1.000 ms",
+		" 2  *",
+		" 3  192.168.111.80  2.000 ms",
+		"Destination reached",
+		"",
+	}, "\n"), text)
+	assert.Contains(t, logs.String(), "traceroute to 192.168.111.80:  2  *")
+
+	// the view is focused once started, and ESC goes back
+	screen.InjectKey(tcell.KeyESC, 0, tcell.ModNone)
+	waitForPage(t, ui, PAGE_CHECKSCREEN)
+}
@@ -1,10 +1,15 @@
 package ui
 
 import (
+	gonet "net"
 	"sync/atomic"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/logs"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/media"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/net"
 	"github.com/rivo/tview"
 	"github.com/sirupsen/logrus"
 )
@@ -16,9 +21,13 @@ type UI struct {
 	primaryCheck        *tview.Table
 	checks              *tview.Table    // summary of all checks
 	details             *tview.TextView // where errors from checks are displayed
-	netConfigForm       *tview.Form     // contains "Configure network" button
-	timeoutModal        *tview.Modal    // popup window that times out
-	splashScreen        *tview.Modal    // display initial waiting message
+	detailsFilter       *tview.InputField
+	checkDetails        *checkDetails // errors reported by each check
+	selectedCheck       string        // check whose errors are currently displayed
+	checkRows           []string      // check types listed in the additional checks table
+	netConfigForm       *tview.Form   // contains "Configure network" button
+	timeoutModal        *tview.Modal  // popup window that times out
+	splashScreen        *tview.Modal  // display initial waiting message
 	nmtuiActive         atomic.Value
 	timeoutDialogActive atomic.Value
 	timeoutDialogCancel chan bool
@@ -40,6 +49,122 @@ type UI struct {
 	rendezvousIPTimeoutActive atomic.Value
 	rendezvousIPTimeoutCancel chan bool
 
+	rendezvousHostEnvPath string
+	promptTimeout         time.Duration
+	connectivityTimeout   time.Duration
+	probeRendezvousIP     func(ns net.NetState, ipAddress string, local bool) (*checks.DuplicateIP, error)
+	autoContinue          bool
+
+	// Log viewer
+	logSources        []logs.Source
+	logEntries        []logs.Entry
+	logView           *tview.TextView
+	logSourceDropDown *tview.DropDown
+	logLevelDropDown  *tview.DropDown
+	logSearch         *tview.InputField
+	logBackForm       *tview.Form
+	logPageStop       chan struct{}
+	logPageDone       func()
+
+	// Settings page
+	settingsTable    *tview.Table
+	settingsBackForm *tview.Form
+	settingsPageDone func()
+
+	// Network configuration
+	netModal           *tview.Modal
+	netModalDone       func(label string)
+	netEditorIfaces    *tview.List
+	netEditorForm      *tview.Form
+	netEditorDNSForm   *tview.Form
+	netEditorButtons   *tview.Form
+	netEditorEdits     []*ifaceEdit
+	netEditorDNS       *dnsEdit
+	netEditorSelected  int
+	netEditorDone      func()
+	retrieveNetState   func() (net.NetState, error)
+	lintNetState       func(net.NetState) []net.Finding
+	applyCheckpoint    func(state interface{}, timeout time.Duration) error
+	createCheckpoint   func(timeout time.Duration) error
+	commitCheckpoint   func() error
+	rollbackCheckpoint func() error
+	checkpointCancel   chan bool
+	runChecks          func() []checks.CheckResult
+	triggerChecks      func()
+	removableMounts    func() ([]media.Mount, error)
+	execNMTUI          func() error
+
+	// Export page
+	exportView     *tview.TextView
+	exportForm     *tview.Form
+	exportButtons  *tview.Form
+	exportData     []byte
+	exportPageDone func()
+
+	// Bond/VLAN wizard
+	bondWizardFlex    *tview.Flex
+	bondWizardText    *tview.TextView
+	bondWizardForm    *tview.Form
+	bondWizardSummary *tview.TextView
+	bondWizardButtons *tview.Form
+	bondWizard        *bondWizard
+	bondWizardDone    func()
+	physicalNICs      func(net.NetState) []net.NIC
+	waitForCarrier    func(name string, timeout time.Duration) error
+
+	// Network status page
+	netStatusTree        *tview.TreeView
+	netStatusRaw         *tview.TextView
+	netStatusViews       *tview.Pages
+	netStatusSearch      *tview.InputField
+	netStatusHints       *tview.TextView
+	netStatusDone        func()
+	netStatusState       net.NetState
+	netStatusChanges     []net.Change
+	netStatusHidden      int
+	netStatusHideVirtual bool
+	netStatusHideDown    bool
+	netStatusRawJSON     bool
+	watchNetlink         func(stop <-chan struct{}) (<-chan net.LinkEvent, error)
+	retrieveRawNetState  func() ([]byte, error)
+	isVirtualIface       func(net.Iface) bool
+	lldpIfaces           []string
+	lldpNeighbors        map[string][]net.LLDPNeighbor
+	lldpListening        bool
+	lldpErr              error
+	lldpRun              int // incremented to ignore the neighbors of a previous listener
+	listenLLDP           func(ifaces []string, window time.Duration, neighbor func(net.LLDPNeighbor), stop <-chan struct{}) error
+
+	// Import page
+	importView     *tview.TextView
+	importForm     *tview.Form
+	importButtons  *tview.Form
+	importState    *net.ImportedState
+	importChanges  []net.Change
+	importPageDone func()
+
+	// Traceroute page
+	tracerouteConfig   checks.Config
+	tracerouteTargets  []checks.TracerouteTarget
+	tracerouteView     *tview.TextView
+	tracerouteForm     *tview.Form
+	tracerouteButtons  *tview.Form
+	tracerouteStop     chan struct{}
+	traceroutePageDone func()
+	traceroute         func(host string, opts net.TracerouteOptions, hop func(net.Hop), stop <-chan struct{}) (gonet.IP, error)
+
+	// DHCP probe page
+	dhcpProbeIfaces   []string
+	dhcpProbeView     *tview.TextView
+	dhcpProbeForm     *tview.Form
+	dhcpProbeButtons  *tview.Form
+	dhcpProbeRun      int // incremented to ignore the results of a previous probe
+	dhcpProbePageDone func()
+	probeDHCP         func(iface string, timeout time.Duration) ([]net.DHCPOffer, error)
+
+	shortcutsBar *tview.TextView
+	shortcuts    []shortcut
+
 	focusableItems []tview.Primitive // the list of widgets that can be focused
 	focusedItem    int               // the current focused widget
 
@@ -53,6 +178,37 @@ func NewUI(app *tview.Application, config checks.Config, logger *logrus.Logger,
 		rendezvousIPTimeoutCancel: make(chan bool),
 		logger:                    logger,
 		initialRendezvousIP:       initialRendezvousIP,
+		rendezvousHostEnvPath:     config.RendezvousHostEnvPath,
+		promptTimeout:             config.PromptTimeout,
+		connectivityTimeout:       config.ConnectivityTimeout,
+		autoContinue:              config.AutoContinue,
+		retrieveNetState:          net.RetrieveNetState,
+		lintNetState:              net.Lint,
+		applyCheckpoint:           net.ApplyWithCheckpoint,
+		createCheckpoint:          net.CreateCheckpoint,
+		commitCheckpoint:          net.CommitCheckpoint,
+		rollbackCheckpoint:        net.RollbackCheckpoint,
+		checkpointCancel:          make(chan bool, 1),
+		removableMounts:           media.RemovableMounts,
+		execNMTUI:                 execNMTUI,
+		physicalNICs:              net.PhysicalNICs,
+		watchNetlink:              net.WatchNetlink,
+		retrieveRawNetState:       net.RetrieveRawNetState,
+		isVirtualIface:            net.IsVirtual,
+		listenLLDP:                net.ListenLLDP,
+		waitForCarrier:            net.WaitForCarrier,
+		probeRendezvousIP:         checks.ProbeRendezvousIP,
+		traceroute:                checks.Traceroute,
+		probeDHCP:                 net.ProbeDHCP,
+	}
+	if ui.rendezvousHostEnvPath == "" {
+		ui.rendezvousHostEnvPath = RENDEZVOUS_HOST_ENV_PATH
+	}
+	if ui.promptTimeout == 0 {
+		ui.promptTimeout = checks.DefaultPromptTimeout
+	}
+	if ui.connectivityTimeout == 0 {
+		ui.connectivityTimeout = checks.DefaultConnectivityTimeout
 	}
 	ui.nmtuiActive.Store(false)
 	ui.timeoutDialogActive.Store(false)
@@ -63,6 +219,18 @@ func NewUI(app *tview.Application, config checks.Config, logger *logrus.Logger,
 	return ui
 }
 
+// SetRunChecks sets the function used to run the connectivity checks on
+// demand, to verify the connectivity before and after a network change
+func (u *UI) SetRunChecks(runChecks func() []checks.CheckResult) {
+	u.runChecks = runChecks
+}
+
+// SetTriggerChecks sets the function used to run the periodic checks
+// immediately, when the network configuration changes
+func (u *UI) SetTriggerChecks(triggerChecks func()) {
+	u.triggerChecks = triggerChecks
+}
+
 func (u *UI) GetApp() *tview.Application {
 	return u.app
 }
@@ -73,10 +241,7 @@ func (u *UI) GetPages() *tview.Pages {
 
 func (u *UI) setFocusToChecks() {
 	// reset u.focusableItems to those on the checks page
-	u.focusableItems = []tview.Primitive{
-		u.netConfigForm.GetButton(0),
-		u.netConfigForm.GetButton(1),
-	}
+	u.resetChecksFocusableItems()
 	u.pages.SwitchToPage(PAGE_CHECKSCREEN)
 	// shifting focus back to the "Configure network"
 	// button requires setting focus in this sequence
@@ -164,6 +329,16 @@ func (u *UI) create(config checks.Config) {
 	u.createRendezvousModal()
 	u.createRendezvousIPTimeoutModal()
 	u.createSelectHostIPPage()
+	u.createLogPage(config)
+	u.createSettingsPage()
+	u.createNetModal()
+	u.createNetEditorPage()
+	u.createExportPage()
+	u.createImportPage()
+	u.createBondWizardPage()
+	u.createNetStatusPage()
+	u.createTraceroutePage(config)
+	u.createDHCPProbePage()
 	u.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		if !u.IsRendezvousIPFormActive() {
 			// Any interaction with the rendezvous IP form does
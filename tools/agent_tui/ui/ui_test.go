@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/openshift/agent-installer-utils/tools/agent_tui/checks"
+	"github.com/rivo/tview"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSimulatedUI creates a UI drawn on a simulated screen, where the key
+// events can be injected once the UI is started. The log path of the
+// tests is used when config has none, and a nil logger is replaced by a
+// new one.
+func newSimulatedUI(t *testing.T, config checks.Config, logger *logrus.Logger) (*UI, tcell.SimulationScreen) {
+	screen := tcell.NewSimulationScreen("")
+	assert.NoError(t, screen.Init())
+	if config.LogPath == "" {
+		config.LogPath = "/tmp/agent-tui.log"
+	}
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return NewUI(tview.NewApplication().SetScreen(screen), config, logger, ""), screen
+}
+
+// startUI runs the application in the background until the end of the
+// test. The dependencies of the UI must be replaced before.
+func startUI(t *testing.T, ui *UI) {
+	go ui.app.Run()
+	t.Cleanup(ui.app.Stop)
+}
+
+// openShortcutPage opens a page with its shortcut of the checks page
+func openShortcutPage(t *testing.T, ui *UI, shortcut rune, page string) {
+	ui.app.QueueUpdateDraw(func() {
+		ui.setFocusToChecks()
+		ui.handleShortcut(shortcut)
+	})
+	waitForPage(t, ui, page)
+}
+
+// waitForPage waits until the page is displayed in front
+func waitForPage(t *testing.T, ui *UI, page string) {
+	assert.Eventually(t, func() bool {
+		front, _ := ui.pages.GetFrontPage()
+		return front == page
+	}, 5*time.Second, 10*time.Millisecond)
+}